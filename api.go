@@ -17,6 +17,7 @@ type API struct {
 	settingEngine       *SettingEngine
 	mediaEngine         *MediaEngine
 	interceptorRegistry *interceptor.Registry
+	certificateStore    *CertificateStore
 
 	interceptor interceptor.Interceptor // Generated per PeerConnection
 }
@@ -70,3 +71,11 @@ func WithInterceptorRegistry(ir *interceptor.Registry) func(a *API) {
 		}
 	}
 }
+
+// WithCertificateStore allows providing a CertificateStore to the API. PeerConnections
+// and DTLSTransports that aren't given certificates use the one of the store.
+func WithCertificateStore(s *CertificateStore) func(a *API) {
+	return func(a *API) {
+		a.certificateStore = s
+	}
+}
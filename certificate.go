@@ -4,8 +4,11 @@
 package webrtc
 
 import (
+	"bytes"
 	"crypto"
 	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
 	"crypto/rand"
 	"crypto/rsa"
 	"crypto/x509"
@@ -28,6 +31,41 @@ type Certificate struct {
 	statsID    string
 }
 
+// CertificateKeyType is the type of key GenerateCertificateWithOptions generates
+type CertificateKeyType int
+
+const (
+	// CertificateKeyTypeECDSAP256 is an ECDSA key on the P-256 curve
+	CertificateKeyTypeECDSAP256 CertificateKeyType = iota + 1
+
+	// CertificateKeyTypeECDSAP384 is an ECDSA key on the P-384 curve
+	CertificateKeyTypeECDSAP384
+
+	// CertificateKeyTypeEd25519 is an Ed25519 key
+	CertificateKeyTypeEd25519
+
+	// CertificateKeyTypeRSA is a RSA key
+	CertificateKeyTypeRSA
+)
+
+// CertificateOptions controls the certificates generated by GenerateCertificateWithOptions.
+// The zero value generates the same certificates as GenerateCertificate with an ECDSA P-256 key.
+type CertificateOptions struct {
+	// KeyType is the type of the key, ECDSA P-256 if unset
+	KeyType CertificateKeyType
+
+	// RSABits is the size of a RSA key, 2048 if unset
+	RSABits int
+
+	// Validity is how long the certificate is valid for from now, a month if unset.
+	// The certificate is valid from a day ago to allow for clock skew.
+	Validity time.Duration
+
+	// Subject is the subject and the issuer of the self-signed certificate, a common
+	// name of "WebRTC" if unset
+	Subject pkix.Name
+}
+
 // NewCertificate generates a new x509 compliant Certificate to be used
 // by DTLS for encrypting data sent over the wire. This method differs from
 // GenerateCertificate by allowing to specify a template x509.Certificate to
@@ -50,6 +88,13 @@ func NewCertificate(key crypto.PrivateKey, tpl x509.Certificate) (*Certificate,
 		if err != nil {
 			return nil, &rtcerr.UnknownError{Err: err}
 		}
+	case ed25519.PrivateKey:
+		pk := sk.Public()
+		tpl.SignatureAlgorithm = x509.PureEd25519
+		certDER, err = x509.CreateCertificate(rand.Reader, &tpl, &tpl, pk, sk)
+		if err != nil {
+			return nil, &rtcerr.UnknownError{Err: err}
+		}
 	default:
 		return nil, &rtcerr.NotSupportedError{Err: ErrPrivateKeyType}
 	}
@@ -82,6 +127,14 @@ func (c Certificate) Equals(o Certificate) bool {
 			return c.x509Cert.Equal(o.x509Cert)
 		}
 		return false
+	case ed25519.PrivateKey:
+		if oSK, ok := o.privateKey.(ed25519.PrivateKey); ok {
+			if !cSK.Equal(oSK) {
+				return false
+			}
+			return c.x509Cert.Equal(o.x509Cert)
+		}
+		return false
 	default:
 		return false
 	}
@@ -123,6 +176,38 @@ func (c Certificate) GetFingerprints() ([]DTLSFingerprint, error) {
 // GenerateCertificate causes the creation of an X.509 certificate and
 // corresponding private key.
 func GenerateCertificate(secretKey crypto.PrivateKey) (*Certificate, error) {
+	return generateCertificate(secretKey, CertificateOptions{})
+}
+
+// GenerateCertificateWithOptions generates a private key and a self-signed X.509
+// certificate for it, as configured by options.
+func GenerateCertificateWithOptions(options CertificateOptions) (*Certificate, error) {
+	var secretKey crypto.PrivateKey
+	var err error
+	switch options.KeyType {
+	case CertificateKeyType(Unknown), CertificateKeyTypeECDSAP256:
+		secretKey, err = ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	case CertificateKeyTypeECDSAP384:
+		secretKey, err = ecdsa.GenerateKey(elliptic.P384(), rand.Reader)
+	case CertificateKeyTypeEd25519:
+		_, secretKey, err = ed25519.GenerateKey(rand.Reader)
+	case CertificateKeyTypeRSA:
+		bits := options.RSABits
+		if bits == 0 {
+			bits = defaultCertificateRSABits
+		}
+		secretKey, err = rsa.GenerateKey(rand.Reader, bits)
+	default:
+		return nil, &rtcerr.NotSupportedError{Err: ErrPrivateKeyType}
+	}
+	if err != nil {
+		return nil, &rtcerr.UnknownError{Err: err}
+	}
+
+	return generateCertificate(secretKey, options)
+}
+
+func generateCertificate(secretKey crypto.PrivateKey, options CertificateOptions) (*Certificate, error) {
 	// Max random value, a 130-bits integer, i.e 2^130 - 1
 	maxBigInt := new(big.Int)
 	/* #nosec */
@@ -133,13 +218,24 @@ func GenerateCertificate(secretKey crypto.PrivateKey) (*Certificate, error) {
 		return nil, &rtcerr.UnknownError{Err: err}
 	}
 
+	subject := options.Subject
+	if subject.String() == "" {
+		subject = pkix.Name{CommonName: generatedCertificateOrigin}
+	}
+
+	now := time.Now()
+	notAfter := now.AddDate(0, 1, -1)
+	if options.Validity != 0 {
+		notAfter = now.Add(options.Validity)
+	}
+
 	return NewCertificate(secretKey, x509.Certificate{
-		Issuer:       pkix.Name{CommonName: generatedCertificateOrigin},
-		NotBefore:    time.Now().AddDate(0, 0, -1),
-		NotAfter:     time.Now().AddDate(0, 1, -1),
+		Issuer:       subject,
+		NotBefore:    now.AddDate(0, 0, -1),
+		NotAfter:     notAfter,
 		SerialNumber: serialNumber,
 		Version:      2,
-		Subject:      pkix.Name{CommonName: generatedCertificateOrigin},
+		Subject:      subject,
 	})
 }
 
@@ -177,31 +273,58 @@ func (c Certificate) collectStats(report *statsReportCollector) error {
 // CertificateFromPEM creates a fresh certificate based on a string containing
 // pem blocks fort the private key and x509 certificate
 func CertificateFromPEM(pems string) (*Certificate, error) {
+	certificate, _, err := certificateFromPEM([]byte(pems))
+	return certificate, err
+}
+
+// CertificatesFromPEM creates the certificates of a string containing the pem
+// blocks of multiple certificates, as returned by CertificatesPEM
+func CertificatesFromPEM(pems string) ([]Certificate, error) {
+	certificates := []Certificate{}
+	rest := []byte(pems)
+	for len(bytes.TrimSpace(rest)) != 0 {
+		certificate, more, err := certificateFromPEM(rest)
+		if err != nil {
+			return nil, err
+		}
+
+		certificates = append(certificates, *certificate)
+		rest = more
+	}
+
+	if len(certificates) == 0 {
+		return nil, errCertificatePEMFormatError
+	}
+	return certificates, nil
+}
+
+// certificateFromPEM parses the first certificate of pems, and returns what follows it
+func certificateFromPEM(pems []byte) (*Certificate, []byte, error) {
 	// decode & parse the certificate
-	block, more := pem.Decode([]byte(pems))
+	block, more := pem.Decode(pems)
 	if block == nil || block.Type != "CERTIFICATE" {
-		return nil, errCertificatePEMFormatError
+		return nil, nil, errCertificatePEMFormatError
 	}
 	certBytes := make([]byte, base64.StdEncoding.DecodedLen(len(block.Bytes)))
 	n, err := base64.StdEncoding.Decode(certBytes, block.Bytes)
 	if err != nil {
-		return nil, fmt.Errorf("failed to decode ceritifcate: %w", err)
+		return nil, nil, fmt.Errorf("failed to decode ceritifcate: %w", err)
 	}
 	cert, err := x509.ParseCertificate(certBytes[:n])
 	if err != nil {
-		return nil, fmt.Errorf("failed parsing ceritifcate: %w", err)
+		return nil, nil, fmt.Errorf("failed parsing ceritifcate: %w", err)
 	}
 	// decode & parse the private key
-	block, _ = pem.Decode(more)
+	block, more = pem.Decode(more)
 	if block == nil || block.Type != "PRIVATE KEY" {
-		return nil, errCertificatePEMFormatError
+		return nil, nil, errCertificatePEMFormatError
 	}
 	privateKey, err := x509.ParsePKCS8PrivateKey(block.Bytes)
 	if err != nil {
-		return nil, fmt.Errorf("unable to parse private key: %w", err)
+		return nil, nil, fmt.Errorf("unable to parse private key: %w", err)
 	}
 	x := CertificateFromX509(privateKey, cert)
-	return &x, nil
+	return &x, more, nil
 }
 
 // PEM returns the certificate encoded as two pem block: once for the X509
@@ -227,3 +350,17 @@ func (c Certificate) PEM() (string, error) {
 	}
 	return o.String(), nil
 }
+
+// CertificatesPEM returns the certificates encoded as the pem blocks of each
+// certificate one after the other, as read by CertificatesFromPEM
+func CertificatesPEM(certificates []Certificate) (string, error) {
+	var o strings.Builder
+	for _, c := range certificates {
+		pems, err := c.PEM()
+		if err != nil {
+			return "", err
+		}
+		o.WriteString(pems)
+	}
+	return o.String(), nil
+}
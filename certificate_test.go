@@ -10,6 +10,7 @@ import (
 	"crypto/rsa"
 	"crypto/tls"
 	"crypto/x509"
+	"crypto/x509/pkix"
 	"encoding/pem"
 	"testing"
 	"time"
@@ -133,3 +134,88 @@ func TestPEM(t *testing.T) {
 	assert.Nil(t, err)
 	assert.Equal(t, pem, pem2)
 }
+
+func TestGenerateCertificateWithOptions(t *testing.T) {
+	for keyType, algorithm := range map[CertificateKeyType]x509.PublicKeyAlgorithm{
+		CertificateKeyType(Unknown): x509.ECDSA,
+		CertificateKeyTypeECDSAP256: x509.ECDSA,
+		CertificateKeyTypeECDSAP384: x509.ECDSA,
+		CertificateKeyTypeEd25519:   x509.Ed25519,
+		CertificateKeyTypeRSA:       x509.RSA,
+	} {
+		cert, err := GenerateCertificateWithOptions(CertificateOptions{KeyType: keyType, RSABits: 1024})
+		assert.NoError(t, err)
+		assert.Equal(t, algorithm, cert.x509Cert.PublicKeyAlgorithm)
+		assert.Equal(t, generatedCertificateOrigin, cert.x509Cert.Subject.CommonName)
+		assert.True(t, cert.Equals(*cert))
+
+		_, err = cert.GetFingerprints()
+		assert.NoError(t, err)
+	}
+
+	cert, err := GenerateCertificateWithOptions(CertificateOptions{
+		Validity: time.Hour * 24 * 365,
+		Subject:  pkix.Name{CommonName: "pion", Organization: []string{"Pion"}},
+	})
+	assert.NoError(t, err)
+	assert.WithinDuration(t, time.Now().Add(time.Hour*24*365), cert.Expires(), time.Minute)
+	assert.Equal(t, "pion", cert.x509Cert.Subject.CommonName)
+	assert.Equal(t, "pion", cert.x509Cert.Issuer.CommonName)
+
+	_, err = GenerateCertificateWithOptions(CertificateOptions{KeyType: CertificateKeyTypeRSA + 1})
+	assert.Error(t, err)
+}
+
+func TestCertificatesPEM(t *testing.T) {
+	certs := []Certificate{}
+	for _, keyType := range []CertificateKeyType{CertificateKeyTypeECDSAP256, CertificateKeyTypeEd25519, CertificateKeyTypeRSA} {
+		cert, err := GenerateCertificateWithOptions(CertificateOptions{KeyType: keyType})
+		assert.NoError(t, err)
+		certs = append(certs, *cert)
+	}
+
+	pems, err := CertificatesPEM(certs)
+	assert.NoError(t, err)
+
+	decoded, err := CertificatesFromPEM(pems)
+	assert.NoError(t, err)
+	assert.Equal(t, len(certs), len(decoded))
+	for i := range certs {
+		assert.True(t, certs[i].Equals(decoded[i]))
+	}
+
+	// The first certificate is read by CertificateFromPEM
+	first, err := CertificateFromPEM(pems)
+	assert.NoError(t, err)
+	assert.True(t, certs[0].Equals(*first))
+
+	_, err = CertificatesFromPEM("")
+	assert.Error(t, err)
+}
+
+func TestCertificateStore(t *testing.T) {
+	store := NewCertificateStore(CertificateOptions{Validity: time.Hour}, time.Minute*30)
+
+	cert, err := store.Certificate()
+	assert.NoError(t, err)
+
+	reused, err := store.Certificate()
+	assert.NoError(t, err)
+	assert.True(t, cert.Equals(reused))
+
+	// A certificate that expires within the renewal period is replaced
+	expiring, err := GenerateCertificateWithOptions(CertificateOptions{Validity: time.Minute * 10})
+	assert.NoError(t, err)
+	store.SetCertificate(*expiring)
+
+	renewed, err := store.Certificate()
+	assert.NoError(t, err)
+	assert.False(t, expiring.Equals(renewed))
+	assert.True(t, time.Now().Add(time.Minute*30).Before(renewed.Expires()))
+
+	// PeerConnections without certificates use the one of the store
+	pc, err := NewAPI(WithCertificateStore(store)).NewPeerConnection(Configuration{})
+	assert.NoError(t, err)
+	assert.True(t, renewed.Equals(pc.configuration.Certificates[0]))
+	assert.NoError(t, pc.Close())
+}
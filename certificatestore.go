@@ -0,0 +1,60 @@
+//go:build !js
+// +build !js
+
+package webrtc
+
+import (
+	"sync"
+	"time"
+)
+
+// CertificateStore provides the certificate of the PeerConnections and DTLSTransports
+// of an API that aren't configured with one. The same certificate is reused until it
+// is about to expire, then a new one is generated.
+type CertificateStore struct {
+	mu          sync.Mutex
+	options     CertificateOptions
+	renewBefore time.Duration
+	certificate *Certificate
+}
+
+// NewCertificateStore returns a CertificateStore that generates certificates as
+// configured by options, and renews them renewBefore they expire. renewBefore
+// must be shorter than the validity of the generated certificates.
+func NewCertificateStore(options CertificateOptions, renewBefore time.Duration) *CertificateStore {
+	return &CertificateStore{
+		options:     options,
+		renewBefore: renewBefore,
+	}
+}
+
+// SetCertificate sets the certificate provided by the store, e.g. one restored with
+// CertificateFromPEM. It is used until it has to be renewed.
+func (s *CertificateStore) SetCertificate(certificate Certificate) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.certificate = &certificate
+}
+
+// Certificate returns the certificate of the store. A new one is generated if there
+// is none yet or if the current one expires within the renewal period.
+func (s *CertificateStore) Certificate() (Certificate, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.certificate == nil || s.needsRenewal(*s.certificate) {
+		certificate, err := GenerateCertificateWithOptions(s.options)
+		if err != nil {
+			return Certificate{}, err
+		}
+		s.certificate = certificate
+	}
+
+	return *s.certificate, nil
+}
+
+func (s *CertificateStore) needsRenewal(certificate Certificate) bool {
+	expires := certificate.Expires()
+	return !expires.IsZero() && !time.Now().Add(s.renewBefore).Before(expires)
+}
@@ -24,6 +24,11 @@ const (
 
 	sdpAttributeRid = "rid"
 
+	sdpAttributeBundleOnly = "bundle-only"
+
+	// sdpSemanticTokenFECFramework groups a media SSRC with the SSRC of its FlexFEC protection (RFC 5956)
+	sdpSemanticTokenFECFramework = "FEC-FR"
+
 	rtpOutboundMTU = 1200
 
 	rtpPayloadTypeBitmask = 0x7F
@@ -32,6 +37,8 @@ const (
 
 	generatedCertificateOrigin = "WebRTC"
 
+	defaultCertificateRSABits = 2048
+
 	sdesRepairRTPStreamIDURI = "urn:ietf:params:rtp-hdrext:sdes:repaired-rtp-stream-id"
 )
 
@@ -4,6 +4,7 @@
 package webrtc
 
 import (
+	"context"
 	"errors"
 	"fmt"
 	"io"
@@ -14,10 +15,17 @@ import (
 
 	"github.com/pion/datachannel"
 	"github.com/pion/logging"
+	"github.com/pion/sctp"
 	"github.com/pion/webrtc/v3/pkg/rtcerr"
 )
 
 const dataChannelBufferSize = math.MaxUint16 // message size limit for Chromium
+
+// dataChannelReadMessagesSize is the amount of messages kept for ReadMessage. Before
+// the first call of ReadMessage further messages are dropped, after it the DataChannel
+// stops reading from SCTP until they are read
+const dataChannelReadMessagesSize = 64
+
 var errSCTPNotEstablished = errors.New("SCTP not established")
 
 // DataChannel represents a WebRTC DataChannel
@@ -33,6 +41,7 @@ type DataChannel struct {
 	maxRetransmits             *uint16
 	protocol                   string
 	negotiated                 bool
+	priority                   PriorityType
 	id                         *uint16
 	readyState                 atomic.Value // DataChannelState
 	bufferedAmountLowThreshold uint64
@@ -46,16 +55,37 @@ type DataChannel struct {
 	// "blob". This attribute controls how binary data is exposed to scripts.
 	// binaryType                 string
 
-	onMessageHandler    func(DataChannelMessage)
-	openHandlerOnce     sync.Once
-	onOpenHandler       func()
-	onCloseHandler      func()
-	onBufferedAmountLow func()
-	onErrorHandler      func(error)
+	onMessageHandler       func(DataChannelMessage)
+	onMessageReaderHandler func(*DataChannelMessageReader)
+	openHandlerOnce        sync.Once
+	onOpenHandler          func()
+	onCloseHandler         func()
+	onBufferedAmountLow    func()
+	onErrorHandler         func(error)
 
 	sctpTransport *SCTPTransport
 	dataChannel   *datachannel.DataChannel
 
+	// Streamed messages, see OpenMessageWriter and OnMessageReader
+	messageWriterMu sync.Mutex
+	messageReader   *io.PipeWriter
+
+	// Messages passed to ReadMessage, readStop is closed once the DataChannel is closing.
+	// deliverMu passes the kept messages to OnMessage before the ones that arrive later
+	readMessages        chan DataChannelMessage
+	readMessagesStarted bool
+	readStop            chan struct{}
+	readStopOnce        sync.Once
+	deliverMu           sync.Mutex
+
+	// Closed when the BufferedAmount falls to the BufferedAmountLowThreshold, see SendContext.
+	// The association and the scheduler both report it, bufferedAmountLowRunning and
+	// bufferedAmountLowPending pass their reports to the handler one at a time
+	bufferedAmountLowMu      sync.Mutex
+	bufferedAmountLowNotify  chan struct{}
+	bufferedAmountLowRunning bool
+	bufferedAmountLowPending bool
+
 	// A reference to the associated api object used by this datachannel
 	api *API
 	log logging.LeveledLogger
@@ -95,10 +125,17 @@ func (api *API) newDataChannel(params *DataChannelParameters, log logging.Levele
 		ordered:           params.Ordered,
 		maxPacketLifeTime: params.MaxPacketLifeTime,
 		maxRetransmits:    params.MaxRetransmits,
+		priority:          params.Priority,
+		readMessages:      make(chan DataChannelMessage, dataChannelReadMessagesSize),
+		readStop:          make(chan struct{}),
 		api:               api,
 		log:               log,
 	}
 
+	if d.priority == PriorityType(Unknown) {
+		d.priority = PriorityTypeLow
+	}
+
 	d.setReadyState(DataChannelStateConnecting)
 	return d, nil
 }
@@ -145,7 +182,7 @@ func (d *DataChannel) open(sctpTransport *SCTPTransport) error {
 
 	cfg := &datachannel.Config{
 		ChannelType:          channelType,
-		Priority:             datachannel.ChannelPriorityNormal,
+		Priority:             d.priority.dataChannelPriority(),
 		ReliabilityParameter: reliabilityParameter,
 		Label:                d.label,
 		Protocol:             d.protocol,
@@ -170,9 +207,8 @@ func (d *DataChannel) open(sctpTransport *SCTPTransport) error {
 		return err
 	}
 
-	// bufferedAmountLowThreshold and onBufferedAmountLow might be set earlier
+	// bufferedAmountLowThreshold might be set earlier
 	dc.SetBufferedAmountLowThreshold(d.bufferedAmountLowThreshold)
-	dc.OnBufferedAmountLow(d.onBufferedAmountLow)
 	d.mu.Unlock()
 
 	d.handleOpen(dc, false, d.negotiated)
@@ -252,27 +288,78 @@ func (d *DataChannel) onClose() {
 // in size. Check out the detach API if you want to use larger
 // message sizes. Note that browser support for larger messages
 // is also limited.
+// The messages kept for ReadMessage are passed to f first.
 func (d *DataChannel) OnMessage(f func(msg DataChannelMessage)) {
 	d.mu.Lock()
-	defer d.mu.Unlock()
 	d.onMessageHandler = f
+	d.mu.Unlock()
+
+	if f == nil || len(d.readMessages) == 0 {
+		return
+	}
+
+	go func() {
+		d.deliverMu.Lock()
+		defer d.deliverMu.Unlock()
+
+		d.mu.RLock()
+		handler := d.onMessageHandler
+		d.mu.RUnlock()
+
+		if handler != nil {
+			d.drainReadMessages(handler)
+		}
+	}()
+}
+
+// drainReadMessages passes the messages kept for ReadMessage to handler, deliverMu must be held
+func (d *DataChannel) drainReadMessages(handler func(DataChannelMessage)) {
+	for {
+		select {
+		case msg := <-d.readMessages:
+			handler(msg)
+		default:
+			return
+		}
+	}
 }
 
 func (d *DataChannel) onMessage(msg DataChannelMessage) {
 	d.mu.RLock()
 	handler := d.onMessageHandler
+	readerHandler := d.onMessageReaderHandler
+	readMessagesStarted := d.readMessagesStarted
 	d.mu.RUnlock()
 
-	if handler == nil {
-		return
+	switch {
+	case readerHandler != nil:
+		d.onMessageChunk(msg, readerHandler)
+	case handler != nil:
+		d.deliverMu.Lock()
+		d.drainReadMessages(handler)
+		handler(msg)
+		d.deliverMu.Unlock()
+	case readMessagesStarted:
+		// Wait for room for ReadMessage, the message is dropped if the DataChannel is closed first
+		select {
+		case d.readMessages <- msg:
+		case <-d.readStop:
+		}
+	default:
+		// Nothing reads the messages yet, don't stop reading from SCTP for them
+		select {
+		case d.readMessages <- msg:
+		default:
+			d.log.Warnf("Dropped a message of DataChannel %s, it has no OnMessage handler and ReadMessage isn't called", d.label)
+		}
 	}
-	handler(msg)
 }
 
 func (d *DataChannel) handleOpen(dc *datachannel.DataChannel, isRemote, isAlreadyNegotiated bool) {
 	d.mu.Lock()
 	d.dataChannel = dc
 	d.mu.Unlock()
+	dc.OnBufferedAmountLow(d.handleBufferedAmountLow)
 	d.setReadyState(DataChannelStateOpen)
 
 	// Fire the OnOpen handler immediately not using pion/datachannel
@@ -325,6 +412,7 @@ func (d *DataChannel) readLoop() {
 		n, isString, err := d.dataChannel.ReadDataChannel(buffer)
 		if err != nil {
 			rlBufPool.Put(buffer) // nolint:staticcheck
+			d.closeMessageReader(io.ErrUnexpectedEOF)
 			d.setReadyState(DataChannelStateClosed)
 			if !errors.Is(err, io.EOF) {
 				d.onError(err)
@@ -343,26 +431,175 @@ func (d *DataChannel) readLoop() {
 	}
 }
 
-// Send sends the binary message to the DataChannel peer
+// Send sends the binary message to the DataChannel peer. While the SCTPTransport is
+// busy the message is queued, and sent in turn with the messages of the other
+// DataChannels according to their Priority.
+// A queued message that fails to be sent is reported to OnError, the messages
+// queued after it are dropped.
 func (d *DataChannel) Send(data []byte) error {
+	return d.write(data, false)
+}
+
+// SendText sends the text message to the DataChannel peer. While the SCTPTransport is
+// busy the message is queued, and sent in turn with the messages of the other
+// DataChannels according to their Priority.
+// A queued message that fails to be sent is reported to OnError, the messages
+// queued after it are dropped.
+func (d *DataChannel) SendText(s string) error {
+	return d.write([]byte(s), true)
+}
+
+func (d *DataChannel) write(data []byte, isString bool) error {
 	err := d.ensureOpen()
 	if err != nil {
 		return err
 	}
 
-	_, err = d.dataChannel.WriteDataChannel(data, false)
-	return err
+	d.mu.RLock()
+	dataChannel := d.dataChannel
+	sctpTransport := d.sctpTransport
+	d.mu.RUnlock()
+
+	if sctpTransport == nil {
+		_, err = dataChannel.WriteDataChannel(data, isString)
+		return err
+	}
+
+	// Messages the scheduler queues are written later, reject the ones the association can't send now
+	if association := sctpTransport.association(); association != nil && len(data) > int(association.MaxMessageSize()) {
+		return fmt.Errorf("%w: %d", sctp.ErrOutboundPacketTooLarge, association.MaxMessageSize())
+	}
+	return sctpTransport.scheduler.send(d, dataChannel, data, isString)
 }
 
-// SendText sends the text message to the DataChannel peer
-func (d *DataChannel) SendText(s string) error {
-	err := d.ensureOpen()
-	if err != nil {
+// SendContext sends the binary message to the DataChannel peer once the BufferedAmount
+// has fallen to the BufferedAmountLowThreshold. It returns the error of ctx if ctx is
+// done before.
+func (d *DataChannel) SendContext(ctx context.Context, data []byte) error {
+	if err := d.waitBufferedAmountLow(ctx); err != nil {
 		return err
 	}
+	return d.write(data, false)
+}
+
+// SendTextContext sends the text message to the DataChannel peer once the BufferedAmount
+// has fallen to the BufferedAmountLowThreshold. It returns the error of ctx if ctx is
+// done before.
+func (d *DataChannel) SendTextContext(ctx context.Context, s string) error {
+	if err := d.waitBufferedAmountLow(ctx); err != nil {
+		return err
+	}
+	return d.write([]byte(s), true)
+}
+
+func (d *DataChannel) waitBufferedAmountLow(ctx context.Context) error {
+	for {
+		if err := d.ensureOpen(); err != nil {
+			return err
+		}
+
+		// Wait for the notification that is sent after the check
+		d.bufferedAmountLowMu.Lock()
+		if d.bufferedAmountLowNotify == nil {
+			d.bufferedAmountLowNotify = make(chan struct{})
+		}
+		notify := d.bufferedAmountLowNotify
+		d.bufferedAmountLowMu.Unlock()
+
+		if d.BufferedAmount() <= d.BufferedAmountLowThreshold() {
+			return nil
+		}
+
+		select {
+		case <-notify:
+		case <-d.readStop:
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+}
+
+// handleBufferedAmountLow is called by the association and by the scheduler of the
+// SCTPTransport. A call while another one is running doesn't wait, the running one
+// checks the BufferedAmount again once it is done.
+func (d *DataChannel) handleBufferedAmountLow() {
+	d.bufferedAmountLowMu.Lock()
+	if d.bufferedAmountLowRunning {
+		d.bufferedAmountLowPending = true
+		d.bufferedAmountLowMu.Unlock()
+		return
+	}
+	d.bufferedAmountLowRunning = true
+	d.bufferedAmountLowMu.Unlock()
+
+	for {
+		d.checkBufferedAmountLow()
+
+		d.bufferedAmountLowMu.Lock()
+		if !d.bufferedAmountLowPending {
+			d.bufferedAmountLowRunning = false
+			d.bufferedAmountLowMu.Unlock()
+			return
+		}
+		d.bufferedAmountLowPending = false
+		d.bufferedAmountLowMu.Unlock()
+	}
+}
+
+func (d *DataChannel) checkBufferedAmountLow() {
+	// The association only knows about the data it buffers, not the messages queued
+	// by the scheduler of the SCTPTransport
+	if d.BufferedAmount() > d.BufferedAmountLowThreshold() {
+		return
+	}
+
+	d.bufferedAmountLowMu.Lock()
+	if d.bufferedAmountLowNotify != nil {
+		close(d.bufferedAmountLowNotify)
+		d.bufferedAmountLowNotify = nil
+	}
+	d.bufferedAmountLowMu.Unlock()
+
+	d.mu.RLock()
+	handler := d.onBufferedAmountLow
+	d.mu.RUnlock()
+
+	if handler != nil {
+		handler()
+	}
+}
 
-	_, err = d.dataChannel.WriteDataChannel([]byte(s), true)
-	return err
+// ReadMessage blocks until the next message arrives from the DataChannel peer. It returns
+// io.EOF once the DataChannel is closed and the kept messages are read, and the error of
+// ctx if ctx is done before.
+// Messages are only passed to ReadMessage while neither OnMessage nor OnMessageReader is
+// set. Up to 64 messages that arrive before the first call of ReadMessage are kept until
+// they are read, later ones are dropped.
+func (d *DataChannel) ReadMessage(ctx context.Context) (DataChannelMessage, error) {
+	d.mu.Lock()
+	if d.api.settingEngine.detach.DataChannels {
+		d.mu.Unlock()
+		return DataChannelMessage{}, errReadMessageDetached
+	}
+	d.readMessagesStarted = true
+	readMessages := d.readMessages
+	d.mu.Unlock()
+
+	// Messages that arrived before the DataChannel closed are still returned
+	select {
+	case msg := <-readMessages:
+		return msg, nil
+	default:
+	}
+
+	select {
+	case msg := <-readMessages:
+		return msg, nil
+	case <-d.readStop:
+		return DataChannelMessage{}, io.EOF
+	case <-ctx.Done():
+		return DataChannelMessage{}, ctx.Err()
+	}
 }
 
 func (d *DataChannel) ensureOpen() error {
@@ -415,6 +652,9 @@ func (d *DataChannel) Close() error {
 		return nil
 	}
 
+	if sctpTransport := d.Transport(); sctpTransport != nil {
+		sctpTransport.scheduler.closeDataChannel(d)
+	}
 	return d.dataChannel.Close()
 }
 
@@ -464,6 +704,16 @@ func (d *DataChannel) Protocol() string {
 	return d.protocol
 }
 
+// Priority represents the priority of this DataChannel relative to the other
+// DataChannels of the SCTPTransport. It is announced to the remote peer and
+// decides how the SCTPTransport shares the association between them when it is busy.
+func (d *DataChannel) Priority() PriorityType {
+	d.mu.RLock()
+	defer d.mu.RUnlock()
+
+	return d.priority
+}
+
 // Negotiated represents whether this DataChannel was negotiated by the
 // application (true), or not (false).
 func (d *DataChannel) Negotiated() bool {
@@ -505,6 +755,17 @@ func (d *DataChannel) ReadyState() DataChannelState {
 // open; however, BufferedAmount does not reset to zero once the channel
 // closes.
 func (d *DataChannel) BufferedAmount() uint64 {
+	bufferedAmount := d.sctpBufferedAmount()
+
+	if sctpTransport := d.Transport(); sctpTransport != nil {
+		bufferedAmount += sctpTransport.scheduler.queuedAmount(d)
+	}
+	return bufferedAmount
+}
+
+// sctpBufferedAmount returns the amount of data buffered in the SCTP association,
+// without the messages queued by the scheduler of the SCTPTransport
+func (d *DataChannel) sctpBufferedAmount() uint64 {
 	d.mu.RLock()
 	defer d.mu.RUnlock()
 
@@ -551,9 +812,6 @@ func (d *DataChannel) OnBufferedAmountLow(f func()) {
 	defer d.mu.Unlock()
 
 	d.onBufferedAmountLow = f
-	if d.dataChannel != nil {
-		d.dataChannel.OnBufferedAmountLow(f)
-	}
 }
 
 func (d *DataChannel) getStatsID() string {
@@ -565,6 +823,11 @@ func (d *DataChannel) getStatsID() string {
 func (d *DataChannel) collectStats(collector *statsReportCollector) {
 	collector.Collecting()
 
+	var queuedAmount uint64
+	if sctpTransport := d.Transport(); sctpTransport != nil {
+		queuedAmount = sctpTransport.scheduler.queuedAmount(d)
+	}
+
 	d.mu.Lock()
 	defer d.mu.Unlock()
 
@@ -587,11 +850,63 @@ func (d *DataChannel) collectStats(collector *statsReportCollector) {
 		stats.BytesSent = d.dataChannel.BytesSent()
 		stats.MessagesReceived = d.dataChannel.MessagesReceived()
 		stats.BytesReceived = d.dataChannel.BytesReceived()
+		stats.BufferedAmount = d.dataChannel.BufferedAmount() + queuedAmount
 	}
 
 	collector.Collect(stats.ID, stats)
 }
 
+// dataChannelPriority returns the priority announced in the DATA_CHANNEL_OPEN
+// message, see RFC 8831 Section 6.4
+func (p PriorityType) dataChannelPriority() uint16 {
+	switch p {
+	case PriorityTypeVeryLow:
+		return datachannel.ChannelPriorityBelowNormal
+	case PriorityTypeMedium:
+		return datachannel.ChannelPriorityHigh
+	case PriorityTypeHigh:
+		return datachannel.ChannelPriorityExtraHigh
+	default:
+		return datachannel.ChannelPriorityNormal
+	}
+}
+
+// newPriorityTypeFromDataChannel returns the PriorityType of a priority announced
+// in the DATA_CHANNEL_OPEN message, a priority in between is rounded up
+func newPriorityTypeFromDataChannel(priority uint16) PriorityType {
+	switch {
+	case priority <= datachannel.ChannelPriorityBelowNormal:
+		return PriorityTypeVeryLow
+	case priority <= datachannel.ChannelPriorityNormal:
+		return PriorityTypeLow
+	case priority <= datachannel.ChannelPriorityHigh:
+		return PriorityTypeMedium
+	default:
+		return PriorityTypeHigh
+	}
+}
+
+// schedulerWeight returns the share of the SCTPTransport a DataChannel gets
+// relative to a DataChannel of PriorityTypeVeryLow
+func (p PriorityType) schedulerWeight() int {
+	switch p {
+	case PriorityTypeVeryLow:
+		return 1
+	case PriorityTypeMedium:
+		return 4
+	case PriorityTypeHigh:
+		return 8
+	default:
+		return 2
+	}
+}
+
 func (d *DataChannel) setReadyState(r DataChannelState) {
 	d.readyState.Store(r)
+
+	if (r == DataChannelStateClosing || r == DataChannelStateClosed) && d.readStop != nil {
+		d.readStopOnce.Do(func() {
+			close(d.readStop)
+		})
+	}
 }
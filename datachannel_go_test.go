@@ -5,6 +5,7 @@ package webrtc
 
 import (
 	"bytes"
+	"context"
 	"crypto/rand"
 	"encoding/binary"
 	"io"
@@ -14,11 +15,13 @@ import (
 	"regexp"
 	"strings"
 	"sync"
+	"sync/atomic"
 	"testing"
 	"time"
 
 	"github.com/pion/datachannel"
 	"github.com/pion/logging"
+	"github.com/pion/sctp"
 	"github.com/pion/transport/test"
 	"github.com/stretchr/testify/assert"
 )
@@ -318,6 +321,340 @@ func TestDataChannelBufferedAmount(t *testing.T) {
 	})
 }
 
+func TestDataChannel_Priority(t *testing.T) {
+	lim := test.TimeOut(time.Second * 30)
+	defer lim.Stop()
+
+	report := test.CheckRoutines(t)
+	defer report()
+
+	offerPC, answerPC, err := newPair()
+	assert.NoError(t, err)
+
+	bulkPriority, mediumPriority, controlPriority := PriorityTypeVeryLow, PriorityTypeMedium, PriorityTypeHigh
+	bulk, err := offerPC.CreateDataChannel("bulk", &DataChannelInit{Priority: &bulkPriority})
+	assert.NoError(t, err)
+	medium, err := offerPC.CreateDataChannel("medium", &DataChannelInit{Priority: &mediumPriority})
+	assert.NoError(t, err)
+	control, err := offerPC.CreateDataChannel("control", &DataChannelInit{Priority: &controlPriority})
+	assert.NoError(t, err)
+
+	var bulkReceived, mediumReceived uint64
+	controlReceived := make(chan struct{})
+	answerPC.OnDataChannel(func(d *DataChannel) {
+		switch d.Label() {
+		case "bulk":
+			assert.Equal(t, PriorityTypeVeryLow, d.Priority())
+			d.OnMessage(func(msg DataChannelMessage) {
+				atomic.AddUint64(&bulkReceived, uint64(len(msg.Data)))
+			})
+		case "medium":
+			assert.Equal(t, PriorityTypeMedium, d.Priority())
+			d.OnMessage(func(msg DataChannelMessage) {
+				atomic.AddUint64(&mediumReceived, uint64(len(msg.Data)))
+			})
+		case "control":
+			assert.Equal(t, PriorityTypeHigh, d.Priority())
+			d.OnMessage(func(DataChannelMessage) {
+				controlReceived <- struct{}{}
+			})
+		}
+	})
+
+	var opened sync.WaitGroup
+	opened.Add(3)
+	bulk.OnOpen(opened.Done)
+	medium.OnOpen(opened.Done)
+	control.OnOpen(opened.Done)
+
+	assert.NoError(t, signalPair(offerPC, answerPC))
+	opened.Wait()
+
+	// Keep the bulk and medium channels busy from OnBufferedAmountLow with more data than
+	// the association holds. Without the scheduler their data would pile up in front of
+	// the messages of the control channel.
+	const busyBufferedAmount = 4 * dataChannelSchedulerBufferedAmount
+	buf := make([]byte, 16384)
+	for _, d := range []*DataChannel{bulk, medium} {
+		d := d
+		fill := func() {
+			for d.BufferedAmount() < busyBufferedAmount {
+				if d.Send(buf) != nil {
+					return
+				}
+			}
+		}
+		d.SetBufferedAmountLowThreshold(busyBufferedAmount / 2)
+		d.OnBufferedAmountLow(fill)
+		fill()
+	}
+
+	// The data held back by the scheduler is part of the BufferedAmount
+	assert.Greater(t, bulk.BufferedAmount()+medium.BufferedAmount(), offerPC.SCTP().bufferedAmount())
+
+	// Messages that can't be sent fail right away, not once the scheduler writes them
+	assert.ErrorIs(t, bulk.Send(make([]byte, offerPC.SCTP().association().MaxMessageSize()+1)), sctp.ErrOutboundPacketTooLarge)
+
+	for i := 0; i < 5; i++ {
+		start := time.Now()
+		assert.NoError(t, control.SendText("ping"))
+		<-controlReceived
+		assert.Less(t, time.Since(start), time.Second)
+	}
+
+	// The medium channel gets 4 times the share of the bulk channel
+	received := func() (uint64, uint64) {
+		return atomic.LoadUint64(&bulkReceived), atomic.LoadUint64(&mediumReceived)
+	}
+	bulkStart, mediumStart := received()
+	for {
+		bulkEnd, mediumEnd := received()
+		if bulkEnd-bulkStart+mediumEnd-mediumStart >= 8*busyBufferedAmount {
+			assert.InDelta(t, 4, float64(mediumEnd-mediumStart)/float64(bulkEnd-bulkStart), 1)
+			break
+		}
+		time.Sleep(time.Millisecond * 10)
+	}
+
+	closePairNow(t, offerPC, answerPC)
+}
+
+func TestDataChannel_MessageStream(t *testing.T) {
+	lim := test.TimeOut(time.Second * 30)
+	defer lim.Stop()
+
+	report := test.CheckRoutines(t)
+	defer report()
+
+	offerPC, answerPC, err := newPair()
+	assert.NoError(t, err)
+
+	// Larger than the max-message-size, and not a multiple of the chunk size
+	expected := make([][]byte, 2)
+	for i := range expected {
+		expected[i] = make([]byte, 1<<20*(i+1)+i)
+		_, err = rand.Read(expected[i])
+		assert.NoError(t, err)
+	}
+
+	received := make(chan []byte)
+	answerPC.OnDataChannel(func(d *DataChannel) {
+		if d.Label() != expectedLabel {
+			return
+		}
+		d.OnMessageReader(func(r *DataChannelMessageReader) {
+			assert.False(t, r.IsString)
+			data, readErr := ioutil.ReadAll(r)
+			assert.NoError(t, readErr)
+			received <- data
+		})
+	})
+
+	dc, err := offerPC.CreateDataChannel(expectedLabel, nil)
+	assert.NoError(t, err)
+
+	// At most 256 KiB of a message are in flight
+	dc.SetBufferedAmountLowThreshold(16 * dataChannelMessageChunkSize)
+
+	dc.OnOpen(func() {
+		for _, data := range expected {
+			w, openErr := dc.OpenMessageWriter(false)
+			assert.NoError(t, openErr)
+
+			// Write in parts that don't line up with the chunks
+			for len(data) != 0 {
+				n := 5000
+				if n > len(data) {
+					n = len(data)
+				}
+				_, writeErr := w.Write(data[:n])
+				assert.NoError(t, writeErr)
+				assert.LessOrEqual(t, dc.BufferedAmount(), uint64(17*dataChannelMessageChunkSize))
+				data = data[n:]
+			}
+			assert.NoError(t, w.Close())
+
+			_, writeErr := w.Write([]byte{0})
+			assert.ErrorIs(t, writeErr, io.ErrClosedPipe)
+		}
+	})
+
+	assert.NoError(t, signalPair(offerPC, answerPC))
+
+	for _, data := range expected {
+		assert.Equal(t, data, <-received)
+	}
+
+	closePairNow(t, offerPC, answerPC)
+}
+
+func TestDataChannel_ReadMessage(t *testing.T) {
+	lim := test.TimeOut(time.Second * 30)
+	defer lim.Stop()
+
+	report := test.CheckRoutines(t)
+	defer report()
+
+	offerPC, answerPC, err := newPair()
+	assert.NoError(t, err)
+
+	answerDCs := make(chan *DataChannel, 1)
+	answerPC.OnDataChannel(func(d *DataChannel) {
+		if d.Label() == expectedLabel {
+			answerDCs <- d
+		}
+	})
+
+	offerDC, err := offerPC.CreateDataChannel(expectedLabel, nil)
+	assert.NoError(t, err)
+
+	assert.NoError(t, signalPair(offerPC, answerPC))
+	answerDC := <-answerDCs
+
+	// Nothing has been sent yet
+	ctx, cancel := context.WithTimeout(context.Background(), time.Millisecond*50)
+	_, err = answerDC.ReadMessage(ctx)
+	assert.ErrorIs(t, err, context.DeadlineExceeded)
+	cancel()
+
+	assert.NoError(t, offerDC.SendTextContext(context.Background(), "text"))
+	assert.NoError(t, offerDC.SendContext(context.Background(), []byte{1, 2, 3}))
+
+	msg, err := answerDC.ReadMessage(context.Background())
+	assert.NoError(t, err)
+	assert.Equal(t, DataChannelMessage{IsString: true, Data: []byte("text")}, msg)
+
+	msg, err = answerDC.ReadMessage(context.Background())
+	assert.NoError(t, err)
+	assert.Equal(t, DataChannelMessage{IsString: false, Data: []byte{1, 2, 3}}, msg)
+
+	// SendContext waits for the BufferedAmount to fall to the threshold, which doesn't
+	// happen before ctx is done if nothing is acknowledged
+	canceled, cancel := context.WithCancel(context.Background())
+	cancel()
+	offerDC.SetBufferedAmountLowThreshold(0)
+	assert.NoError(t, offerDC.Send(make([]byte, 1000)))
+	if offerDC.BufferedAmount() != 0 {
+		assert.ErrorIs(t, offerDC.SendContext(canceled, []byte{1}), context.Canceled)
+	}
+
+	// The messages received before Close are still returned
+	assert.NoError(t, answerDC.Close())
+	for err == nil {
+		_, err = answerDC.ReadMessage(context.Background())
+	}
+	assert.ErrorIs(t, err, io.EOF)
+
+	closePairNow(t, offerPC, answerPC)
+}
+
+func TestDataChannel_ReadMessageBeforeFirstCall(t *testing.T) {
+	lim := test.TimeOut(time.Second * 30)
+	defer lim.Stop()
+
+	report := test.CheckRoutines(t)
+	defer report()
+
+	offerPC, answerPC, err := newPair()
+	assert.NoError(t, err)
+
+	answerDCs := make(chan *DataChannel, 1)
+	answerPC.OnDataChannel(func(d *DataChannel) {
+		if d.Label() == expectedLabel {
+			answerDCs <- d
+		}
+	})
+
+	offerDC, err := offerPC.CreateDataChannel(expectedLabel, nil)
+	assert.NoError(t, err)
+
+	sent := make(chan struct{})
+	offerDC.OnOpen(func() {
+		for i := 0; i < 3; i++ {
+			assert.NoError(t, offerDC.Send([]byte{byte(i)}))
+		}
+		close(sent)
+	})
+
+	assert.NoError(t, signalPair(offerPC, answerPC))
+	answerDC := <-answerDCs
+	<-sent
+
+	// Let the messages arrive before ReadMessage is called
+	time.Sleep(time.Millisecond * 200)
+
+	for i := 0; i < 3; i++ {
+		msg, readErr := answerDC.ReadMessage(context.Background())
+		assert.NoError(t, readErr)
+		assert.Equal(t, DataChannelMessage{Data: []byte{byte(i)}}, msg)
+	}
+
+	closePairNow(t, offerPC, answerPC)
+}
+
+func TestDataChannel_MessagesWithoutReader(t *testing.T) {
+	lim := test.TimeOut(time.Second * 30)
+	defer lim.Stop()
+
+	report := test.CheckRoutines(t)
+	defer report()
+
+	offerPC, answerPC, err := newPair()
+	assert.NoError(t, err)
+
+	answerDCs := make(chan *DataChannel, 1)
+	answerPC.OnDataChannel(func(d *DataChannel) {
+		if d.Label() == expectedLabel {
+			answerDCs <- d
+		}
+	})
+
+	offerDC, err := offerPC.CreateDataChannel(expectedLabel, nil)
+	assert.NoError(t, err)
+
+	const messageCount = 100
+	message := func(i int) []byte {
+		return append([]byte{byte(i)}, make([]byte, 99)...)
+	}
+
+	sent := make(chan struct{})
+	offerDC.OnOpen(func() {
+		for i := 0; i < messageCount; i++ {
+			assert.NoError(t, offerDC.Send(message(i)))
+		}
+		close(sent)
+	})
+
+	assert.NoError(t, signalPair(offerPC, answerPC))
+	answerDC := <-answerDCs
+	<-sent
+
+	// Nothing reads the messages, the ones that don't fit are dropped
+	for len(answerDC.readMessages) != dataChannelReadMessagesSize {
+		time.Sleep(time.Millisecond * 10)
+	}
+
+	received := make(chan DataChannelMessage, messageCount+1)
+	answerDC.OnMessage(func(msg DataChannelMessage) {
+		received <- msg
+	})
+
+	// The kept messages are passed on first, the readLoop isn't blocked by the dropped ones.
+	// The messages that were still read when OnMessage was set are passed on as well
+	assert.NoError(t, offerDC.Send(message(messageCount)))
+	for i := 0; i < dataChannelReadMessagesSize; i++ {
+		assert.Equal(t, DataChannelMessage{Data: message(i)}, <-received)
+	}
+	for msg := range received {
+		if msg.Data[0] == messageCount {
+			break
+		}
+		assert.Greater(t, int(msg.Data[0]), dataChannelReadMessagesSize-1)
+	}
+
+	closePairNow(t, offerPC, answerPC)
+}
+
 func TestEOF(t *testing.T) {
 	report := test.CheckRoutines(t)
 	defer report()
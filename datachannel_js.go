@@ -193,6 +193,16 @@ func (d *DataChannel) Protocol() string {
 	return d.underlying.Get("protocol").String()
 }
 
+// Priority represents the priority of this DataChannel relative to the other
+// DataChannels of the PeerConnection.
+func (d *DataChannel) Priority() PriorityType {
+	priority := newPriorityType(valueToStringOrZero(d.underlying.Get("priority")))
+	if priority == PriorityType(Unknown) {
+		return PriorityTypeLow
+	}
+	return priority
+}
+
 // Negotiated represents whether this DataChannel was negotiated by the
 // application (true), or not (false).
 func (d *DataChannel) Negotiated() bool {
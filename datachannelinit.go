@@ -30,4 +30,8 @@ type DataChannelInit struct {
 
 	// ID overrides the default selection of ID for this channel.
 	ID *uint16
+
+	// Priority is the priority of this channel relative to the other channels
+	// of the PeerConnection. The default value is PriorityTypeLow.
+	Priority *PriorityType
 }
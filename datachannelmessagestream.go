@@ -0,0 +1,168 @@
+//go:build !js
+// +build !js
+
+package webrtc
+
+import (
+	"context"
+	"io"
+)
+
+const (
+	// dataChannelMessageChunkSize is the size of the DataChannel messages a streamed
+	// message is split into, including the chunk header. It is below the
+	// max-message-size of every known implementation.
+	dataChannelMessageChunkSize = 16384
+
+	// The chunk header is a single byte that carries the end of record (EOR) flag, like
+	// the E bit of an SCTP DATA chunk: it is set on the last chunk of a streamed message
+	dataChannelMessageChunkMore = 0
+	dataChannelMessageChunkEnd  = 1
+)
+
+// DataChannelMessageWriter streams a single message of arbitrary size to the
+// DataChannel peer, see DataChannel.OpenMessageWriter.
+type DataChannelMessageWriter struct {
+	dataChannel *DataChannel
+	isString    bool
+	chunk       []byte
+	closed      bool
+}
+
+// OpenMessageWriter starts a message that is streamed to the DataChannel peer. The
+// message is split into chunks that are sent as they are filled, the last one is sent
+// by Close and ends the record. Another message can't be sent on the DataChannel before that.
+//
+// Write blocks until the BufferedAmount of the DataChannel has fallen to the
+// BufferedAmountLowThreshold before it sends a chunk, so the threshold is the amount
+// of the message that may be in flight. It should be set to a multiple of the chunk size.
+//
+// pion/sctp only sends complete records, it has no explicit EOR mode (RFC 6458 Section
+// 8.1.26) that would let the chunks form a single SCTP message. The end of record is
+// therefore marked by a header byte in every chunk, and only a pion peer that receives
+// with OnMessageReader can read the message. Both peers have to agree on it, e.g.
+// through the Protocol of the DataChannel.
+func (d *DataChannel) OpenMessageWriter(isString bool) (*DataChannelMessageWriter, error) {
+	if err := d.ensureOpen(); err != nil {
+		return nil, err
+	}
+
+	d.messageWriterMu.Lock()
+	return &DataChannelMessageWriter{
+		dataChannel: d,
+		isString:    isString,
+		chunk:       newDataChannelMessageChunk(),
+	}, nil
+}
+
+func newDataChannelMessageChunk() []byte {
+	return make([]byte, 1, dataChannelMessageChunkSize)
+}
+
+// Write appends p to the message
+func (w *DataChannelMessageWriter) Write(p []byte) (int, error) {
+	if w.closed {
+		return 0, io.ErrClosedPipe
+	}
+
+	n := 0
+	for len(p) != 0 {
+		if len(w.chunk) == cap(w.chunk) {
+			if err := w.flush(dataChannelMessageChunkMore); err != nil {
+				return n, err
+			}
+		}
+
+		written := copy(w.chunk[len(w.chunk):cap(w.chunk)], p)
+		w.chunk = w.chunk[:len(w.chunk)+written]
+		p = p[written:]
+		n += written
+	}
+
+	return n, nil
+}
+
+// Close ends the message. The DataChannel peer reads io.EOF once it has read all of it.
+func (w *DataChannelMessageWriter) Close() error {
+	if w.closed {
+		return nil
+	}
+	w.closed = true
+	defer w.dataChannel.messageWriterMu.Unlock()
+
+	return w.flush(dataChannelMessageChunkEnd)
+}
+
+func (w *DataChannelMessageWriter) flush(header byte) error {
+	chunk := w.chunk
+	w.chunk = newDataChannelMessageChunk()
+
+	if err := w.dataChannel.waitBufferedAmountLow(context.Background()); err != nil {
+		return err
+	}
+
+	chunk[0] = header
+	return w.dataChannel.write(chunk, w.isString)
+}
+
+// DataChannelMessageReader reads a single message of arbitrary size that is streamed
+// by the DataChannel peer, see DataChannel.OnMessageReader.
+type DataChannelMessageReader struct {
+	// IsString is true if the message is text
+	IsString bool
+
+	reader *io.PipeReader
+}
+
+// Read reads the next part of the message. It returns io.EOF at the end of the message,
+// and io.ErrUnexpectedEOF if the DataChannel is closed before.
+func (r *DataChannelMessageReader) Read(p []byte) (int, error) {
+	return r.reader.Read(p)
+}
+
+// OnMessageReader sets an event handler which is invoked when the DataChannel peer
+// starts a message with OpenMessageWriter, it replaces the OnMessage handler. The
+// message is passed on while it arrives, it is not received any further while the
+// handler doesn't read. The rest of the message is dropped when the handler returns.
+func (d *DataChannel) OnMessageReader(f func(*DataChannelMessageReader)) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	d.onMessageReaderHandler = f
+}
+
+// onMessageChunk passes a chunk of a streamed message to the handler. It is
+// only called from the readLoop.
+func (d *DataChannel) onMessageChunk(msg DataChannelMessage, handler func(*DataChannelMessageReader)) {
+	if len(msg.Data) == 0 || msg.Data[0] > dataChannelMessageChunkEnd {
+		d.closeMessageReader(errDataChannelMessageChunkInvalid)
+		d.onError(errDataChannelMessageChunkInvalid)
+		return
+	}
+
+	if d.messageReader == nil {
+		reader, writer := io.Pipe()
+		d.messageReader = writer
+		go func() {
+			handler(&DataChannelMessageReader{IsString: msg.IsString, reader: reader})
+			_ = reader.Close()
+		}()
+	}
+
+	// Fails once the handler returned, the rest of the message is dropped
+	_, _ = d.messageReader.Write(msg.Data[1:])
+
+	if msg.Data[0] == dataChannelMessageChunkEnd {
+		d.closeMessageReader(nil)
+	}
+}
+
+// closeMessageReader ends the message that is currently read, if any. The reader
+// gets err after the data it has not read yet, io.EOF if err is nil.
+func (d *DataChannel) closeMessageReader(err error) {
+	if d.messageReader == nil {
+		return
+	}
+
+	_ = d.messageReader.CloseWithError(err)
+	d.messageReader = nil
+}
@@ -0,0 +1,227 @@
+//go:build !js
+// +build !js
+
+package webrtc
+
+import (
+	"io"
+	"sync"
+	"time"
+
+	"github.com/pion/datachannel"
+)
+
+const (
+	// dataChannelSchedulerBufferedAmount is the amount of data the DataChannels of an
+	// SCTPTransport may have buffered in the association before the scheduler holds
+	// messages back. The association sends the buffered data first in first out, so
+	// this bounds the time a message of a high priority DataChannel waits behind bulk data.
+	dataChannelSchedulerBufferedAmount = 256 << 10
+
+	// dataChannelSchedulerQuantum is the amount of data a DataChannel of PriorityTypeVeryLow
+	// may send per round, the other priorities get a multiple of it
+	dataChannelSchedulerQuantum = 1200
+
+	// dataChannelSchedulerInterval is how often the scheduler checks if the association
+	// has room for more data while messages are held back
+	dataChannelSchedulerInterval = 5 * time.Millisecond
+)
+
+type dataChannelSchedulerMessage struct {
+	dataChannel *datachannel.DataChannel
+	data        []byte
+	isString    bool
+}
+
+type dataChannelSchedulerQueue struct {
+	quantum  int
+	deficit  int
+	visited  bool
+	queued   uint64
+	messages []*dataChannelSchedulerMessage
+}
+
+// dataChannelScheduler shares the SCTP association of an SCTPTransport between its
+// DataChannels. Messages are written to the association directly as long as it has
+// room for them. Once it is full they are queued per DataChannel and written by the
+// scheduler as room becomes available, using deficit round robin weighted by the
+// Priority of the DataChannels. Queued messages count in the BufferedAmount of their
+// DataChannel. Detached DataChannels bypass the scheduler.
+type dataChannelScheduler struct {
+	mu      sync.Mutex
+	queues  map[*DataChannel]*dataChannelSchedulerQueue
+	order   []*DataChannel
+	current int
+	running bool
+	closed  bool
+
+	bufferedAmount func() uint64
+}
+
+func newDataChannelScheduler(bufferedAmount func() uint64) *dataChannelScheduler {
+	return &dataChannelScheduler{
+		queues:         map[*DataChannel]*dataChannelSchedulerQueue{},
+		bufferedAmount: bufferedAmount,
+	}
+}
+
+// send writes a message of d to dc, or queues it if the association is full. It doesn't block.
+// Queued messages that fail to be written are reported to the OnError handler of d.
+func (s *dataChannelScheduler) send(d *DataChannel, dc *datachannel.DataChannel, data []byte, isString bool) error {
+	s.mu.Lock()
+	if s.closed {
+		s.mu.Unlock()
+		return io.ErrClosedPipe
+	}
+
+	// Messages are only written directly while none are queued or being written by run,
+	// so that the messages of a DataChannel stay in order
+	if !s.running && s.hasRoom() {
+		s.mu.Unlock()
+		_, err := dc.WriteDataChannel(data, isString)
+		return err
+	}
+
+	queue, ok := s.queues[d]
+	if !ok {
+		queue = &dataChannelSchedulerQueue{quantum: d.Priority().schedulerWeight() * dataChannelSchedulerQuantum}
+		s.queues[d] = queue
+		s.order = append(s.order, d)
+	}
+	queue.messages = append(queue.messages, &dataChannelSchedulerMessage{
+		dataChannel: dc,
+		data:        data,
+		isString:    isString,
+	})
+	queue.queued += uint64(len(data))
+
+	if !s.running {
+		s.running = true
+		go s.run()
+	}
+	s.mu.Unlock()
+
+	return nil
+}
+
+// queuedAmount returns the amount of data of d waiting in the scheduler
+func (s *dataChannelScheduler) queuedAmount(d *DataChannel) uint64 {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if queue, ok := s.queues[d]; ok {
+		return queue.queued
+	}
+	return 0
+}
+
+func (s *dataChannelScheduler) hasRoom() bool {
+	return s.bufferedAmount() < dataChannelSchedulerBufferedAmount
+}
+
+func (s *dataChannelScheduler) run() {
+	ticker := time.NewTicker(dataChannelSchedulerInterval)
+	defer ticker.Stop()
+
+	for {
+		s.mu.Lock()
+		for len(s.order) != 0 && s.hasRoom() {
+			d, message, drained := s.next()
+			s.mu.Unlock()
+
+			// The other messages of d can't be sent either, they are dropped and OnError is fired once
+			if _, err := message.dataChannel.WriteDataChannel(message.data, message.isString); err != nil {
+				s.mu.Lock()
+				s.remove(d)
+				s.mu.Unlock()
+				d.onError(err)
+				drained = true
+			}
+
+			// The association may not cross the threshold once the queue is empty
+			if drained && d.BufferedAmount() <= d.BufferedAmountLowThreshold() {
+				d.handleBufferedAmountLow()
+			}
+			s.mu.Lock()
+		}
+		if len(s.order) == 0 {
+			s.running = false
+			s.mu.Unlock()
+			return
+		}
+		s.mu.Unlock()
+
+		<-ticker.C
+	}
+}
+
+// next removes the message that is sent next from the queues, and returns if it was the
+// last queued message of its DataChannel. Every DataChannel that has messages queued may
+// send up to its quantum per round, the unused part is kept for the next round so that
+// large messages are sent eventually.
+func (s *dataChannelScheduler) next() (*DataChannel, *dataChannelSchedulerMessage, bool) {
+	for {
+		d := s.order[s.current]
+		queue := s.queues[d]
+		if !queue.visited {
+			queue.deficit += queue.quantum
+			queue.visited = true
+		}
+
+		message := queue.messages[0]
+		if len(message.data) <= queue.deficit {
+			queue.deficit -= len(message.data)
+			queue.queued -= uint64(len(message.data))
+			queue.messages = queue.messages[1:]
+			if len(queue.messages) == 0 {
+				s.remove(d)
+				return d, message, true
+			}
+			return d, message, false
+		}
+
+		queue.visited = false
+		s.current = (s.current + 1) % len(s.order)
+	}
+}
+
+// remove drops the queued messages of d
+func (s *dataChannelScheduler) remove(d *DataChannel) {
+	if _, ok := s.queues[d]; !ok {
+		return
+	}
+	delete(s.queues, d)
+
+	for i := range s.order {
+		if s.order[i] != d {
+			continue
+		}
+		s.order = append(s.order[:i], s.order[i+1:]...)
+		if s.current > i {
+			s.current--
+		}
+		break
+	}
+	if s.current >= len(s.order) {
+		s.current = 0
+	}
+}
+
+// closeDataChannel drops the queued messages of a DataChannel that is closed
+func (s *dataChannelScheduler) closeDataChannel(d *DataChannel) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.remove(d)
+}
+
+// close drops all queued messages, messages sent afterwards fail with io.ErrClosedPipe
+func (s *dataChannelScheduler) close() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.closed = true
+	for len(s.order) != 0 {
+		s.remove(s.order[0])
+	}
+}
@@ -0,0 +1,49 @@
+//go:build !js
+// +build !js
+
+package webrtc
+
+import (
+	"io"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDataChannelScheduler_Next(t *testing.T) {
+	s := newDataChannelScheduler(func() uint64 { return 0 })
+
+	veryLow := &DataChannel{priority: PriorityTypeVeryLow}
+	high := &DataChannel{priority: PriorityTypeHigh}
+	owners := map[*dataChannelSchedulerMessage]*DataChannel{}
+	for _, d := range []*DataChannel{veryLow, high} {
+		queue := &dataChannelSchedulerQueue{quantum: d.priority.schedulerWeight() * dataChannelSchedulerQuantum}
+		for i := 0; i < 100; i++ {
+			message := &dataChannelSchedulerMessage{data: make([]byte, dataChannelSchedulerQuantum)}
+			queue.messages = append(queue.messages, message)
+			queue.queued += uint64(len(message.data))
+			owners[message] = d
+		}
+		s.queues[d] = queue
+		s.order = append(s.order, d)
+	}
+
+	// A DataChannel of PriorityTypeHigh sends 8 times as much as one of PriorityTypeVeryLow
+	sent := map[*DataChannel]int{}
+	for i := 0; i < 90; i++ {
+		d, message, _ := s.next()
+		assert.Equal(t, owners[message], d)
+		sent[d]++
+	}
+	assert.Equal(t, 10, sent[veryLow])
+	assert.Equal(t, 80, sent[high])
+
+	// The queued messages are part of the BufferedAmount
+	assert.Equal(t, uint64(90*dataChannelSchedulerQuantum), s.queuedAmount(veryLow))
+	assert.Equal(t, uint64(20*dataChannelSchedulerQuantum), s.queuedAmount(high))
+
+	// Queued messages are dropped once the scheduler is closed
+	s.close()
+	assert.Equal(t, uint64(0), s.queuedAmount(high))
+	assert.ErrorIs(t, s.send(high, nil, nil, false), io.ErrClosedPipe)
+}
@@ -4,7 +4,9 @@
 package webrtc
 
 import (
+	"crypto"
 	"crypto/ecdsa"
+	"crypto/ed25519"
 	"crypto/elliptic"
 	"crypto/rand"
 	"crypto/tls"
@@ -77,6 +79,12 @@ func (api *API) NewDTLSTransport(transport *ICETransport, certificates []Certifi
 			}
 			t.certificates = append(t.certificates, x509Cert)
 		}
+	} else if api.certificateStore != nil {
+		certificate, err := api.certificateStore.Certificate()
+		if err != nil {
+			return nil, err
+		}
+		t.certificates = []Certificate{certificate}
 	} else {
 		sk, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
 		if err != nil {
@@ -100,6 +108,18 @@ func (t *DTLSTransport) ICETransport() *ICETransport {
 	return t.iceTransport
 }
 
+// iceTransportStatsID returns the ID of the TransportStats of the ICETransport the
+// DTLSTransport runs over
+func (t *DTLSTransport) iceTransportStatsID() string {
+	if t == nil {
+		return ""
+	}
+	if iceTransport := t.ICETransport(); iceTransport != nil {
+		return iceTransport.statsID
+	}
+	return ""
+}
+
 // onStateChange requires the caller holds the lock
 func (t *DTLSTransport) onStateChange(state DTLSTransportState) {
 	t.state = state
@@ -293,16 +313,18 @@ func (t *DTLSTransport) Start(remoteParameters DTLSParameters) error {
 		t.srtcpEndpoint = t.iceTransport.newEndpoint(mux.MatchSRTCP)
 		t.remoteParameters = remoteParameters
 
-		cert := t.certificates[0]
+		certificates := make([]tls.Certificate, 0, len(t.certificates))
+		for _, cert := range t.certificates {
+			certificates = append(certificates, tls.Certificate{
+				Certificate: [][]byte{cert.x509Cert.Raw},
+				PrivateKey:  cert.privateKey,
+			})
+		}
 		t.onStateChange(DTLSTransportStateConnecting)
 
 		return t.role(), &dtls.Config{
-			Certificates: []tls.Certificate{
-				{
-					Certificate: [][]byte{cert.x509Cert.Raw},
-					PrivateKey:  cert.privateKey,
-				},
-			},
+			Certificates:   certificates,
+			GetCertificate: certificateForClientHello(certificates),
 			SRTPProtectionProfiles: func() []dtls.SRTPProtectionProfile {
 				if len(t.api.settingEngine.srtpProtectionProfiles) > 0 {
 					return t.api.settingEngine.srtpProtectionProfiles
@@ -429,11 +451,67 @@ func (t *DTLSTransport) Stop() error {
 	return util.FlattenErrs(closeErrs)
 }
 
+// certificateForClientHello returns the function that selects the certificate a DTLS server
+// presents. The certificate is chosen by the signature of the first cipher suite of the client
+// that one of the certificates can sign, ECDSA or RSA, otherwise the first certificate is used.
+func certificateForClientHello(certificates []tls.Certificate) func(*dtls.ClientHelloInfo) (*tls.Certificate, error) {
+	return func(hello *dtls.ClientHelloInfo) (*tls.Certificate, error) {
+		for _, id := range hello.CipherSuites {
+			suiteIsECDSA, usesCertificate := cipherSuiteIsECDSA(id)
+			if !usesCertificate {
+				continue
+			}
+
+			for i := range certificates {
+				if certificateIsECDSA(&certificates[i]) == suiteIsECDSA {
+					return &certificates[i], nil
+				}
+			}
+		}
+
+		return &certificates[0], nil
+	}
+}
+
+// cipherSuiteIsECDSA returns if a cipher suite of pion/dtls is signed with ECDSA or RSA,
+// usesCertificate is false for the suites that aren't authenticated with a certificate
+func cipherSuiteIsECDSA(id dtls.CipherSuiteID) (isECDSA, usesCertificate bool) {
+	switch id { // nolint:exhaustive
+	case dtls.TLS_ECDHE_ECDSA_WITH_AES_128_CCM,
+		dtls.TLS_ECDHE_ECDSA_WITH_AES_128_CCM_8,
+		dtls.TLS_ECDHE_ECDSA_WITH_AES_128_GCM_SHA256,
+		dtls.TLS_ECDHE_ECDSA_WITH_AES_256_GCM_SHA384,
+		dtls.TLS_ECDHE_ECDSA_WITH_AES_256_CBC_SHA:
+		return true, true
+	case dtls.TLS_ECDHE_RSA_WITH_AES_128_GCM_SHA256,
+		dtls.TLS_ECDHE_RSA_WITH_AES_256_GCM_SHA384,
+		dtls.TLS_ECDHE_RSA_WITH_AES_256_CBC_SHA:
+		return false, true
+	}
+	return false, false
+}
+
+// certificateIsECDSA returns if a certificate signs the ECDSA cipher suites, Ed25519 keys
+// are used with them like in pion/dtls
+func certificateIsECDSA(certificate *tls.Certificate) bool {
+	signer, ok := certificate.PrivateKey.(crypto.Signer)
+	if !ok {
+		return false
+	}
+
+	switch signer.Public().(type) {
+	case *ecdsa.PublicKey, ed25519.PublicKey:
+		return true
+	}
+	return false
+}
+
 func (t *DTLSTransport) validateFingerPrint(remoteCert *x509.Certificate) error {
 	for _, fp := range t.remoteParameters.Fingerprints {
+		// Fingerprints with an unknown hash function are ignored (RFC 8122 Section 5)
 		hashAlgo, err := fingerprint.HashFromString(fp.Algorithm)
 		if err != nil {
-			return err
+			continue
 		}
 
 		remoteValue, err := fingerprint.Fingerprint(remoteCert, hashAlgo)
@@ -492,7 +570,7 @@ func (t *DTLSTransport) streamsForSSRC(ssrc SSRC, streamInfo interceptor.StreamI
 
 	rtcpInterceptor := t.api.interceptor.BindRTCPReader(interceptor.RTPReaderFunc(func(in []byte, a interceptor.Attributes) (n int, attributes interceptor.Attributes, err error) {
 		n, err = rtcpReadStream.Read(in)
-		return n, a, err
+		return n, withRTCPStreamSSRC(a, ssrc), err
 	}))
 
 	return rtpReadStream, rtpInterceptor, rtcpReadStream, rtcpInterceptor, nil
@@ -4,10 +4,18 @@
 package webrtc
 
 import (
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/tls"
 	"regexp"
+	"strings"
 	"testing"
 	"time"
 
+	"github.com/pion/dtls/v2"
 	"github.com/pion/transport/test"
 	"github.com/stretchr/testify/assert"
 )
@@ -131,3 +139,77 @@ func TestPeerConnection_DTLSRoleSettingEngine(t *testing.T) {
 		runTest(DTLSRoleClient)
 	})
 }
+
+// A PeerConnection with an ECDSA and a RSA certificate offers both, and accepts a remote
+// that advertises fingerprints it can't use in addition to the one of its certificate
+func TestPeerConnection_MultipleCertificates(t *testing.T) {
+	lim := test.TimeOut(time.Second * 30)
+	defer lim.Stop()
+
+	report := test.CheckRoutines(t)
+	defer report()
+
+	ecdsaKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	assert.NoError(t, err)
+	ecdsaCertificate, err := GenerateCertificate(ecdsaKey)
+	assert.NoError(t, err)
+
+	rsaKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	assert.NoError(t, err)
+	rsaCertificate, err := GenerateCertificate(rsaKey)
+	assert.NoError(t, err)
+
+	pcOffer, err := NewPeerConnection(Configuration{Certificates: []Certificate{*ecdsaCertificate, *rsaCertificate}})
+	assert.NoError(t, err)
+
+	pcAnswer, err := NewPeerConnection(Configuration{})
+	assert.NoError(t, err)
+
+	connected := untilConnectionState(PeerConnectionStateConnected, pcOffer, pcAnswer)
+	assert.NoError(t, signalPairWithModification(pcOffer, pcAnswer, func(offer string) string {
+		assert.Equal(t, 2, strings.Count(offer, "a=fingerprint:sha-256 "))
+
+		return strings.Replace(offer, "a=fingerprint:", "a=fingerprint:sha-384 AA:AA:AA:AA:AA:AA:AA:AA:AA:AA:AA:AA:AA:AA:AA:AA:AA:AA:AA:AA:AA:AA:AA:AA:AA:AA:AA:AA:AA:AA:AA:AA:AA:AA:AA:AA:AA:AA:AA:AA:AA:AA:AA:AA:AA:AA:AA:AA\r\na=fingerprint:unknown-hash AA\r\na=fingerprint:", 1)
+	}))
+	connected.Wait()
+
+	closePairNow(t, pcOffer, pcAnswer)
+}
+
+func TestCertificateForClientHello(t *testing.T) {
+	ecdsaKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	assert.NoError(t, err)
+
+	rsaKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	assert.NoError(t, err)
+
+	_, ed25519Key, err := ed25519.GenerateKey(rand.Reader)
+	assert.NoError(t, err)
+
+	certificates := []tls.Certificate{{PrivateKey: rsaKey}, {PrivateKey: ecdsaKey}}
+	getCertificate := certificateForClientHello(certificates)
+
+	certificate, err := getCertificate(&dtls.ClientHelloInfo{CipherSuites: []dtls.CipherSuiteID{dtls.TLS_ECDHE_ECDSA_WITH_AES_128_GCM_SHA256, dtls.TLS_ECDHE_RSA_WITH_AES_128_GCM_SHA256}})
+	assert.NoError(t, err)
+	assert.Equal(t, &certificates[1], certificate)
+
+	certificate, err = getCertificate(&dtls.ClientHelloInfo{CipherSuites: []dtls.CipherSuiteID{dtls.TLS_ECDHE_ECDSA_WITH_AES_256_GCM_SHA384}})
+	assert.NoError(t, err)
+	assert.Equal(t, &certificates[1], certificate)
+
+	// The first suite of the client that has a certificate is used
+	certificate, err = getCertificate(&dtls.ClientHelloInfo{CipherSuites: []dtls.CipherSuiteID{dtls.TLS_PSK_WITH_AES_128_CCM, dtls.TLS_ECDHE_RSA_WITH_AES_256_GCM_SHA384, dtls.TLS_ECDHE_ECDSA_WITH_AES_128_GCM_SHA256}})
+	assert.NoError(t, err)
+	assert.Equal(t, &certificates[0], certificate)
+
+	// Ed25519 certificates sign the ECDSA suites
+	certificates = []tls.Certificate{{PrivateKey: rsaKey}, {PrivateKey: ed25519Key}}
+	certificate, err = certificateForClientHello(certificates)(&dtls.ClientHelloInfo{CipherSuites: []dtls.CipherSuiteID{dtls.TLS_ECDHE_ECDSA_WITH_AES_128_CCM}})
+	assert.NoError(t, err)
+	assert.Equal(t, &certificates[1], certificate)
+
+	// Without a matching certificate the first one is used
+	certificate, err = certificateForClientHello(certificates[1:])(&dtls.ClientHelloInfo{CipherSuites: []dtls.CipherSuiteID{dtls.TLS_ECDHE_RSA_WITH_AES_256_CBC_SHA}})
+	assert.NoError(t, err)
+	assert.Equal(t, &certificates[1], certificate)
+}
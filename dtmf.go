@@ -0,0 +1,66 @@
+package webrtc
+
+import (
+	"strings"
+)
+
+// dtmfTones are the tones a telephone-event can carry, indexed by their event code
+// https://tools.ietf.org/html/rfc4733#section-3.2
+const dtmfTones = "0123456789*#ABCD"
+
+// dtmfEventSize is the size of a telephone-event payload
+const dtmfEventSize = 4
+
+// dtmfEvent is the payload of a RFC 4733 telephone-event
+//
+//	 0                   1                   2                   3
+//	 0 1 2 3 4 5 6 7 8 9 0 1 2 3 4 5 6 7 8 9 0 1 2 3 4 5 6 7 8 9 0 1
+//	+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+
+//	|     event     |E|R| volume    |          duration             |
+//	+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+
+type dtmfEvent struct {
+	event      uint8
+	endOfEvent bool
+	volume     uint8
+	duration   uint16
+}
+
+func (e *dtmfEvent) marshal() []byte {
+	b := make([]byte, dtmfEventSize)
+	b[0] = e.event
+	b[1] = e.volume & 0x3F
+	if e.endOfEvent {
+		b[1] |= 0x80
+	}
+	b[2] = byte(e.duration >> 8)
+	b[3] = byte(e.duration)
+	return b
+}
+
+func (e *dtmfEvent) unmarshal(b []byte) error {
+	if len(b) < dtmfEventSize {
+		return errDTMFEventTooShort
+	}
+
+	e.event = b[0]
+	e.endOfEvent = b[1]&0x80 != 0
+	e.volume = b[1] & 0x3F
+	e.duration = uint16(b[2])<<8 | uint16(b[3])
+	return nil
+}
+
+// tone returns the DTMF tone of the event, or an empty string if the event is not a DTMF tone
+func (e *dtmfEvent) tone() string {
+	if int(e.event) >= len(dtmfTones) {
+		return ""
+	}
+	return dtmfTones[e.event : e.event+1]
+}
+
+func dtmfEventForTone(tone string) (uint8, bool) {
+	i := strings.Index(dtmfTones, strings.ToUpper(tone))
+	if len(tone) != 1 || i < 0 {
+		return 0, false
+	}
+	return uint8(i), true
+}
@@ -0,0 +1,31 @@
+package webrtc
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDTMFEvent(t *testing.T) {
+	event := &dtmfEvent{event: 11, endOfEvent: true, volume: 10, duration: 800}
+	raw := event.marshal()
+	assert.Equal(t, []byte{0x0B, 0x8A, 0x03, 0x20}, raw)
+
+	parsed := &dtmfEvent{}
+	assert.NoError(t, parsed.unmarshal(raw))
+	assert.Equal(t, event, parsed)
+	assert.Equal(t, "#", parsed.tone())
+
+	assert.Equal(t, errDTMFEventTooShort, parsed.unmarshal(raw[:3]))
+
+	// Events above 15 are not DTMF tones
+	parsed.event = 16
+	assert.Equal(t, "", parsed.tone())
+
+	code, ok := dtmfEventForTone("a")
+	assert.True(t, ok)
+	assert.Equal(t, uint8(12), code)
+
+	_, ok = dtmfEventForTone(",")
+	assert.False(t, ok)
+}
@@ -0,0 +1,305 @@
+//go:build !js
+// +build !js
+
+package webrtc
+
+import (
+	"fmt"
+	"io"
+	"math"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/pion/randutil"
+	"github.com/pion/rtp"
+	"github.com/pion/webrtc/v3/pkg/rtcerr"
+)
+
+const (
+	dtmfDefaultDuration     = 100 * time.Millisecond
+	dtmfMinDuration         = 40 * time.Millisecond
+	dtmfMaxDuration         = 6000 * time.Millisecond
+	dtmfDefaultInterToneGap = 70 * time.Millisecond
+	dtmfMinInterToneGap     = 30 * time.Millisecond
+
+	// dtmfPause is the delay a ',' in the tone buffer causes
+	dtmfPause = 2 * time.Second
+
+	// dtmfPacketInterval is how often a telephone-event is sent while a tone is played
+	dtmfPacketInterval = 50 * time.Millisecond
+
+	// dtmfEndPacketCount is how many times the final packet of a tone is sent, RFC 4733 Section 2.5.1.4
+	dtmfEndPacketCount = 3
+
+	// dtmfVolume is the power level of the tones in -dBm0
+	dtmfVolume = 10
+)
+
+// DTMFSender sends DTMF tones as RFC 4733 telephone-events on the SSRC of an audio RTPSender
+// https://w3c.github.io/webrtc-pc/#rtcdtmfsender
+type DTMFSender struct {
+	rtpSender *RTPSender
+
+	mu                  sync.Mutex
+	toneBuffer          string
+	duration            time.Duration
+	interToneGap        time.Duration
+	playing             bool
+	onToneChangeHandler func(string)
+}
+
+// CanInsertDTMF tells if tones can be sent. This requires the RTPSender to be sending
+// and telephone-event to be negotiated with the clock rate of the audio codec.
+func (d *DTMFSender) CanInsertDTMF() bool {
+	r := d.rtpSender
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	return r.hasSent() && !r.hasStopped() && r.trackEncodings[0].telephoneEvent != nil
+}
+
+// InsertDTMF replaces the tones that remain to be sent with the given ones. Tones are
+// the characters 0123456789ABCD#*, a ',' pauses for two seconds. duration is how long
+// every tone is played and interToneGap the silence between tones, zero selects the defaults.
+func (d *DTMFSender) InsertDTMF(tones string, duration, interToneGap time.Duration) error {
+	if d.rtpSender.hasStopped() {
+		return &rtcerr.InvalidStateError{Err: errRTPSenderStopped}
+	} else if !d.CanInsertDTMF() {
+		return &rtcerr.InvalidStateError{Err: errDTMFSenderCanNotInsert}
+	}
+
+	tones = strings.ToUpper(tones)
+	for _, tone := range tones {
+		if !strings.ContainsRune(dtmfTones+",", tone) {
+			return &rtcerr.SyntaxError{Err: fmt.Errorf("%w: %q", errDTMFSenderInvalidTone, tone)}
+		}
+	}
+
+	switch {
+	case duration == 0:
+		duration = dtmfDefaultDuration
+	case duration < dtmfMinDuration:
+		duration = dtmfMinDuration
+	case duration > dtmfMaxDuration:
+		duration = dtmfMaxDuration
+	}
+
+	switch {
+	case interToneGap == 0:
+		interToneGap = dtmfDefaultInterToneGap
+	case interToneGap < dtmfMinInterToneGap:
+		interToneGap = dtmfMinInterToneGap
+	}
+
+	d.mu.Lock()
+	d.toneBuffer = tones
+	d.duration = duration
+	d.interToneGap = interToneGap
+	start := !d.playing && tones != ""
+	d.playing = d.playing || start
+	d.mu.Unlock()
+
+	if start {
+		go d.playout()
+	}
+	return nil
+}
+
+// ToneBuffer returns the tones that remain to be sent
+func (d *DTMFSender) ToneBuffer() string {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	return d.toneBuffer
+}
+
+// OnToneChange sets an event handler which is called when a tone starts being
+// sent, and with an empty string once all tones have been sent.
+func (d *DTMFSender) OnToneChange(f func(tone string)) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	d.onToneChangeHandler = f
+}
+
+func (d *DTMFSender) playout() {
+	for {
+		d.mu.Lock()
+		handler := d.onToneChangeHandler
+		if d.toneBuffer == "" {
+			d.playing = false
+			d.mu.Unlock()
+
+			if handler != nil {
+				handler("")
+			}
+			return
+		}
+
+		tone := d.toneBuffer[:1]
+		d.toneBuffer = d.toneBuffer[1:]
+		duration, interToneGap := d.duration, d.interToneGap
+		d.mu.Unlock()
+
+		if handler != nil {
+			handler(tone)
+		}
+
+		var err error
+		if tone == "," {
+			err = d.wait(dtmfPause)
+		} else if err = d.sendTone(tone, duration); err == nil {
+			err = d.wait(interToneGap)
+		}
+
+		if err != nil {
+			d.mu.Lock()
+			d.toneBuffer = ""
+			d.playing = false
+			d.mu.Unlock()
+			return
+		}
+	}
+}
+
+func (d *DTMFSender) wait(duration time.Duration) error {
+	timer := time.NewTimer(duration)
+	defer timer.Stop()
+
+	select {
+	case <-timer.C:
+		return nil
+	case <-d.rtpSender.stopCalled:
+		return io.ErrClosedPipe
+	}
+}
+
+// sendTone sends the telephone-events of a single tone, the duration is sent in segments
+// if it doesn't fit in the 16 bit duration field, RFC 4733 Section 2.5.1.3
+func (d *DTMFSender) sendTone(tone string, duration time.Duration) error {
+	r := d.rtpSender
+	r.mu.RLock()
+	trackEncoding := r.trackEncodings[0]
+	telephoneEvent, writeStream, ssrc := trackEncoding.telephoneEvent, trackEncoding.writeStream, trackEncoding.ssrc
+	r.mu.RUnlock()
+
+	code, ok := dtmfEventForTone(tone)
+	if !ok || telephoneEvent == nil || writeStream == nil {
+		return errDTMFSenderCanNotInsert
+	}
+
+	clockRate := float64(telephoneEvent.ClockRate)
+	total := uint32(duration.Seconds() * clockRate)
+	step := uint32(dtmfPacketInterval.Seconds() * clockRate)
+
+	header := &rtp.Header{
+		Version:     2,
+		Marker:      true,
+		PayloadType: uint8(telephoneEvent.PayloadType),
+		Timestamp:   writeStream.sequencer.timestamp(telephoneEvent.ClockRate),
+		SSRC:        uint32(ssrc),
+	}
+	event := &dtmfEvent{event: code, volume: dtmfVolume}
+	write := func() error {
+		_, err := writeStream.insertRTP(header, event.marshal())
+		header.Marker = false
+		return err
+	}
+
+	ticker := time.NewTicker(dtmfPacketInterval)
+	defer ticker.Stop()
+
+	segmentStart := uint32(0)
+	for elapsed := step; ; elapsed += step {
+		if elapsed > total {
+			elapsed = total
+		}
+
+		for elapsed-segmentStart > math.MaxUint16 {
+			event.duration = math.MaxUint16
+			if err := write(); err != nil {
+				return err
+			}
+			segmentStart += math.MaxUint16
+			header.Timestamp += math.MaxUint16
+		}
+
+		event.duration = uint16(elapsed - segmentStart)
+		if elapsed == total {
+			event.endOfEvent = true
+			for i := 0; i < dtmfEndPacketCount; i++ {
+				if err := write(); err != nil {
+					return err
+				}
+			}
+			return nil
+		}
+
+		if err := write(); err != nil {
+			return err
+		}
+
+		select {
+		case <-ticker.C:
+		case <-r.stopCalled:
+			return io.ErrClosedPipe
+		}
+	}
+}
+
+// rtpSequencer rewrites the sequence numbers of the packets of a track, so that
+// packets that don't come from the track can be inserted in its stream and packets
+// of the track can be left out
+type rtpSequencer struct {
+	mu                 sync.Mutex
+	offset             uint16
+	lastSequenceNumber uint16
+	lastTimestamp      uint32
+	lastPacketTime     time.Time
+}
+
+func newRTPSequencer() *rtpSequencer {
+	generator := randutil.NewMathRandomGenerator()
+	return &rtpSequencer{
+		lastSequenceNumber: uint16(generator.Uint32()),
+		lastTimestamp:      generator.Uint32(),
+		lastPacketTime:     time.Now(),
+	}
+}
+
+// rewrite updates the sequence number of a packet of the track
+func (s *rtpSequencer) rewrite(header *rtp.Header) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	header.SequenceNumber += s.offset
+	s.lastSequenceNumber = header.SequenceNumber
+	s.lastTimestamp = header.Timestamp
+	s.lastPacketTime = time.Now()
+}
+
+// skip leaves a packet of the track out of the stream, the packets after it follow
+// the last packet of the stream without a gap
+func (s *rtpSequencer) skip() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.offset--
+}
+
+// insert returns the sequence number of a packet inserted in the stream
+func (s *rtpSequencer) insert() uint16 {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.offset++
+	s.lastSequenceNumber++
+	return s.lastSequenceNumber
+}
+
+// timestamp extrapolates the RTP timestamp of the stream for the current time
+func (s *rtpSequencer) timestamp(clockRate uint32) uint32 {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return s.lastTimestamp + uint32(time.Since(s.lastPacketTime).Seconds()*float64(clockRate))
+}
@@ -0,0 +1,149 @@
+//go:build !js
+// +build !js
+
+package webrtc
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/pion/rtp"
+	"github.com/pion/transport/test"
+	"github.com/pion/webrtc/v3/pkg/rtcerr"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDTMFSender(t *testing.T) {
+	lim := test.TimeOut(time.Second * 30)
+	defer lim.Stop()
+
+	report := test.CheckRoutines(t)
+	defer report()
+
+	m := &MediaEngine{}
+	assert.NoError(t, m.RegisterDefaultCodecs())
+	assert.NoError(t, m.RegisterTelephoneEventCodecs())
+	api := NewAPI(WithMediaEngine(m))
+
+	pcOffer, err := api.NewPeerConnection(Configuration{})
+	assert.NoError(t, err)
+
+	pcAnswer, err := api.NewPeerConnection(Configuration{})
+	assert.NoError(t, err)
+
+	track, err := NewTrackLocalStaticSample(RTPCodecCapability{MimeType: MimeTypeOpus}, "audio", "pion")
+	assert.NoError(t, err)
+
+	sender, err := pcOffer.AddTrack(track)
+	assert.NoError(t, err)
+
+	dtmf := sender.DTMF()
+	assert.NotNil(t, dtmf)
+	assert.False(t, dtmf.CanInsertDTMF())
+
+	var stateErr *rtcerr.InvalidStateError
+	assert.True(t, errors.As(dtmf.InsertDTMF("1", 0, 0), &stateErr))
+
+	receivedTones := make(chan string, 10)
+	trackReceived := make(chan struct{})
+	pcAnswer.OnTrack(func(track *TrackRemote, r *RTPReceiver) {
+		track.OnDTMF(func(tone string) {
+			receivedTones <- tone
+		})
+		close(trackReceived)
+
+		for {
+			pkt, _, readErr := track.ReadRTP()
+			if readErr != nil {
+				return
+			}
+			// telephone-events are only passed to OnDTMF
+			assert.Equal(t, track.PayloadType(), PayloadType(pkt.PayloadType))
+		}
+	})
+
+	done := make(chan struct{})
+	go sendVideoUntilDone(done, t, []*TrackLocalStaticSample{track})
+
+	assert.NoError(t, signalPair(pcOffer, pcAnswer))
+
+	untilConnectionState(PeerConnectionStateConnected, pcOffer, pcAnswer).Wait()
+	assert.True(t, dtmf.CanInsertDTMF())
+
+	// Tones received before OnDTMF is set are dropped
+	<-trackReceived
+
+	var syntaxErr *rtcerr.SyntaxError
+	assert.True(t, errors.As(dtmf.InsertDTMF("1x", 0, 0), &syntaxErr))
+
+	toneChanges := make(chan string, 10)
+	dtmf.OnToneChange(func(tone string) {
+		toneChanges <- tone
+	})
+	assert.NoError(t, dtmf.InsertDTMF("1#", 40*time.Millisecond, 30*time.Millisecond))
+
+	assert.Equal(t, "1", <-toneChanges)
+	assert.Equal(t, "#", <-toneChanges)
+	assert.Equal(t, "", <-toneChanges)
+	assert.Equal(t, "", dtmf.ToneBuffer())
+
+	assert.Equal(t, "1", <-receivedTones)
+	assert.Equal(t, "#", <-receivedTones)
+
+	close(done)
+	closePairNow(t, pcOffer, pcAnswer)
+}
+
+func TestDTMFSender_Video(t *testing.T) {
+	pc, err := NewPeerConnection(Configuration{})
+	assert.NoError(t, err)
+
+	track, err := NewTrackLocalStaticSample(RTPCodecCapability{MimeType: MimeTypeVP8}, "video", "pion")
+	assert.NoError(t, err)
+
+	sender, err := pc.AddTrack(track)
+	assert.NoError(t, err)
+	assert.Nil(t, sender.DTMF())
+
+	assert.NoError(t, pc.Close())
+}
+
+func TestMediaEngine_TelephoneEventOptIn(t *testing.T) {
+	m := &MediaEngine{}
+	assert.NoError(t, m.RegisterDefaultCodecs())
+	assert.Nil(t, findTelephoneEventCodec(48000, m.audioCodecs))
+
+	assert.NoError(t, m.RegisterTelephoneEventCodecs())
+	assert.NotNil(t, findTelephoneEventCodec(48000, m.audioCodecs))
+	assert.NotNil(t, findTelephoneEventCodec(8000, m.audioCodecs))
+}
+
+func TestTrackRemote_PeekedTelephoneEvent(t *testing.T) {
+	m := &MediaEngine{}
+	assert.NoError(t, m.RegisterDefaultCodecs())
+	assert.NoError(t, m.RegisterTelephoneEventCodecs())
+
+	received := make(chan interface{})
+	close(received)
+	track := newTrackRemote(RTPCodecTypeAudio, 1, "", &RTPReceiver{api: NewAPI(WithMediaEngine(m)), received: received})
+	track.payloadType = 111
+
+	tones := []string{}
+	track.OnDTMF(func(tone string) {
+		tones = append(tones, tone)
+	})
+
+	packet := &rtp.Packet{
+		Header:  rtp.Header{Version: 2, PayloadType: uint8(findTelephoneEventCodec(48000, m.audioCodecs).PayloadType), SSRC: 1},
+		Payload: (&dtmfEvent{event: 5, duration: 160}).marshal(),
+	}
+	raw, err := packet.Marshal()
+	assert.NoError(t, err)
+	track.peeked = raw
+
+	// The peeked telephone-event is passed to OnDTMF, and the next packet is read instead
+	_, _, err = track.Read(make([]byte, 1500))
+	assert.ErrorIs(t, err, errRTPReceiverWithSSRCTrackStreamNotFound)
+	assert.Equal(t, []string{"5"}, tones)
+}
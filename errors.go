@@ -159,6 +159,8 @@ var (
 
 	errDetachNotEnabled                 = errors.New("enable detaching by calling webrtc.DetachDataChannels()")
 	errDetachBeforeOpened               = errors.New("datachannel not opened yet, try calling Detach from OnOpen")
+	errDataChannelMessageChunkInvalid   = errors.New("datachannel received a message that is not a chunk of a streamed message")
+	errReadMessageDetached              = errors.New("ReadMessage is not available for detached datachannels")
 	errDtlsTransportNotStarted          = errors.New("the DTLS transport has not started yet")
 	errDtlsKeyExtractionFailed          = errors.New("failed extracting keys from DTLS for SRTP")
 	errFailedToStartSRTP                = errors.New("failed to start SRTP")
@@ -205,16 +207,26 @@ var (
 	errRTPReceiverWithSSRCTrackStreamNotFound = errors.New("unable to find stream for Track with SSRC")
 	errRTPReceiverForRIDTrackStreamNotFound   = errors.New("no trackStreams found for RID")
 
-	errRTPSenderTrackNil             = errors.New("Track must not be nil")
-	errRTPSenderDTLSTransportNil     = errors.New("DTLSTransport must not be nil")
-	errRTPSenderSendAlreadyCalled    = errors.New("Send has already been called")
-	errRTPSenderStopped              = errors.New("Sender has already been stopped")
-	errRTPSenderTrackRemoved         = errors.New("Sender Track has been removed or replaced to nil")
-	errRTPSenderRidNil               = errors.New("Sender cannot add encoding as rid is empty")
-	errRTPSenderNoBaseEncoding       = errors.New("Sender cannot add encoding as there is no base track")
-	errRTPSenderBaseEncodingMismatch = errors.New("Sender cannot add encoding as provided track does not match base track")
-	errRTPSenderRIDCollision         = errors.New("Sender cannot encoding due to RID collision")
-	errRTPSenderNoTrackForRID        = errors.New("Sender does not have track for RID")
+	errRTPSenderTrackNil              = errors.New("Track must not be nil")
+	errRTPSenderDTLSTransportNil      = errors.New("DTLSTransport must not be nil")
+	errRTPSenderSendAlreadyCalled     = errors.New("Send has already been called")
+	errRTPSenderStopped               = errors.New("Sender has already been stopped")
+	errRTPSenderTrackRemoved          = errors.New("Sender Track has been removed or replaced to nil")
+	errRTPSenderRidNil                = errors.New("Sender cannot add encoding as rid is empty")
+	errRTPSenderNoBaseEncoding        = errors.New("Sender cannot add encoding as there is no base track")
+	errRTPSenderBaseEncodingMismatch  = errors.New("Sender cannot add encoding as provided track does not match base track")
+	errRTPSenderRIDCollision          = errors.New("Sender cannot encoding due to RID collision")
+	errRTPSenderNoTrackForRID         = errors.New("Sender does not have track for RID")
+	errRTPSenderTransactionIDMismatch = errors.New("Sender parameters were not returned by the last GetParameters")
+	errRTPSenderEncodingsMismatch     = errors.New("Sender encodings can not be added, removed or reordered")
+	errRTPSenderScaleResolutionDownBy = errors.New("Sender ScaleResolutionDownBy must be at least 1")
+	errRTPSenderInvalidPriority       = errors.New("Sender encoding has an invalid priority")
+
+	errTrackLocalNotMediaStreamTrack = errors.New("TrackLocal must be backed by a MediaStreamTrack")
+
+	errDTMFEventTooShort      = errors.New("telephone-event payload is too short")
+	errDTMFSenderCanNotInsert = errors.New("DTMFSender can not insert tones, telephone-event has not been negotiated or the sender is not sending")
+	errDTMFSenderInvalidTone  = errors.New("DTMFSender tones must be one of 0123456789ABCD#*,")
 
 	errRTPTransceiverCannotChangeMid        = errors.New("errRTPSenderTrackNil")
 	errRTPTransceiverSetSendingInvalidState = errors.New("invalid state change in RTPTransceiver.setSending")
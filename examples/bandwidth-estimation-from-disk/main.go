@@ -8,6 +8,7 @@ import (
 	"fmt"
 	"io"
 	"os"
+	"sync/atomic"
 	"time"
 
 	"github.com/pion/interceptor"
@@ -56,34 +57,22 @@ func main() {
 		panic(err)
 	}
 
-	// Create a Congestion Controller. This analyzes inbound and outbound data and provides
+	if err := webrtc.RegisterDefaultInterceptors(m, i); err != nil {
+		panic(err)
+	}
+
+	// Enable the Congestion Controller. This analyzes inbound and outbound data and provides
 	// suggestions on how much we should be sending.
 	//
 	// Passing `nil` means we use the default Estimation Algorithm which is Google Congestion Control.
 	// You can use the other ones that Pion provides, or write your own!
-	congestionController, err := cc.NewInterceptor(func() (cc.BandwidthEstimator, error) {
+	s := webrtc.SettingEngine{}
+	s.EnableBandwidthEstimation(func() (cc.BandwidthEstimator, error) {
 		return gcc.NewSendSideBWE(gcc.SendSideBWEInitialBitrate(lowBitrate))
 	})
-	if err != nil {
-		panic(err)
-	}
-
-	estimatorChan := make(chan cc.BandwidthEstimator, 1)
-	congestionController.OnNewPeerConnection(func(id string, estimator cc.BandwidthEstimator) {
-		estimatorChan <- estimator
-	})
-
-	i.Add(congestionController)
-	if err = webrtc.ConfigureTWCCHeaderExtensionSender(m, i); err != nil {
-		panic(err)
-	}
-
-	if err = webrtc.RegisterDefaultInterceptors(m, i); err != nil {
-		panic(err)
-	}
 
 	// Create a new RTCPeerConnection
-	peerConnection, err := webrtc.NewAPI(webrtc.WithInterceptorRegistry(i), webrtc.WithMediaEngine(m)).NewPeerConnection(webrtc.Configuration{
+	peerConnection, err := webrtc.NewAPI(webrtc.WithSettingEngine(s), webrtc.WithInterceptorRegistry(i), webrtc.WithMediaEngine(m)).NewPeerConnection(webrtc.Configuration{
 		ICEServers: []webrtc.ICEServer{
 			{
 				URLs: []string{"stun:stun.l.google.com:19302"},
@@ -99,8 +88,11 @@ func main() {
 		}
 	}()
 
-	// Wait until our Bandwidth Estimator has been created
-	estimator := <-estimatorChan
+	// Store the latest estimate of the Congestion Controller
+	estimatedBitrate := int64(lowBitrate)
+	peerConnection.OnBandwidthEstimate(func(bitrate int) {
+		atomic.StoreInt64(&estimatedBitrate, int64(bitrate))
+	})
 
 	// Create a video track
 	videoTrack, err := webrtc.NewTrackLocalStaticSample(webrtc.RTPCodecCapability{MimeType: webrtc.MimeTypeVP8}, "video", "pion")
@@ -204,7 +196,7 @@ func main() {
 	}
 
 	for ; true; <-ticker.C {
-		targetBitrate := estimator.GetTargetBitrate()
+		targetBitrate := int(atomic.LoadInt64(&estimatedBitrate))
 		switch {
 		// If current quality level is below target bitrate drop to level below
 		case currentQuality != 0 && targetBitrate < qualityLevels[currentQuality].bitrate:
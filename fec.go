@@ -0,0 +1,353 @@
+//go:build !js
+// +build !js
+
+package webrtc
+
+import (
+	"encoding/binary"
+	"sync"
+
+	"github.com/pion/interceptor"
+	"github.com/pion/rtp"
+	"github.com/pion/webrtc/v3/internal/fec"
+)
+
+// fecEncoder adds FEC protection to the packets written to a track. FlexFEC-03 packets are
+// sent on their own SSRC, ULPFEC packets are sent inside of RED on the SSRC of the media.
+type fecEncoder struct {
+	mu sync.Mutex
+
+	// A protection packet is sent every groupSize media packets
+	groupSize int
+	group     [][]byte
+	groupBase uint16
+
+	flexFECSSRC           SSRC
+	flexFECPayloadType    PayloadType
+	flexFECSequenceNumber uint16
+
+	// Set for ULPFEC, the protection packets are inserted in the sequence numbers of the media
+	redPayloadType, ulpfecPayloadType PayloadType
+	sequencer                         *rtpSequencer
+}
+
+// fecGroupSize returns how many media packets are protected by a single protection packet
+// for an overhead in percent
+func fecGroupSize(overhead uint8) int {
+	groupSize := (100 + int(overhead) - 1) / int(overhead)
+	if groupSize > fec.MaxGroupSize {
+		return fec.MaxGroupSize
+	}
+	return groupSize
+}
+
+func newFlexFECEncoder(overhead uint8, ssrc SSRC, payloadType PayloadType) *fecEncoder {
+	return &fecEncoder{
+		groupSize:          fecGroupSize(overhead),
+		flexFECSSRC:        ssrc,
+		flexFECPayloadType: payloadType,
+	}
+}
+
+func newULPFECEncoder(overhead uint8, redPayloadType, ulpfecPayloadType PayloadType, sequencer *rtpSequencer) *fecEncoder {
+	return &fecEncoder{
+		groupSize:         fecGroupSize(overhead),
+		redPayloadType:    redPayloadType,
+		ulpfecPayloadType: ulpfecPayloadType,
+		sequencer:         sequencer,
+	}
+}
+
+// fecProtectAttribute is set on the attributes of the media packets written by writeRTP. They are
+// protected once they have been through the interceptors, so the FEC covers the packets as they
+// are sent, including the header extensions added by interceptors.
+type fecProtectAttribute struct{}
+
+// writeRTP writes a media packet through the interceptors, the media is put inside of RED for ULPFEC
+func (e *fecEncoder) writeRTP(header *rtp.Header, payload []byte, write func(*rtp.Header, []byte, interceptor.Attributes) (int, error)) (int, error) {
+	attributes := interceptor.Attributes{fecProtectAttribute{}: true}
+	if e.sequencer == nil {
+		return write(header, payload, attributes)
+	}
+
+	red, err := fec.MarshalRED([]fec.REDBlock{{PayloadType: header.PayloadType, Data: payload}})
+	if err != nil {
+		return 0, err
+	}
+
+	redHeader := *header
+	redHeader.PayloadType = uint8(e.redPayloadType)
+	return write(&redHeader, red, attributes)
+}
+
+// writeProtected sends a media packet that has been through the interceptors, followed by a
+// protection packet if a group is complete
+func (e *fecEncoder) writeProtected(header *rtp.Header, payload []byte, write func(*rtp.Header, []byte) (int, error)) (int, error) {
+	n, err := write(header, payload)
+	if err != nil {
+		return n, err
+	}
+
+	// ULPFEC protects the media without its RED encapsulation
+	media := &rtp.Packet{Header: *header, Payload: payload}
+	if e.sequencer != nil {
+		blocks, redErr := fec.UnmarshalRED(payload)
+		if redErr != nil {
+			return n, redErr
+		}
+
+		media.PayloadType = blocks[len(blocks)-1].PayloadType
+		media.Payload = blocks[len(blocks)-1].Data
+	}
+
+	raw, err := media.Marshal()
+	if err != nil {
+		return n, err
+	}
+
+	if protectionHeader, protection := e.protect(header, raw); protection != nil {
+		if _, err = write(protectionHeader, protection); err != nil {
+			return n, err
+		}
+	}
+
+	return n, nil
+}
+
+// protect adds a media packet to the group, once the group is complete the protection packet is returned
+func (e *fecEncoder) protect(header *rtp.Header, raw []byte) (*rtp.Header, []byte) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	// The sequence numbers of a group have to fit the mask, start over after a jump
+	if len(e.group) != 0 && header.SequenceNumber-e.groupBase >= fec.MaxGroupSize {
+		e.group = nil
+	}
+	if len(e.group) == 0 {
+		e.groupBase = header.SequenceNumber
+	}
+
+	e.group = append(e.group, raw)
+	if len(e.group) < e.groupSize {
+		return nil, nil
+	}
+
+	group := e.group
+	e.group = nil
+
+	if e.sequencer == nil {
+		protection, err := fec.EncodeFlexFEC03(group)
+		if err != nil {
+			return nil, nil
+		}
+
+		protectionHeader := &rtp.Header{
+			Version:        2,
+			PayloadType:    uint8(e.flexFECPayloadType),
+			SequenceNumber: e.flexFECSequenceNumber,
+			Timestamp:      header.Timestamp,
+			SSRC:           uint32(e.flexFECSSRC),
+		}
+		e.flexFECSequenceNumber++
+		return protectionHeader, protection
+	}
+
+	protection, err := fec.EncodeULPFEC(group)
+	if err != nil {
+		return nil, nil
+	}
+
+	red, err := fec.MarshalRED([]fec.REDBlock{{PayloadType: uint8(e.ulpfecPayloadType), Data: protection}})
+	if err != nil {
+		return nil, nil
+	}
+
+	return &rtp.Header{
+		Version:        2,
+		PayloadType:    uint8(e.redPayloadType),
+		SequenceNumber: e.sequencer.insert(),
+		Timestamp:      header.Timestamp,
+		SSRC:           header.SSRC,
+	}, red
+}
+
+// fecDecoder recovers the lost packets of a remote stream from the FEC received for it,
+// and removes the RED encapsulation of ULPFEC protected media and of redundant audio.
+type fecDecoder struct {
+	mu        sync.Mutex
+	recoverer *fec.Recoverer
+	recovered [][]byte
+
+	red, ulpfec *RTPCodecParameters
+
+	// Set for audio, the redundant encodings of RED fill the gaps after the newest packet read
+	redundancy              bool
+	newestSequenceNumber    uint16
+	hasNewestSequenceNumber bool
+}
+
+// newFECDecoder returns a decoder for video protected by FEC, nil if neither FlexFEC nor RED was negotiated
+func newFECDecoder(codecs []RTPCodecParameters) *fecDecoder {
+	red := findFECCodec(MimeTypeRED, codecs)
+	if red == nil && findFECCodec(MimeTypeFlexFEC03, codecs) == nil {
+		return nil
+	}
+
+	return &fecDecoder{
+		recoverer: fec.NewRecoverer(),
+		red:       red,
+		ulpfec:    findFECCodec(MimeTypeULPFEC, codecs),
+	}
+}
+
+// newAudioREDDecoder returns a decoder for audio sent as RED, nil if audio/red wasn't negotiated
+func newAudioREDDecoder(codecs []RTPCodecParameters) *fecDecoder {
+	red := findFECCodec(MimeTypeAudioRED, codecs)
+	if red == nil {
+		return nil
+	}
+
+	return &fecDecoder{
+		recoverer:  fec.NewRecoverer(),
+		red:        red,
+		redundancy: true,
+	}
+}
+
+// popRecovered copies the oldest recovered packet into b
+func (d *fecDecoder) popRecovered(b []byte) (int, bool) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	if len(d.recovered) == 0 {
+		return 0, false
+	}
+
+	n := copy(b, d.recovered[0])
+	d.recovered = d.recovered[1:]
+	return n, true
+}
+
+// processRTP handles a packet read from the stream of the media, it is rewritten in place if it
+// was inside of RED. It returns the length of the packet the application reads, or 0 if the
+// packet carried FEC or has already been recovered and has to be dropped.
+func (d *fecDecoder) processRTP(b []byte, n int) int {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	if n < 2 {
+		return n
+	}
+
+	if d.red != nil && PayloadType(b[1]&rtpPayloadTypeBitmask) == d.red.PayloadType {
+		packet := &rtp.Packet{}
+		if err := packet.Unmarshal(b[:n]); err != nil {
+			return 0
+		}
+
+		blocks, err := fec.UnmarshalRED(packet.Payload)
+		if err != nil {
+			return 0
+		}
+
+		primary := blocks[len(blocks)-1]
+		if d.ulpfec != nil && PayloadType(primary.PayloadType) == d.ulpfec.PayloadType {
+			if recovered, err := d.recoverer.PushULPFEC(packet.SSRC, primary.Data); err == nil {
+				d.recovered = append(d.recovered, recovered...)
+			}
+			return 0
+		}
+
+		var redundant [][]byte
+		if d.redundancy {
+			redundant = d.recoverRedundancy(packet, blocks)
+		}
+
+		packet.PayloadType = primary.PayloadType
+		packet.Payload = append([]byte{}, primary.Data...)
+		packet.Padding = false
+		if n, err = packet.MarshalTo(b); err != nil {
+			return 0
+		}
+
+		// The redundant encodings are older, they are read before the primary one
+		if len(redundant) != 0 {
+			d.recovered = append(d.recovered, redundant...)
+			if _, duplicate := d.recoverer.Push(b[:n]); !duplicate {
+				d.updateNewestSequenceNumber(packet.SequenceNumber)
+				d.recovered = append(d.recovered, append([]byte{}, b[:n]...))
+			}
+			return 0
+		}
+	}
+
+	recovered, duplicate := d.recoverer.Push(b[:n])
+	d.recovered = append(d.recovered, recovered...)
+	if duplicate {
+		return 0
+	}
+
+	if d.redundancy {
+		d.updateNewestSequenceNumber(binary.BigEndian.Uint16(b[2:4]))
+	}
+
+	return n
+}
+
+// recoverRedundancy returns the redundant encodings of a RED packet for the packets
+// between the newest one read and it, that have been lost.
+func (d *fecDecoder) recoverRedundancy(packet *rtp.Packet, blocks []fec.REDBlock) (recovered [][]byte) {
+	if !d.hasNewestSequenceNumber {
+		return nil
+	}
+
+	for i, block := range blocks[:len(blocks)-1] {
+		sequenceNumber := packet.SequenceNumber - uint16(len(blocks)-1-i)
+		if diff := sequenceNumber - d.newestSequenceNumber; diff == 0 || diff >= 0x8000 {
+			continue
+		}
+
+		raw, err := (&rtp.Packet{
+			Header: rtp.Header{
+				Version:        2,
+				PayloadType:    block.PayloadType,
+				SequenceNumber: sequenceNumber,
+				Timestamp:      packet.Timestamp - uint32(block.TimestampOffset),
+				SSRC:           packet.SSRC,
+			},
+			Payload: block.Data,
+		}).Marshal()
+		if err != nil {
+			continue
+		}
+
+		if _, duplicate := d.recoverer.Push(raw); !duplicate {
+			d.updateNewestSequenceNumber(sequenceNumber)
+			recovered = append(recovered, raw)
+		}
+	}
+
+	return recovered
+}
+
+func (d *fecDecoder) updateNewestSequenceNumber(sequenceNumber uint16) {
+	if !d.hasNewestSequenceNumber || sequenceNumber-d.newestSequenceNumber < 0x8000 {
+		d.newestSequenceNumber = sequenceNumber
+		d.hasNewestSequenceNumber = true
+	}
+}
+
+// processFlexFEC handles a packet read from the FlexFEC stream that protects the media
+func (d *fecDecoder) processFlexFEC(b []byte) {
+	packet := &rtp.Packet{}
+	if err := packet.Unmarshal(b); err != nil {
+		return
+	}
+
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	if recovered, err := d.recoverer.PushFlexFEC03(packet.Payload); err == nil {
+		d.recovered = append(d.recovered, recovered...)
+	}
+}
@@ -19,6 +19,10 @@ type ICECandidate struct {
 	RelatedAddress string           `json:"relatedAddress"`
 	RelatedPort    uint16           `json:"relatedPort"`
 	TCPType        string           `json:"tcpType"`
+
+	// The media section of a local candidate, only set for media sections that are not bundled
+	sdpMid        string
+	sdpMLineIndex uint16
 }
 
 // Conversion for package ice
@@ -67,6 +71,29 @@ func newICECandidateFromICE(i ice.Candidate) (ICECandidate, error) {
 	return c, nil
 }
 
+// newICECandidateFromStats converts the stats of a candidate the ICE agent knows, e.g. a
+// peer reflexive one. The Foundation and related address of the candidate are not known.
+func newICECandidateFromStats(stats ice.CandidateStats) (ICECandidate, error) {
+	typ, err := convertTypeFromICE(stats.CandidateType)
+	if err != nil {
+		return ICECandidate{}, err
+	}
+	protocol, err := NewICEProtocol(stats.NetworkType.NetworkShort())
+	if err != nil {
+		return ICECandidate{}, err
+	}
+
+	return ICECandidate{
+		statsID:   stats.ID,
+		Priority:  stats.Priority,
+		Address:   stats.IP,
+		Protocol:  protocol,
+		Port:      uint16(stats.Port),
+		Component: uint16(ICEComponentRTP),
+		Typ:       typ,
+	}, nil
+}
+
 func (c ICECandidate) toICE() (ice.Candidate, error) {
 	candidateID := c.statsID
 	switch c.Typ {
@@ -152,8 +179,8 @@ func (c ICECandidate) String() string {
 // ToJSON returns an ICECandidateInit
 // as indicated by the spec https://w3c.github.io/webrtc-pc/#dom-rtcicecandidate-tojson
 func (c ICECandidate) ToJSON() ICECandidateInit {
-	zeroVal := uint16(0)
-	emptyStr := ""
+	sdpMid := c.sdpMid
+	sdpMLineIndex := c.sdpMLineIndex
 	candidateStr := ""
 
 	candidate, err := c.toICE()
@@ -163,7 +190,7 @@ func (c ICECandidate) ToJSON() ICECandidateInit {
 
 	return ICECandidateInit{
 		Candidate:     fmt.Sprintf("candidate:%s", candidateStr),
-		SDPMid:        &emptyStr,
-		SDPMLineIndex: &zeroVal,
+		SDPMid:        &sdpMid,
+		SDPMLineIndex: &sdpMLineIndex,
 	}
 }
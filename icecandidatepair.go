@@ -27,3 +27,38 @@ func NewICECandidatePair(local, remote *ICECandidate) *ICECandidatePair {
 		Remote:  remote,
 	}
 }
+
+// ICECandidatePairInfo describes a candidate pair in the checklist of an ICETransport
+type ICECandidatePairInfo struct {
+	ICECandidatePair
+
+	// State is the state of the connectivity checks of the pair
+	State StatsICECandidatePairState
+
+	// Priority is the priority of the pair as defined in RFC 8445 Section 6.1.2.3
+	Priority uint64
+
+	// Nominated is true if the pair was nominated to be used for the traffic
+	Nominated bool
+
+	// CurrentRoundTripTime is the latest round trip time of the STUN connectivity checks on
+	// the pair in seconds, as the ICE Agent reports it in its candidate pair stats. It is 0
+	// with versions of pion/ice that don't measure it
+	CurrentRoundTripTime float64
+}
+
+// newICECandidatePairPriority computes the priority of a pair from the priorities of
+// the candidates of the controlling and the controlled agent
+func newICECandidatePairPriority(controlling, controlled uint32) uint64 {
+	g, d := uint64(controlling), uint64(controlled)
+	minimum, maximum := g, d
+	if minimum > maximum {
+		minimum, maximum = maximum, minimum
+	}
+
+	priority := minimum<<32 + 2*maximum
+	if g > d {
+		priority++
+	}
+	return priority
+}
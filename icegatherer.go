@@ -32,6 +32,17 @@ type ICEGatherer struct {
 	// Used for GatheringCompletePromise
 	onGatheringCompleteHandler atomic.Value // func()
 
+	// Set if the gatherer belongs to a media section that isn't bundled, its candidates carry them
+	sdpMid        string
+	sdpMLineIndex uint16
+
+	// Set while candidates are gathered ahead of Gather for the ICE candidate pool,
+	// they are held back until Gather is called
+	poolLock         sync.Mutex
+	pooling          bool
+	pooledCandidates []*ICECandidate
+	poolComplete     bool
+
 	api *API
 }
 
@@ -149,35 +160,150 @@ func (g *ICEGatherer) Gather() error {
 		return fmt.Errorf("%w: unable to gather", errICEAgentNotExist)
 	}
 
+	// Candidates have been gathered ahead of time, hand them out instead of gathering again
+	g.poolLock.Lock()
+	pooling := g.pooling
+	g.poolLock.Unlock()
+	if pooling {
+		g.flushPool()
+		return nil
+	}
+
+	g.setState(ICEGathererStateGathering)
+	if err := agent.OnCandidate(g.onCandidate); err != nil {
+		return err
+	}
+	return agent.GatherCandidates()
+}
+
+// gatherPool starts gathering candidates before Gather has been called. The state
+// of the ICEGatherer is left untouched and the candidates are held back until Gather is called.
+func (g *ICEGatherer) gatherPool() error {
+	if err := g.createAgent(); err != nil {
+		return err
+	}
+
+	agent := g.getAgent()
+	// it is possible agent had just been closed
+	if agent == nil {
+		return fmt.Errorf("%w: unable to gather", errICEAgentNotExist)
+	}
+
+	g.poolLock.Lock()
+	if g.pooling || g.State() != ICEGathererStateNew {
+		g.poolLock.Unlock()
+		return nil
+	}
+	g.pooling = true
+	g.poolLock.Unlock()
+
+	if err := agent.OnCandidate(g.onCandidate); err != nil {
+		return err
+	}
+	return agent.GatherCandidates()
+}
+
+// discardPool drops the candidates gathered by gatherPool and closes the agent
+// that gathered them. A new agent is created the next time the ICEGatherer is used.
+func (g *ICEGatherer) discardPool() error {
+	g.poolLock.Lock()
+	pooling := g.pooling
+	g.poolLock.Unlock()
+	if !pooling {
+		return nil
+	}
+
+	g.lock.Lock()
+	agent := g.agent
+	g.agent = nil
+	g.lock.Unlock()
+
+	var err error
+	if agent != nil {
+		err = agent.Close()
+	}
+
+	g.poolLock.Lock()
+	g.pooling = false
+	g.pooledCandidates, g.poolComplete = nil, false
+	g.poolLock.Unlock()
+
+	return err
+}
+
+// flushPool hands out the candidates that have been gathered by gatherPool. The handlers
+// are called without holding `g.poolLock`, candidates gathered meanwhile are pooled until
+// the pool is empty so they are handed out in order.
+func (g *ICEGatherer) flushPool() {
 	g.setState(ICEGathererStateGathering)
-	if err := agent.OnCandidate(func(candidate ice.Candidate) {
-		onLocalCandidateHandler := func(*ICECandidate) {}
-		if handler, ok := g.onLocalCandidateHandler.Load().(func(candidate *ICECandidate)); ok && handler != nil {
-			onLocalCandidateHandler = handler
+
+	for {
+		g.poolLock.Lock()
+		candidates, complete := g.pooledCandidates, g.poolComplete
+		g.pooledCandidates, g.poolComplete = nil, false
+		if len(candidates) == 0 && !complete {
+			g.pooling = false
+			g.poolLock.Unlock()
+			return
 		}
+		g.poolLock.Unlock()
 
-		onGatheringCompleteHandler := func() {}
-		if handler, ok := g.onGatheringCompleteHandler.Load().(func()); ok && handler != nil {
-			onGatheringCompleteHandler = handler
+		for _, c := range candidates {
+			g.emitCandidate(c)
 		}
+		if complete {
+			g.emitCandidate(nil)
+		}
+	}
+}
 
-		if candidate != nil {
-			c, err := newICECandidateFromICE(candidate)
-			if err != nil {
-				g.log.Warnf("Failed to convert ice.Candidate: %s", err)
-				return
-			}
-			onLocalCandidateHandler(&c)
-		} else {
-			g.setState(ICEGathererStateComplete)
+func (g *ICEGatherer) onCandidate(candidate ice.Candidate) {
+	var c *ICECandidate
+	if candidate != nil {
+		iceCandidate, err := newICECandidateFromICE(candidate)
+		if err != nil {
+			g.log.Warnf("Failed to convert ice.Candidate: %s", err)
+			return
+		}
+		c = &iceCandidate
+	}
 
-			onGatheringCompleteHandler()
-			onLocalCandidateHandler(nil)
+	g.poolLock.Lock()
+	if g.pooling {
+		if c == nil {
+			g.poolComplete = true
+		} else {
+			g.pooledCandidates = append(g.pooledCandidates, c)
 		}
-	}); err != nil {
-		return err
+		g.poolLock.Unlock()
+		return
+	}
+	g.poolLock.Unlock()
+
+	g.emitCandidate(c)
+}
+
+// emitCandidate fires the handlers for a local candidate, a nil candidate marks the end of gathering
+func (g *ICEGatherer) emitCandidate(c *ICECandidate) {
+	onLocalCandidateHandler := func(*ICECandidate) {}
+	if handler, ok := g.onLocalCandidateHandler.Load().(func(candidate *ICECandidate)); ok && handler != nil {
+		onLocalCandidateHandler = handler
+	}
+
+	onGatheringCompleteHandler := func() {}
+	if handler, ok := g.onGatheringCompleteHandler.Load().(func()); ok && handler != nil {
+		onGatheringCompleteHandler = handler
+	}
+
+	if c != nil {
+		c.sdpMid, c.sdpMLineIndex = g.sdpMid, g.sdpMLineIndex
+		onLocalCandidateHandler(c)
+	} else {
+		g.setState(ICEGathererStateComplete)
+
+		onGatheringCompleteHandler()
+		onLocalCandidateHandler(nil)
 	}
-	return agent.GatherCandidates()
 }
 
 // Close prunes all local candidates, and closes the ports.
@@ -35,6 +35,10 @@ type ICETransport struct {
 	ctx       context.Context
 	ctxCancel func()
 
+	// The ID of its TransportStats, the ICETransports of media sections that have their own
+	// transport get the mid as suffix
+	statsID string
+
 	loggerFactory logging.LoggerFactory
 
 	log logging.LeveledLogger
@@ -66,10 +70,106 @@ func (t *ICETransport) GetSelectedCandidatePair() (*ICECandidatePair, error) {
 	return &ICECandidatePair{Local: &local, Remote: &remote}, nil
 }
 
+// GetLocalCandidates returns the candidates the ICE agent gathered
+func (t *ICETransport) GetLocalCandidates() ([]ICECandidate, error) {
+	agent := t.gatherer.getAgent()
+	if agent == nil {
+		return nil, fmt.Errorf("%w: unable to get local candidates", errICEAgentNotExist)
+	}
+
+	return getLocalCandidates(agent)
+}
+
+func getLocalCandidates(agent *ice.Agent) ([]ICECandidate, error) {
+	iceCandidates, err := agent.GetLocalCandidates()
+	if err != nil {
+		return nil, err
+	}
+	return newICECandidatesFromICE(iceCandidates)
+}
+
+// GetRemoteCandidates returns the candidates of the remote ICETransport, including the
+// peer reflexive ones the ICE agent discovered through connectivity checks
+func (t *ICETransport) GetRemoteCandidates() ([]ICECandidate, error) {
+	agent := t.gatherer.getAgent()
+	if agent == nil {
+		return nil, fmt.Errorf("%w: unable to get remote candidates", errICEAgentNotExist)
+	}
+
+	return getRemoteCandidates(agent)
+}
+
+func getRemoteCandidates(agent *ice.Agent) ([]ICECandidate, error) {
+	candidates := []ICECandidate{}
+	for _, stats := range agent.GetRemoteCandidatesStats() {
+		c, err := newICECandidateFromStats(stats)
+		if err != nil {
+			return nil, err
+		}
+		candidates = append(candidates, c)
+	}
+	return candidates, nil
+}
+
+// GetCandidatePairs returns the checklist of the ICE agent, every pair of a local
+// and a remote candidate with the state of its connectivity checks
+func (t *ICETransport) GetCandidatePairs() ([]ICECandidatePairInfo, error) {
+	agent := t.gatherer.getAgent()
+	if agent == nil {
+		return nil, fmt.Errorf("%w: unable to get candidate pairs", errICEAgentNotExist)
+	}
+
+	local, err := getLocalCandidates(agent)
+	if err != nil {
+		return nil, err
+	}
+	remote, err := getRemoteCandidates(agent)
+	if err != nil {
+		return nil, err
+	}
+
+	candidates := map[string]*ICECandidate{}
+	for i := range local {
+		candidates[local[i].statsID] = &local[i]
+	}
+	for i := range remote {
+		candidates[remote[i].statsID] = &remote[i]
+	}
+
+	isControlling := t.Role() == ICERoleControlling
+	pairs := []ICECandidatePairInfo{}
+	for _, stats := range agent.GetCandidatePairsStats() {
+		localCandidate, remoteCandidate := candidates[stats.LocalCandidateID], candidates[stats.RemoteCandidateID]
+		if localCandidate == nil || remoteCandidate == nil {
+			continue
+		}
+
+		state, err := toStatsICECandidatePairState(stats.State)
+		if err != nil {
+			return nil, err
+		}
+
+		priority := newICECandidatePairPriority(remoteCandidate.Priority, localCandidate.Priority)
+		if isControlling {
+			priority = newICECandidatePairPriority(localCandidate.Priority, remoteCandidate.Priority)
+		}
+
+		pairs = append(pairs, ICECandidatePairInfo{
+			ICECandidatePair:     *NewICECandidatePair(localCandidate, remoteCandidate),
+			State:                state,
+			Priority:             priority,
+			Nominated:            stats.Nominated,
+			CurrentRoundTripTime: stats.CurrentRoundTripTime,
+		})
+	}
+	return pairs, nil
+}
+
 // NewICETransport creates a new NewICETransport.
 func NewICETransport(gatherer *ICEGatherer, loggerFactory logging.LoggerFactory) *ICETransport {
 	iceTransport := &ICETransport{
 		gatherer:      gatherer,
+		statsID:       "iceTransport",
 		loggerFactory: loggerFactory,
 		log:           loggerFactory.NewLogger("ortc"),
 	}
@@ -332,7 +432,7 @@ func (t *ICETransport) collectStats(collector *statsReportCollector) {
 	stats := TransportStats{
 		Timestamp: statsTimestampFrom(time.Now()),
 		Type:      StatsTypeTransport,
-		ID:        "iceTransport",
+		ID:        t.statsID,
 	}
 
 	if conn != nil {
@@ -113,3 +113,63 @@ func TestICETransport_GetSelectedCandidatePair(t *testing.T) {
 
 	closePairNow(t, offerer, answerer)
 }
+
+func TestICETransport_GetCandidatePairs(t *testing.T) {
+	offerer, answerer, err := newPair()
+	assert.NoError(t, err)
+
+	peerConnectionConnected := untilConnectionState(PeerConnectionStateConnected, offerer, answerer)
+
+	assert.NoError(t, signalPair(offerer, answerer))
+	peerConnectionConnected.Wait()
+
+	for _, pc := range []*PeerConnection{offerer, answerer} {
+		iceTransport := pc.SCTP().Transport().ICETransport()
+
+		selectedPair, selectedErr := iceTransport.GetSelectedCandidatePair()
+		assert.NoError(t, selectedErr)
+
+		localCandidates, localErr := iceTransport.GetLocalCandidates()
+		assert.NoError(t, localErr)
+		assert.NotEmpty(t, localCandidates)
+
+		remoteCandidates, remoteErr := iceTransport.GetRemoteCandidates()
+		assert.NoError(t, remoteErr)
+		assert.NotEmpty(t, remoteCandidates)
+
+		pairs, pairsErr := iceTransport.GetCandidatePairs()
+		assert.NoError(t, pairsErr)
+
+		report := pc.GetStats()
+
+		found := false
+		for i := range pairs {
+			pair := pairs[i]
+			assert.Contains(t, localCandidates, *pair.Local)
+			assert.Contains(t, remoteCandidates, *pair.Remote)
+
+			// The round trip time is the one the ICE Agent measured on the pair
+			pairStats, ok := report.GetICECandidatePairStats(&pair.ICECandidatePair)
+			assert.True(t, ok)
+			assert.Equal(t, pairStats.CurrentRoundTripTime, pair.CurrentRoundTripTime)
+
+			// The candidates of the selected pair are copies with other IDs
+			if pair.Local.Address != selectedPair.Local.Address || pair.Local.Port != selectedPair.Local.Port ||
+				pair.Remote.Address != selectedPair.Remote.Address || pair.Remote.Port != selectedPair.Remote.Port {
+				continue
+			}
+			found = true
+			assert.Equal(t, StatsICECandidatePairStateSucceeded, pair.State)
+			assert.NotZero(t, pair.Priority)
+		}
+		assert.True(t, found)
+	}
+
+	closePairNow(t, offerer, answerer)
+}
+
+func TestNewICECandidatePairPriority(t *testing.T) {
+	assert.Equal(t, uint64(1<<32+2*2+1), newICECandidatePairPriority(2, 1))
+	assert.Equal(t, uint64(1<<32+2*2), newICECandidatePairPriority(1, 2))
+	assert.Equal(t, uint64(2<<32+2*2), newICECandidatePairPriority(2, 2))
+}
@@ -105,3 +105,24 @@ func (c ICETransportState) toICE() ice.ConnectionState {
 		return ice.ConnectionState(Unknown)
 	}
 }
+
+func (c ICETransportState) toICEConnectionState() ICEConnectionState {
+	switch c {
+	case ICETransportStateNew:
+		return ICEConnectionStateNew
+	case ICETransportStateChecking:
+		return ICEConnectionStateChecking
+	case ICETransportStateConnected:
+		return ICEConnectionStateConnected
+	case ICETransportStateCompleted:
+		return ICEConnectionStateCompleted
+	case ICETransportStateFailed:
+		return ICEConnectionStateFailed
+	case ICETransportStateDisconnected:
+		return ICEConnectionStateDisconnected
+	case ICETransportStateClosed:
+		return ICEConnectionStateClosed
+	default:
+		return ICEConnectionState(Unknown)
+	}
+}
@@ -7,6 +7,7 @@ import (
 	"sync/atomic"
 
 	"github.com/pion/interceptor"
+	"github.com/pion/interceptor/pkg/cc"
 	"github.com/pion/interceptor/pkg/nack"
 	"github.com/pion/interceptor/pkg/report"
 	"github.com/pion/interceptor/pkg/twcc"
@@ -57,10 +58,8 @@ func ConfigureNack(mediaEngine *MediaEngine, interceptorRegistry *interceptor.Re
 		return err
 	}
 
-	responder, err := nack.NewResponderInterceptor()
-	if err != nil {
-		return err
-	}
+	// Answers NACKs with RTX (RFC 4588) packets when a repair flow has been negotiated
+	responder := &rtxResponderInterceptorFactory{}
 
 	mediaEngine.RegisterFeedback(RTCPFeedback{Type: "nack"}, RTPCodecTypeVideo)
 	mediaEngine.RegisterFeedback(RTCPFeedback{Type: "nack", Parameter: "pli"}, RTPCodecTypeVideo)
@@ -110,11 +109,81 @@ func ConfigureTWCCSender(mediaEngine *MediaEngine, interceptorRegistry *intercep
 	return nil
 }
 
-type interceptorToTrackLocalWriter struct{ interceptor atomic.Value } // interceptor.RTPWriter }
+// createBandwidthEstimator creates the interceptors a single PeerConnection needs for sender side
+// bandwidth estimation. The first one runs the BandwidthEstimator over the TWCC feedback, the
+// second one adds the transport-wide sequence numbers that feedback refers to.
+func createBandwidthEstimator(factory cc.BandwidthEstimatorFactory) (cc.BandwidthEstimator, []interceptor.Interceptor, error) {
+	congestionControllerFactory, err := cc.NewInterceptor(factory)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	var estimator cc.BandwidthEstimator
+	congestionControllerFactory.OnNewPeerConnection(func(_ string, e cc.BandwidthEstimator) {
+		estimator = e
+	})
+
+	congestionController, err := congestionControllerFactory.NewInterceptor("")
+	if err != nil {
+		return nil, nil, err
+	}
+
+	headerExtensionFactory, err := twcc.NewHeaderExtensionInterceptor()
+	if err != nil {
+		return nil, nil, err
+	}
+
+	headerExtension, err := headerExtensionFactory.NewInterceptor("")
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return estimator, []interceptor.Interceptor{congestionController, headerExtension}, nil
+}
+
+type interceptorToTrackLocalWriter struct {
+	interceptor atomic.Value // interceptor.RTPWriter
+	paused      *atomicBool
+	sequencer   *rtpSequencer
+	fec         *fecEncoder
+}
 
 func (i *interceptorToTrackLocalWriter) WriteRTP(header *rtp.Header, payload []byte) (int, error) {
+	// Paused encodings are dropped before the interceptors, without a gap in the sequence numbers
+	if i.paused != nil && i.paused.get() {
+		if i.sequencer != nil {
+			i.sequencer.skip()
+		}
+		return 0, nil
+	}
+
+	if i.sequencer != nil {
+		rewritten := *header
+		i.sequencer.rewrite(&rewritten)
+		header = &rewritten
+	}
+
+	if i.fec != nil {
+		return i.fec.writeRTP(header, payload, i.writeWithAttributes)
+	}
+
+	return i.write(header, payload)
+}
+
+// insertRTP writes a packet that doesn't come from the track, like a DTMF telephone-event,
+// in the stream of the track. The sequence number of the header is set by the sequencer
+func (i *interceptorToTrackLocalWriter) insertRTP(header *rtp.Header, payload []byte) (int, error) {
+	header.SequenceNumber = i.sequencer.insert()
+	return i.write(header, payload)
+}
+
+func (i *interceptorToTrackLocalWriter) write(header *rtp.Header, payload []byte) (int, error) {
+	return i.writeWithAttributes(header, payload, interceptor.Attributes{})
+}
+
+func (i *interceptorToTrackLocalWriter) writeWithAttributes(header *rtp.Header, payload []byte, attributes interceptor.Attributes) (int, error) {
 	if writer, ok := i.interceptor.Load().(interceptor.RTPWriter); ok && writer != nil {
-		return writer.Write(header, payload, interceptor.Attributes{})
+		return writer.Write(header, payload, attributes)
 	}
 
 	return 0, nil
@@ -198,19 +198,20 @@ func Test_Interceptor_BindUnbind(t *testing.T) {
 	if cnt := atomic.LoadUint32(&cntUnbindLocalStream); cnt != 1 {
 		t.Errorf("UnbindLocalStreamFn is expected to be called once, but called %d times", cnt)
 	}
-	if cnt := atomic.LoadUint32(&cntBindRemoteStream); cnt != 1 {
-		t.Errorf("BindRemoteStreamFn is expected to be called once, but called %d times", cnt)
+	// The receiver binds the RTX repair stream of the video track too.
+	if cnt := atomic.LoadUint32(&cntBindRemoteStream); cnt != 2 {
+		t.Errorf("BindRemoteStreamFn is expected to be called twice, but called %d times", cnt)
 	}
-	if cnt := atomic.LoadUint32(&cntUnbindRemoteStream); cnt != 1 {
-		t.Errorf("UnbindRemoteStreamFn is expected to be called once, but called %d times", cnt)
+	if cnt := atomic.LoadUint32(&cntUnbindRemoteStream); cnt != 2 {
+		t.Errorf("UnbindRemoteStreamFn is expected to be called twice, but called %d times", cnt)
 	}
 
 	// BindRTCPWriter/Reader and Close should be called from both side.
 	if cnt := atomic.LoadUint32(&cntBindRTCPWriter); cnt != 2 {
 		t.Errorf("BindRTCPWriterFn is expected to be called twice, but called %d times", cnt)
 	}
-	if cnt := atomic.LoadUint32(&cntBindRTCPReader); cnt != 2 {
-		t.Errorf("BindRTCPReaderFn is expected to be called twice, but called %d times", cnt)
+	if cnt := atomic.LoadUint32(&cntBindRTCPReader); cnt != 3 {
+		t.Errorf("BindRTCPReaderFn is expected to be called 3 times, but called %d times", cnt)
 	}
 	if cnt := atomic.LoadUint32(&cntClose); cnt != 2 {
 		t.Errorf("CloseFn is expected to be called twice, but called %d times", cnt)
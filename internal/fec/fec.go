@@ -0,0 +1,118 @@
+// Package fec implements the XOR based Forward Error Correction of FlexFEC-03
+// (draft-ietf-payload-flexible-fec-scheme-03) and ULPFEC (RFC 5109), and the
+// RTP payload for redundant data (RFC 2198) that ULPFEC is carried in.
+package fec
+
+import (
+	"encoding/binary"
+	"errors"
+)
+
+const (
+	rtpHeaderSize  = 12
+	rtpVersionBits = 0x80
+
+	// MaxGroupSize is the largest amount of media packets a single protection packet covers
+	MaxGroupSize = 15
+
+	recovererBufferSize     = 256
+	recovererMaxProtections = 32
+)
+
+var (
+	errPacketTooShort   = errors.New("fec: packet is too short")
+	errGroupSize        = errors.New("fec: invalid amount of packets to protect")
+	errGroupTooSparse   = errors.New("fec: packets to protect are too far apart")
+	errGroupSSRCs       = errors.New("fec: packets to protect have different SSRCs")
+	errUnsupportedMask  = errors.New("fec: unsupported protection mask")
+	errUnsupportedSSRCs = errors.New("fec: only protection of a single SSRC is supported")
+	errRetransmissionOn = errors.New("fec: retransmission bit is set")
+	errBlockTooLong     = errors.New("fec: RED block is too long")
+	errTimestampOffset  = errors.New("fec: RED timestamp offset is too large")
+)
+
+// recoveryFields is the XOR of the parts of RTP packets that are protected
+type recoveryFields struct {
+	first, second byte
+	timestamp     uint32
+	length        uint16
+	payload       []byte
+}
+
+// add XORs a marshaled RTP packet into the fields
+func (r *recoveryFields) add(packet []byte) {
+	r.first ^= packet[0]
+	r.second ^= packet[1]
+	r.timestamp ^= binary.BigEndian.Uint32(packet[4:8])
+	r.length ^= uint16(len(packet) - rtpHeaderSize)
+
+	body := packet[rtpHeaderSize:]
+	if len(body) > len(r.payload) {
+		r.payload = append(r.payload, make([]byte, len(body)-len(r.payload))...)
+	}
+	for i := range body {
+		r.payload[i] ^= body[i]
+	}
+}
+
+// packet builds the RTP packet that is left once all others of the group have been added
+func (r *recoveryFields) packet(ssrc uint32, sequenceNumber uint16) ([]byte, bool) {
+	if int(r.length) > len(r.payload) {
+		return nil, false
+	}
+
+	packet := make([]byte, rtpHeaderSize+int(r.length))
+	packet[0] = rtpVersionBits | (r.first & 0x3f)
+	packet[1] = r.second
+	binary.BigEndian.PutUint16(packet[2:4], sequenceNumber)
+	binary.BigEndian.PutUint32(packet[4:8], r.timestamp)
+	binary.BigEndian.PutUint32(packet[8:12], ssrc)
+	copy(packet[rtpHeaderSize:], r.payload[:r.length])
+	return packet, true
+}
+
+func (r *recoveryFields) clone() recoveryFields {
+	cloned := *r
+	cloned.payload = append([]byte{}, r.payload...)
+	return cloned
+}
+
+// protect computes the recovery fields of packets, that must all have the same SSRC and
+// sequence numbers less than MaxGroupSize apart. The sequence number of the first packet is the base.
+func protect(packets [][]byte) (fields recoveryFields, base uint16, mask uint16, err error) {
+	if len(packets) == 0 || len(packets) > MaxGroupSize {
+		return fields, 0, 0, errGroupSize
+	}
+
+	for _, packet := range packets {
+		if len(packet) < rtpHeaderSize {
+			return fields, 0, 0, errPacketTooShort
+		}
+	}
+
+	ssrc := binary.BigEndian.Uint32(packets[0][8:12])
+	base = binary.BigEndian.Uint16(packets[0][2:4])
+	for _, packet := range packets {
+		if binary.BigEndian.Uint32(packet[8:12]) != ssrc {
+			return fields, 0, 0, errGroupSSRCs
+		}
+
+		offset := binary.BigEndian.Uint16(packet[2:4]) - base
+		if offset >= MaxGroupSize {
+			return fields, 0, 0, errGroupTooSparse
+		}
+
+		mask |= 1 << (15 - offset)
+		fields.add(packet)
+	}
+
+	return fields, base, mask, nil
+}
+
+func sequenceNumber(packet []byte) uint16 {
+	return binary.BigEndian.Uint16(packet[2:4])
+}
+
+func ssrc(packet []byte) uint32 {
+	return binary.BigEndian.Uint32(packet[8:12])
+}
@@ -0,0 +1,175 @@
+package fec
+
+import (
+	"bytes"
+	"encoding/binary"
+	"reflect"
+	"testing"
+)
+
+func testPacket(seq uint16, payloadSize int) []byte {
+	packet := make([]byte, rtpHeaderSize+payloadSize)
+	packet[0] = rtpVersionBits
+	packet[1] = 96
+	if seq%3 == 0 {
+		packet[1] |= 0x80
+	}
+	binary.BigEndian.PutUint16(packet[2:4], seq)
+	binary.BigEndian.PutUint32(packet[4:8], uint32(seq)*3000)
+	binary.BigEndian.PutUint32(packet[8:12], 0xdeadbeef)
+	for i := rtpHeaderSize; i < len(packet); i++ {
+		packet[i] = byte(int(seq) + i)
+	}
+	return packet
+}
+
+func testGroup(base uint16, size int) [][]byte {
+	packets := [][]byte{}
+	for i := 0; i < size; i++ {
+		packets = append(packets, testPacket(base+uint16(i), 20+i*7))
+	}
+	return packets
+}
+
+func TestRecover(t *testing.T) {
+	for name, push := range map[string]func(r *Recoverer, packets [][]byte) ([][]byte, error){
+		"FlexFEC03": func(r *Recoverer, packets [][]byte) ([][]byte, error) {
+			payload, err := EncodeFlexFEC03(packets)
+			if err != nil {
+				return nil, err
+			}
+			return r.PushFlexFEC03(payload)
+		},
+		"ULPFEC": func(r *Recoverer, packets [][]byte) ([][]byte, error) {
+			payload, err := EncodeULPFEC(packets)
+			if err != nil {
+				return nil, err
+			}
+			return r.PushULPFEC(0xdeadbeef, payload)
+		},
+	} {
+		push := push
+		t.Run(name, func(t *testing.T) {
+			// The group wraps around the sequence numbers
+			packets := testGroup(65533, 5)
+			for lost := range packets {
+				r := NewRecoverer()
+				for i, packet := range packets {
+					if i == lost {
+						continue
+					}
+					if recovered, duplicate := r.Push(packet); len(recovered) != 0 || duplicate {
+						t.Fatalf("Unexpected result of Push %v %v", recovered, duplicate)
+					}
+				}
+
+				recovered, err := push(r, packets)
+				if err != nil {
+					t.Fatal(err)
+				}
+				if len(recovered) != 1 || !bytes.Equal(recovered[0], packets[lost]) {
+					t.Fatalf("Packet %d not recovered: %v", lost, recovered)
+				}
+
+				if _, duplicate := r.Push(packets[lost]); !duplicate {
+					t.Fatal("Recovered packet not detected as duplicate")
+				}
+			}
+		})
+	}
+}
+
+func TestRecoverProtectionFirst(t *testing.T) {
+	packets := testGroup(100, 4)
+	payload, err := EncodeFlexFEC03(packets)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	r := NewRecoverer()
+	if recovered, err := r.PushFlexFEC03(payload); err != nil || len(recovered) != 0 {
+		t.Fatalf("Unexpected result of PushFlexFEC03 %v %v", recovered, err)
+	}
+
+	r.Push(packets[0])
+	r.Push(packets[1])
+	recovered, _ := r.Push(packets[3])
+	if len(recovered) != 1 || !bytes.Equal(recovered[0], packets[2]) {
+		t.Fatalf("Packet not recovered: %v", recovered)
+	}
+}
+
+func TestRecoverTwoLost(t *testing.T) {
+	packets := testGroup(100, 4)
+	payload, err := EncodeULPFEC(packets)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	r := NewRecoverer()
+	r.Push(packets[0])
+	r.Push(packets[1])
+	if recovered, err := r.PushULPFEC(0xdeadbeef, payload); err != nil || len(recovered) != 0 {
+		t.Fatalf("Unexpected result of PushULPFEC %v %v", recovered, err)
+	}
+}
+
+func TestProtectInvalidGroup(t *testing.T) {
+	if _, err := EncodeFlexFEC03(nil); err != errGroupSize {
+		t.Fatalf("Expected %v, got %v", errGroupSize, err)
+	}
+
+	if _, err := EncodeULPFEC(testGroup(0, MaxGroupSize+1)); err != errGroupSize {
+		t.Fatalf("Expected %v, got %v", errGroupSize, err)
+	}
+
+	if _, err := EncodeFlexFEC03([][]byte{testPacket(0, 10), testPacket(MaxGroupSize, 10)}); err != errGroupTooSparse {
+		t.Fatalf("Expected %v, got %v", errGroupTooSparse, err)
+	}
+
+	otherSSRC := testPacket(1, 10)
+	otherSSRC[8] = 0
+	if _, err := EncodeULPFEC([][]byte{testPacket(0, 10), otherSSRC}); err != errGroupSSRCs {
+		t.Fatalf("Expected %v, got %v", errGroupSSRCs, err)
+	}
+}
+
+func TestRED(t *testing.T) {
+	blocks := []REDBlock{
+		{PayloadType: 111, TimestampOffset: 960, Data: []byte{0x01, 0x02, 0x03}},
+		{PayloadType: 111, TimestampOffset: 480, Data: []byte{}},
+		{PayloadType: 111, Data: []byte{0x04, 0x05}},
+	}
+
+	payload, err := MarshalRED(blocks)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expected := []byte{
+		0xef, 0x0f, 0x00, 0x03,
+		0xef, 0x07, 0x80, 0x00,
+		0x6f,
+		0x01, 0x02, 0x03,
+		0x04, 0x05,
+	}
+	if !bytes.Equal(payload, expected) {
+		t.Fatalf("Expected %x, got %x", expected, payload)
+	}
+
+	unmarshaled, err := UnmarshalRED(payload)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(unmarshaled, blocks) {
+		t.Fatalf("Expected %v, got %v", blocks, unmarshaled)
+	}
+
+	if _, err := UnmarshalRED(expected[:6]); err != errPacketTooShort {
+		t.Fatalf("Expected %v, got %v", errPacketTooShort, err)
+	}
+
+	if _, err := MarshalRED([]REDBlock{{TimestampOffset: REDMaxTimestampOffset + 1}, {}}); err != errTimestampOffset {
+		t.Fatalf("Expected %v, got %v", errTimestampOffset, err)
+	}
+}
@@ -0,0 +1,86 @@
+package fec
+
+import (
+	"encoding/binary"
+)
+
+// flexFEC03HeaderSize is the size of a FlexFEC-03 header with a 15 bit mask, the
+// only one that is written. The 46 bit mask is accepted when reading.
+const flexFEC03HeaderSize = 20
+
+// EncodeFlexFEC03 returns the payload of a FlexFEC-03 packet that protects packets. They must
+// be marshaled RTP packets of a single SSRC, with sequence numbers less than MaxGroupSize apart.
+func EncodeFlexFEC03(packets [][]byte) ([]byte, error) {
+	fields, base, mask, err := protect(packets)
+	if err != nil {
+		return nil, err
+	}
+
+	payload := make([]byte, flexFEC03HeaderSize+len(fields.payload))
+	// The R and F bits are zero, this isn't a retransmission and the mask is flexible
+	payload[0] = fields.first & 0x3f
+	payload[1] = fields.second
+	binary.BigEndian.PutUint16(payload[2:4], fields.length)
+	binary.BigEndian.PutUint32(payload[4:8], fields.timestamp)
+	payload[8] = 1 // SSRCCount
+	binary.BigEndian.PutUint32(payload[12:16], ssrc(packets[0]))
+	binary.BigEndian.PutUint16(payload[16:18], base)
+	// The k bit is set, the mask ends after 15 bits
+	binary.BigEndian.PutUint16(payload[18:20], 0x8000|mask>>1)
+	copy(payload[flexFEC03HeaderSize:], fields.payload)
+
+	return payload, nil
+}
+
+func parseFlexFEC03(payload []byte) (*protection, error) {
+	switch {
+	case len(payload) < flexFEC03HeaderSize:
+		return nil, errPacketTooShort
+	case payload[0]&0x80 != 0:
+		return nil, errRetransmissionOn
+	case payload[0]&0x40 != 0:
+		return nil, errUnsupportedMask
+	case payload[8] != 1:
+		return nil, errUnsupportedSSRCs
+	}
+
+	p := &protection{
+		fields: recoveryFields{
+			first:     payload[0] & 0x3f,
+			second:    payload[1],
+			length:    binary.BigEndian.Uint16(payload[2:4]),
+			timestamp: binary.BigEndian.Uint32(payload[4:8]),
+		},
+		ssrc: binary.BigEndian.Uint32(payload[12:16]),
+	}
+	base := binary.BigEndian.Uint16(payload[16:18])
+
+	headerSize := flexFEC03HeaderSize
+	mask := binary.BigEndian.Uint16(payload[18:20])
+	for i := uint16(0); i < 15; i++ {
+		if mask&(1<<(14-i)) != 0 {
+			p.sequenceNumbers = append(p.sequenceNumbers, base+i)
+		}
+	}
+
+	if mask&0x8000 == 0 {
+		if len(payload) < flexFEC03HeaderSize+4 {
+			return nil, errPacketTooShort
+		}
+
+		extendedMask := binary.BigEndian.Uint32(payload[20:24])
+		if extendedMask&0x80000000 == 0 {
+			return nil, errUnsupportedMask
+		}
+
+		for i := uint16(0); i < 31; i++ {
+			if extendedMask&(1<<(30-i)) != 0 {
+				p.sequenceNumbers = append(p.sequenceNumbers, base+15+i)
+			}
+		}
+		headerSize += 4
+	}
+
+	p.fields.payload = append([]byte{}, payload[headerSize:]...)
+	return p, nil
+}
@@ -0,0 +1,116 @@
+package fec
+
+// protection is a parsed protection packet
+type protection struct {
+	fields          recoveryFields
+	ssrc            uint32
+	sequenceNumbers []uint16
+}
+
+// Recoverer keeps the recently received media packets of a stream, and recovers
+// the lost ones from the protection packets that cover them.
+// A Recoverer is not safe for concurrent use.
+type Recoverer struct {
+	packets     [recovererBufferSize][]byte
+	protections []*protection
+}
+
+// NewRecoverer creates a new Recoverer
+func NewRecoverer() *Recoverer {
+	return &Recoverer{}
+}
+
+// Push adds a received media packet, a marshaled RTP packet. It returns the packets that can be
+// recovered now, and if the packet has already been received or recovered before.
+func (r *Recoverer) Push(packet []byte) (recovered [][]byte, duplicate bool) {
+	if len(packet) < rtpHeaderSize {
+		return nil, false
+	}
+
+	if r.has(sequenceNumber(packet)) {
+		return nil, true
+	}
+
+	r.store(append([]byte{}, packet...))
+	return r.recover(), false
+}
+
+// PushFlexFEC03 adds the payload of a received FlexFEC-03 packet. It returns the packets that can be recovered now.
+func (r *Recoverer) PushFlexFEC03(payload []byte) ([][]byte, error) {
+	p, err := parseFlexFEC03(payload)
+	if err != nil {
+		return nil, err
+	}
+
+	r.addProtection(p)
+	return r.recover(), nil
+}
+
+// PushULPFEC adds the payload of a received ULPFEC packet, that protects packets of ssrc.
+// It returns the packets that can be recovered now.
+func (r *Recoverer) PushULPFEC(ssrc uint32, payload []byte) ([][]byte, error) {
+	p, err := parseULPFEC(ssrc, payload)
+	if err != nil {
+		return nil, err
+	}
+
+	r.addProtection(p)
+	return r.recover(), nil
+}
+
+func (r *Recoverer) has(seq uint16) bool {
+	packet := r.packets[seq%recovererBufferSize]
+	return packet != nil && sequenceNumber(packet) == seq
+}
+
+func (r *Recoverer) store(packet []byte) {
+	r.packets[sequenceNumber(packet)%recovererBufferSize] = packet
+}
+
+func (r *Recoverer) addProtection(p *protection) {
+	r.protections = append(r.protections, p)
+	if len(r.protections) > recovererMaxProtections {
+		r.protections = r.protections[1:]
+	}
+}
+
+// recover applies the protections until no more packets can be recovered. A protection
+// recovers a packet once all other packets it covers have been received.
+func (r *Recoverer) recover() (recovered [][]byte) {
+	for progress := true; progress; {
+		progress = false
+
+		remaining := r.protections[:0]
+		for _, p := range r.protections {
+			var missing []uint16
+			for _, seq := range p.sequenceNumbers {
+				if !r.has(seq) {
+					missing = append(missing, seq)
+				}
+			}
+
+			switch len(missing) {
+			case 0:
+				// Everything the protection covers is there
+			case 1:
+				fields := p.fields.clone()
+				for _, seq := range p.sequenceNumbers {
+					if seq != missing[0] {
+						fields.add(r.packets[seq%recovererBufferSize])
+					}
+				}
+
+				if packet, ok := fields.packet(p.ssrc, missing[0]); ok {
+					r.store(packet)
+					recovered = append(recovered, packet)
+					progress = true
+				}
+			default:
+				remaining = append(remaining, p)
+			}
+		}
+		r.protections = remaining
+	}
+
+	return recovered
+}
@@ -0,0 +1,96 @@
+package fec
+
+import (
+	"encoding/binary"
+)
+
+const (
+	// REDMaxBlockLength is the largest redundant block a RED header can describe
+	REDMaxBlockLength = 0x3ff
+	// REDMaxTimestampOffset is the largest timestamp offset a RED header can describe
+	REDMaxTimestampOffset = 0x3fff
+)
+
+// REDBlock is an encoding carried in a RED (RFC 2198) payload
+type REDBlock struct {
+	PayloadType uint8
+	// TimestampOffset is how much earlier than the timestamp of the packet the block is
+	TimestampOffset uint16
+	Data            []byte
+}
+
+// MarshalRED returns the RED payload that carries blocks. The last block is the primary
+// encoding, its TimestampOffset is ignored.
+func MarshalRED(blocks []REDBlock) ([]byte, error) {
+	if len(blocks) == 0 {
+		return nil, errGroupSize
+	}
+
+	size := 1
+	for _, block := range blocks[:len(blocks)-1] {
+		switch {
+		case len(block.Data) > REDMaxBlockLength:
+			return nil, errBlockTooLong
+		case block.TimestampOffset > REDMaxTimestampOffset:
+			return nil, errTimestampOffset
+		}
+		size += 4 + len(block.Data)
+	}
+	size += len(blocks[len(blocks)-1].Data)
+
+	payload := make([]byte, 0, size)
+	for _, block := range blocks[:len(blocks)-1] {
+		header := uint32(0x80|block.PayloadType&0x7f)<<24 | uint32(block.TimestampOffset)<<10 | uint32(len(block.Data))
+		payload = append(payload, 0, 0, 0, 0)
+		binary.BigEndian.PutUint32(payload[len(payload)-4:], header)
+	}
+	payload = append(payload, blocks[len(blocks)-1].PayloadType&0x7f)
+
+	for _, block := range blocks {
+		payload = append(payload, block.Data...)
+	}
+
+	return payload, nil
+}
+
+// UnmarshalRED returns the blocks of a RED payload, the last one is the primary encoding.
+// The Data of the blocks references payload.
+func UnmarshalRED(payload []byte) ([]REDBlock, error) {
+	blocks := []REDBlock{}
+	offset := 0
+	for {
+		if offset >= len(payload) {
+			return nil, errPacketTooShort
+		}
+
+		// The F bit is unset on the header of the primary encoding, the last one
+		if payload[offset]&0x80 == 0 {
+			blocks = append(blocks, REDBlock{PayloadType: payload[offset] & 0x7f})
+			offset++
+			break
+		}
+
+		if offset+4 > len(payload) {
+			return nil, errPacketTooShort
+		}
+		header := binary.BigEndian.Uint32(payload[offset : offset+4])
+		blocks = append(blocks, REDBlock{
+			PayloadType:     uint8(header>>24) & 0x7f,
+			TimestampOffset: uint16(header>>10) & REDMaxTimestampOffset,
+			Data:            make([]byte, header&REDMaxBlockLength),
+		})
+		offset += 4
+	}
+
+	for i := range blocks[:len(blocks)-1] {
+		length := len(blocks[i].Data)
+		if offset+length > len(payload) {
+			return nil, errPacketTooShort
+		}
+		blocks[i].Data = payload[offset : offset+length]
+		offset += length
+	}
+	blocks[len(blocks)-1].Data = payload[offset:]
+
+	return blocks, nil
+}
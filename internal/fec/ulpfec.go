@@ -0,0 +1,82 @@
+package fec
+
+import (
+	"encoding/binary"
+)
+
+const (
+	ulpfecHeaderSize          = 10
+	ulpfecLevelHeaderSize     = 4
+	ulpfecLongLevelHeaderSize = 8
+)
+
+// EncodeULPFEC returns the payload of a ULPFEC packet with a single protection level that protects
+// packets. They must be marshaled RTP packets of a single SSRC, with sequence numbers less than
+// MaxGroupSize apart.
+func EncodeULPFEC(packets [][]byte) ([]byte, error) {
+	fields, base, mask, err := protect(packets)
+	if err != nil {
+		return nil, err
+	}
+
+	payload := make([]byte, ulpfecHeaderSize+ulpfecLevelHeaderSize+len(fields.payload))
+	// The E and L bits are zero, there is no extension and the mask is 16 bits
+	payload[0] = fields.first & 0x3f
+	payload[1] = fields.second
+	binary.BigEndian.PutUint16(payload[2:4], base)
+	binary.BigEndian.PutUint32(payload[4:8], fields.timestamp)
+	binary.BigEndian.PutUint16(payload[8:10], fields.length)
+	binary.BigEndian.PutUint16(payload[10:12], uint16(len(fields.payload)))
+	binary.BigEndian.PutUint16(payload[12:14], mask)
+	copy(payload[ulpfecHeaderSize+ulpfecLevelHeaderSize:], fields.payload)
+
+	return payload, nil
+}
+
+// parseULPFEC reads the first protection level of a ULPFEC payload, protecting packets of ssrc
+func parseULPFEC(ssrc uint32, payload []byte) (*protection, error) {
+	if len(payload) < ulpfecHeaderSize+ulpfecLevelHeaderSize {
+		return nil, errPacketTooShort
+	} else if payload[0]&0x80 != 0 {
+		return nil, errUnsupportedMask
+	}
+
+	p := &protection{
+		fields: recoveryFields{
+			first:     payload[0] & 0x3f,
+			second:    payload[1],
+			timestamp: binary.BigEndian.Uint32(payload[4:8]),
+			length:    binary.BigEndian.Uint16(payload[8:10]),
+		},
+		ssrc: ssrc,
+	}
+	base := binary.BigEndian.Uint16(payload[2:4])
+	protectionLength := int(binary.BigEndian.Uint16(payload[10:12]))
+
+	// The L bit selects the 48 bit mask
+	var mask uint64
+	maskBits := uint16(16)
+	headerSize := ulpfecHeaderSize + ulpfecLevelHeaderSize
+	if payload[0]&0x40 != 0 {
+		if len(payload) < ulpfecHeaderSize+ulpfecLongLevelHeaderSize {
+			return nil, errPacketTooShort
+		}
+		mask = uint64(binary.BigEndian.Uint16(payload[12:14]))<<32 | uint64(binary.BigEndian.Uint32(payload[14:18]))
+		maskBits = 48
+		headerSize = ulpfecHeaderSize + ulpfecLongLevelHeaderSize
+	} else {
+		mask = uint64(binary.BigEndian.Uint16(payload[12:14]))
+	}
+
+	for i := uint16(0); i < maskBits; i++ {
+		if mask&(1<<(maskBits-1-i)) != 0 {
+			p.sequenceNumbers = append(p.sequenceNumbers, base+i)
+		}
+	}
+
+	if protectionLength > len(payload)-headerSize {
+		return nil, errPacketTooShort
+	}
+	p.fields.payload = append([]byte{}, payload[headerSize:headerSize+protectionLength]...)
+	return p, nil
+}
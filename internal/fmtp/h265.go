@@ -0,0 +1,56 @@
+package fmtp
+
+import (
+	"strings"
+)
+
+type h265FMTP struct {
+	parameters map[string]string
+}
+
+func (h *h265FMTP) MimeType() string {
+	return "video/h265"
+}
+
+// parameterOrDefault returns the value of key, or the default value of
+// RFC7798 Section 7.1 if it isn't present
+func (h *h265FMTP) parameterOrDefault(key, defaultValue string) string {
+	if v, ok := h.parameters[key]; ok {
+		return v
+	}
+	return defaultValue
+}
+
+// Match returns true if h and b are compatible fmtp descriptions
+// Based on RFC7798 Section 7.2.2:
+//   The media format configuration parameters are profile-space,
+//   tier-flag, profile-id, profile-compatibility-indicator,
+//   interop-constraint-indicator, and level-id. These parameters MUST be
+//   used symmetrically, except that the level may be downgraded: the
+//   highest level indicated by the answer is either equal to or lower
+//   than that in the offer.
+//   The value of tx-mode MUST be the same in the offer and the answer.
+func (h *h265FMTP) Match(b FMTP) bool {
+	c, ok := b.(*h265FMTP)
+	if !ok {
+		return false
+	}
+
+	for _, p := range []struct{ key, defaultValue string }{
+		{"profile-space", "0"},
+		{"profile-id", "1"},
+		{"tier-flag", "0"},
+		{"tx-mode", "SRST"},
+	} {
+		if !strings.EqualFold(h.parameterOrDefault(p.key, p.defaultValue), c.parameterOrDefault(p.key, p.defaultValue)) {
+			return false
+		}
+	}
+
+	return true
+}
+
+func (h *h265FMTP) Parameter(key string) (string, bool) {
+	v, ok := h.parameters[key]
+	return v, ok
+}
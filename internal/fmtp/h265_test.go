@@ -0,0 +1,127 @@
+package fmtp
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestH265FMTPParse(t *testing.T) {
+	testCases := map[string]struct {
+		input    string
+		expected FMTP
+	}{
+		"OneParam": {
+			input: "level-id=93",
+			expected: &h265FMTP{
+				parameters: map[string]string{
+					"level-id": "93",
+				},
+			},
+		},
+		"FourParamsWithWhiteSpeces": {
+			input: "level-id=93; profile-id=1;\ttier-flag=0;tx-mode=SRST ",
+			expected: &h265FMTP{
+				parameters: map[string]string{
+					"level-id":   "93",
+					"profile-id": "1",
+					"tier-flag":  "0",
+					"tx-mode":    "SRST",
+				},
+			},
+		},
+	}
+	for name, testCase := range testCases {
+		testCase := testCase
+		t.Run(name, func(t *testing.T) {
+			f := Parse("video/H265", testCase.input)
+			if !reflect.DeepEqual(testCase.expected, f) {
+				t.Errorf("Expected Fmtp params: %v, got: %v", testCase.expected, f)
+			}
+
+			if f.MimeType() != "video/h265" {
+				t.Errorf("Expected MimeType of video/h265, got: %s", f.MimeType())
+			}
+		})
+	}
+}
+
+func TestH265FMTPCompare(t *testing.T) {
+	consistString := map[bool]string{true: "consist", false: "inconsist"}
+
+	testCases := map[string]struct {
+		a, b    string
+		consist bool
+	}{
+		"Equal": {
+			a:       "level-id=93;profile-id=1;tier-flag=0;tx-mode=SRST",
+			b:       "level-id=93;profile-id=1;tier-flag=0;tx-mode=SRST",
+			consist: true,
+		},
+		"EqualWithCase": {
+			a:       "level-id=93;profile-id=1;tier-flag=0;tx-mode=SRST",
+			b:       "LEVEL-ID=93;profile-id=1;TIER-FLAG=0;tx-mode=srst",
+			consist: true,
+		},
+		"DifferentLevels": {
+			a:       "level-id=93;profile-id=1;tier-flag=0;tx-mode=SRST",
+			b:       "level-id=180;profile-id=1;tier-flag=0;tx-mode=SRST",
+			consist: true,
+		},
+		"DefaultValues": {
+			a:       "level-id=93;profile-id=1;tier-flag=0;tx-mode=SRST",
+			b:       "",
+			consist: true,
+		},
+		"Inconsistent_ProfileID": {
+			a:       "level-id=93;profile-id=1;tier-flag=0;tx-mode=SRST",
+			b:       "level-id=93;profile-id=2;tier-flag=0;tx-mode=SRST",
+			consist: false,
+		},
+		"Inconsistent_DefaultProfileID": {
+			a:       "profile-id=2",
+			b:       "level-id=93",
+			consist: false,
+		},
+		"Inconsistent_TierFlag": {
+			a:       "level-id=93;profile-id=1;tier-flag=1",
+			b:       "level-id=93;profile-id=1;tier-flag=0",
+			consist: false,
+		},
+		"Inconsistent_TxMode": {
+			a:       "profile-id=1;tx-mode=MRST",
+			b:       "profile-id=1",
+			consist: false,
+		},
+		"Inconsistent_ProfileSpace": {
+			a:       "profile-space=1;profile-id=1",
+			b:       "profile-id=1",
+			consist: false,
+		},
+	}
+	for name, testCase := range testCases {
+		testCase := testCase
+		check := func(t *testing.T, a, b string) {
+			aa := Parse("video/h265", a)
+			bb := Parse("video/h265", b)
+			c := aa.Match(bb)
+			if c != testCase.consist {
+				t.Errorf(
+					"'%s' and '%s' are expected to be %s, but treated as %s",
+					a, b, consistString[testCase.consist], consistString[c],
+				)
+			}
+
+			// test reverse case here
+			c = bb.Match(aa)
+			if c != testCase.consist {
+				t.Errorf(
+					"'%s' and '%s' are expected to be %s, but treated as %s",
+					a, b, consistString[testCase.consist], consistString[c],
+				)
+			}
+		}
+		t.Run(name, func(t *testing.T) {
+			check(t, testCase.a, testCase.b)
+		})
+	}
+}
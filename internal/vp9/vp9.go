@@ -0,0 +1,32 @@
+// Package vp9 provides VP9 helpers shared by the media writers
+package vp9
+
+import "bytes"
+
+// Superframe joins the frames of a picture in a superframe, with the superframe
+// index of the VP9 Bitstream Specification Annex B
+func Superframe(frames [][]byte) []byte {
+	if len(frames) == 1 {
+		return frames[0]
+	}
+
+	maxFrameSize := 0
+	for _, frame := range frames {
+		if len(frame) > maxFrameSize {
+			maxFrameSize = len(frame)
+		}
+	}
+	bytesPerFrameSize := 1
+	for bytesPerFrameSize < 4 && maxFrameSize >= 1<<(8*bytesPerFrameSize) {
+		bytesPerFrameSize++
+	}
+
+	marker := byte(0xC0 | (bytesPerFrameSize-1)<<3 | (len(frames) - 1))
+	superframe := append(bytes.Join(frames, nil), marker)
+	for _, frame := range frames {
+		for j := 0; j < bytesPerFrameSize; j++ {
+			superframe = append(superframe, byte(len(frame)>>(8*j)))
+		}
+	}
+	return append(superframe, marker)
+}
@@ -0,0 +1,15 @@
+package vp9
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSuperframe(t *testing.T) {
+	assert.Equal(t, []byte{0x01}, Superframe([][]byte{{0x01}}))
+
+	large := make([]byte, 300)
+	superframe := Superframe([][]byte{{0x01}, large, {0x02}})
+	assert.Equal(t, []byte{0xCA, 0x01, 0x00, 0x2C, 0x01, 0x01, 0x00, 0xCA}, superframe[302:])
+}
@@ -14,6 +14,7 @@ import (
 	"github.com/pion/rtp/codecs"
 	"github.com/pion/sdp/v3"
 	"github.com/pion/webrtc/v3/internal/fmtp"
+	"github.com/pion/webrtc/v3/pkg/media/h265packet"
 )
 
 const (
@@ -44,6 +45,24 @@ const (
 	// MimeTypePCMA PCMA MIME type
 	// Note: Matching should be case insensitive.
 	MimeTypePCMA = "audio/PCMA"
+	// MimeTypeTelephoneEvent telephone-event (RFC 4733) MIME type, used for DTMF
+	// Note: Matching should be case insensitive.
+	MimeTypeTelephoneEvent = "audio/telephone-event"
+	// MimeTypeRTX RTX (RFC 4588) MIME type
+	// Note: Matching should be case insensitive.
+	MimeTypeRTX = "video/rtx"
+	// MimeTypeFlexFEC03 FlexFEC (draft-ietf-payload-flexible-fec-scheme-03) MIME type
+	// Note: Matching should be case insensitive.
+	MimeTypeFlexFEC03 = "video/flexfec-03"
+	// MimeTypeRED RED (RFC 2198) MIME type for video, carries ULPFEC
+	// Note: Matching should be case insensitive.
+	MimeTypeRED = "video/red"
+	// MimeTypeULPFEC ULPFEC (RFC 5109) MIME type
+	// Note: Matching should be case insensitive.
+	MimeTypeULPFEC = "video/ulpfec"
+	// MimeTypeAudioRED RED (RFC 2198) MIME type for audio, carries redundant Opus
+	// Note: Matching should be case insensitive.
+	MimeTypeAudioRED = "audio/red"
 )
 
 type mediaEngineHeaderExtension struct {
@@ -79,6 +98,10 @@ func (m *MediaEngine) RegisterDefaultCodecs() error {
 			RTPCodecCapability: RTPCodecCapability{MimeTypeOpus, 48000, 2, "minptime=10;useinbandfec=1", nil},
 			PayloadType:        111,
 		},
+		{
+			RTPCodecCapability: RTPCodecCapability{MimeTypeAudioRED, 48000, 2, "111/111", nil},
+			PayloadType:        63,
+		},
 		{
 			RTPCodecCapability: RTPCodecCapability{MimeTypeG722, 8000, 0, "", nil},
 			PayloadType:        9,
@@ -104,7 +127,7 @@ func (m *MediaEngine) RegisterDefaultCodecs() error {
 			PayloadType:        96,
 		},
 		{
-			RTPCodecCapability: RTPCodecCapability{"video/rtx", 90000, 0, "apt=96", nil},
+			RTPCodecCapability: RTPCodecCapability{MimeTypeRTX, 90000, 0, "apt=96", nil},
 			PayloadType:        97,
 		},
 
@@ -113,7 +136,7 @@ func (m *MediaEngine) RegisterDefaultCodecs() error {
 			PayloadType:        98,
 		},
 		{
-			RTPCodecCapability: RTPCodecCapability{"video/rtx", 90000, 0, "apt=98", nil},
+			RTPCodecCapability: RTPCodecCapability{MimeTypeRTX, 90000, 0, "apt=98", nil},
 			PayloadType:        99,
 		},
 
@@ -122,7 +145,7 @@ func (m *MediaEngine) RegisterDefaultCodecs() error {
 			PayloadType:        100,
 		},
 		{
-			RTPCodecCapability: RTPCodecCapability{"video/rtx", 90000, 0, "apt=100", nil},
+			RTPCodecCapability: RTPCodecCapability{MimeTypeRTX, 90000, 0, "apt=100", nil},
 			PayloadType:        101,
 		},
 
@@ -131,7 +154,7 @@ func (m *MediaEngine) RegisterDefaultCodecs() error {
 			PayloadType:        102,
 		},
 		{
-			RTPCodecCapability: RTPCodecCapability{"video/rtx", 90000, 0, "apt=102", nil},
+			RTPCodecCapability: RTPCodecCapability{MimeTypeRTX, 90000, 0, "apt=102", nil},
 			PayloadType:        121,
 		},
 
@@ -140,7 +163,7 @@ func (m *MediaEngine) RegisterDefaultCodecs() error {
 			PayloadType:        127,
 		},
 		{
-			RTPCodecCapability: RTPCodecCapability{"video/rtx", 90000, 0, "apt=127", nil},
+			RTPCodecCapability: RTPCodecCapability{MimeTypeRTX, 90000, 0, "apt=127", nil},
 			PayloadType:        120,
 		},
 
@@ -149,7 +172,7 @@ func (m *MediaEngine) RegisterDefaultCodecs() error {
 			PayloadType:        125,
 		},
 		{
-			RTPCodecCapability: RTPCodecCapability{"video/rtx", 90000, 0, "apt=125", nil},
+			RTPCodecCapability: RTPCodecCapability{MimeTypeRTX, 90000, 0, "apt=125", nil},
 			PayloadType:        107,
 		},
 
@@ -158,7 +181,7 @@ func (m *MediaEngine) RegisterDefaultCodecs() error {
 			PayloadType:        108,
 		},
 		{
-			RTPCodecCapability: RTPCodecCapability{"video/rtx", 90000, 0, "apt=108", nil},
+			RTPCodecCapability: RTPCodecCapability{MimeTypeRTX, 90000, 0, "apt=108", nil},
 			PayloadType:        109,
 		},
 
@@ -167,7 +190,7 @@ func (m *MediaEngine) RegisterDefaultCodecs() error {
 			PayloadType:        127,
 		},
 		{
-			RTPCodecCapability: RTPCodecCapability{"video/rtx", 90000, 0, "apt=127", nil},
+			RTPCodecCapability: RTPCodecCapability{MimeTypeRTX, 90000, 0, "apt=127", nil},
 			PayloadType:        120,
 		},
 
@@ -176,14 +199,35 @@ func (m *MediaEngine) RegisterDefaultCodecs() error {
 			PayloadType:        123,
 		},
 		{
-			RTPCodecCapability: RTPCodecCapability{"video/rtx", 90000, 0, "apt=123", nil},
+			RTPCodecCapability: RTPCodecCapability{MimeTypeRTX, 90000, 0, "apt=123", nil},
 			PayloadType:        118,
 		},
 
 		{
-			RTPCodecCapability: RTPCodecCapability{"video/ulpfec", 90000, 0, "", nil},
+			RTPCodecCapability: RTPCodecCapability{MimeTypeH265, 90000, 0, "level-id=93;profile-id=1;tier-flag=0;tx-mode=SRST", videoRTCPFeedback},
+			PayloadType:        104,
+		},
+		{
+			RTPCodecCapability: RTPCodecCapability{MimeTypeRTX, 90000, 0, "apt=104", nil},
+			PayloadType:        105,
+		},
+
+		{
+			RTPCodecCapability: RTPCodecCapability{MimeTypeRED, 90000, 0, "", nil},
+			PayloadType:        117,
+		},
+		{
+			RTPCodecCapability: RTPCodecCapability{MimeTypeRTX, 90000, 0, "apt=117", nil},
+			PayloadType:        106,
+		},
+		{
+			RTPCodecCapability: RTPCodecCapability{MimeTypeULPFEC, 90000, 0, "", nil},
 			PayloadType:        116,
 		},
+		{
+			RTPCodecCapability: RTPCodecCapability{MimeTypeFlexFEC03, 90000, 0, "repair-window=10000000", nil},
+			PayloadType:        119,
+		},
 	} {
 		if err := m.RegisterCodec(codec, RTPCodecTypeVideo); err != nil {
 			return err
@@ -203,6 +247,28 @@ func (m *MediaEngine) addCodec(codecs []RTPCodecParameters, codec RTPCodecParame
 	return append(codecs, codec)
 }
 
+// RegisterTelephoneEventCodecs registers the telephone-event (RFC 4733) codecs used by DTMF,
+// for the clock rates of the default audio codecs. They are not part of RegisterDefaultCodecs.
+// RegisterTelephoneEventCodecs is not safe for concurrent use.
+func (m *MediaEngine) RegisterTelephoneEventCodecs() error {
+	for _, codec := range []RTPCodecParameters{
+		{
+			RTPCodecCapability: RTPCodecCapability{MimeTypeTelephoneEvent, 48000, 0, "0-15", nil},
+			PayloadType:        110,
+		},
+		{
+			RTPCodecCapability: RTPCodecCapability{MimeTypeTelephoneEvent, 8000, 0, "0-15", nil},
+			PayloadType:        126,
+		},
+	} {
+		if err := m.RegisterCodec(codec, RTPCodecTypeAudio); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
 // RegisterCodec adds codec to the MediaEngine
 // These are the list of codecs supported by this PeerConnection.
 // RegisterCodec is not safe for concurrent use.
@@ -329,6 +395,77 @@ func findCodecByPayload(codecs []RTPCodecParameters, payloadType PayloadType) *R
 	return nil
 }
 
+// Given a PayloadType find the PayloadType of the RTX codec that repairs it
+// Returns 0 if no RTX codec is associated with it
+func findRTXPayloadType(needle PayloadType, haystack []RTPCodecParameters) PayloadType {
+	aptStr := fmt.Sprintf("apt=%d", needle)
+	for _, c := range haystack {
+		if strings.EqualFold(c.MimeType, MimeTypeRTX) && aptStr == c.SDPFmtpLine {
+			return c.PayloadType
+		}
+	}
+
+	return PayloadType(0)
+}
+
+// Tells if the list of codecs contains a RTX codec
+func codecsHaveRTX(codecs []RTPCodecParameters) bool {
+	for _, c := range codecs {
+		if strings.EqualFold(c.MimeType, MimeTypeRTX) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// Given a MIME type find the codec used for FEC
+// Returns nil if there is none
+func findFECCodec(mimeType string, haystack []RTPCodecParameters) *RTPCodecParameters {
+	for _, c := range haystack {
+		if strings.EqualFold(c.MimeType, mimeType) {
+			return &c
+		}
+	}
+
+	return nil
+}
+
+// Given a clock rate find the telephone-event codec that can be used with an audio codec of that rate
+// Returns nil if there is none
+func findTelephoneEventCodec(clockRate uint32, haystack []RTPCodecParameters) *RTPCodecParameters {
+	for _, c := range haystack {
+		if strings.EqualFold(c.MimeType, MimeTypeTelephoneEvent) && c.ClockRate == clockRate {
+			return &c
+		}
+	}
+
+	return nil
+}
+
+// Given a PayloadType find the audio/red codec that carries it
+// Returns nil if there is none
+func findAudioREDCodec(needle PayloadType, haystack []RTPCodecParameters) *RTPCodecParameters {
+	for _, c := range haystack {
+		if !strings.EqualFold(c.MimeType, MimeTypeAudioRED) {
+			continue
+		}
+
+		// The fmtp line lists the payload type of every block, e.g. "111/111"
+		carries := c.SDPFmtpLine != ""
+		for _, pt := range strings.Split(c.SDPFmtpLine, "/") {
+			if payloadType, err := strconv.ParseUint(strings.TrimSpace(pt), 10, 8); err != nil || PayloadType(payloadType) != needle {
+				carries = false
+			}
+		}
+		if carries {
+			return &c
+		}
+	}
+
+	return nil
+}
+
 func (m *MediaEngine) getCodecByPayload(payloadType PayloadType) (RTPCodecParameters, RTPCodecType, error) {
 	m.mu.RLock()
 	defer m.mu.RUnlock()
@@ -631,6 +768,8 @@ func payloaderForCodec(codec RTPCodecCapability) (rtp.Payloader, error) {
 	switch strings.ToLower(codec.MimeType) {
 	case strings.ToLower(MimeTypeH264):
 		return &codecs.H264Payloader{}, nil
+	case strings.ToLower(MimeTypeH265):
+		return &h265packet.Payloader{}, nil
 	case strings.ToLower(MimeTypeOpus):
 		return &codecs.OpusPayloader{}, nil
 	case strings.ToLower(MimeTypeVP8):
@@ -365,6 +365,31 @@ a=fmtp:97 apt=96
 		_, _, err := m.getCodecByPayload(97)
 		assert.ErrorIs(t, err, ErrCodecNotFound)
 	})
+
+	t.Run("Matches H265 with a different level", func(t *testing.T) {
+		const profileLevels = `v=0
+o=- 4596489990601351948 2 IN IP4 127.0.0.1
+s=-
+t=0 0
+m=video 60323 UDP/TLS/RTP/SAVPF 49 51
+a=rtpmap:49 H265/90000
+a=fmtp:49 level-id=180;profile-id=1;tier-flag=0;tx-mode=SRST
+a=rtpmap:51 H265/90000
+a=fmtp:51 level-id=180;profile-id=2;tier-flag=0;tx-mode=SRST
+`
+		m := MediaEngine{}
+		assert.NoError(t, m.RegisterDefaultCodecs())
+		assert.NoError(t, m.updateFromRemoteDescription(mustParse(profileLevels)))
+
+		assert.True(t, m.negotiatedVideo)
+
+		supportedH265, _, err := m.getCodecByPayload(49)
+		assert.NoError(t, err)
+		assert.Equal(t, supportedH265.MimeType, MimeTypeH265)
+
+		_, _, err = m.getCodecByPayload(51)
+		assert.ErrorIs(t, err, ErrCodecNotFound)
+	})
 }
 
 func TestMediaEngineHeaderExtensionDirection(t *testing.T) {
@@ -0,0 +1,359 @@
+//go:build !js
+// +build !js
+
+package webrtc
+
+import (
+	"sync"
+
+	"github.com/pion/rtcp"
+	"github.com/pion/sdp/v3"
+	"github.com/pion/webrtc/v3/internal/util"
+)
+
+// mediaSectionTransport is the ICE and DTLS transport of a media section that isn't
+// carried by the transport of the first media section. They are only used with
+// BundlePolicyMaxCompat: every media section is offered with its own transport, and
+// if the remote doesn't accept BUNDLE each media section is sent over it.
+type mediaSectionTransport struct {
+	iceGatherer   *ICEGatherer
+	iceTransport  *ICETransport
+	dtlsTransport *DTLSTransport
+
+	started bool
+}
+
+// getMediaSectionTransports returns a copy of the media section transports by mid
+func (pc *PeerConnection) getMediaSectionTransports() map[string]*mediaSectionTransport {
+	pc.mediaSectionTransportsMu.RLock()
+	defer pc.mediaSectionTransportsMu.RUnlock()
+
+	transports := make(map[string]*mediaSectionTransport, len(pc.mediaSectionTransports))
+	for mid, transport := range pc.mediaSectionTransports {
+		transports[mid] = transport
+	}
+	return transports
+}
+
+func (pc *PeerConnection) hasMediaSectionTransports() bool {
+	pc.mediaSectionTransportsMu.RLock()
+	defer pc.mediaSectionTransportsMu.RUnlock()
+
+	return len(pc.mediaSectionTransports) != 0
+}
+
+// mediaSectionTransportForMid returns the transport of the media section, it is created if needed
+func (pc *PeerConnection) mediaSectionTransportForMid(mid string, mLineIndex int) (*mediaSectionTransport, error) {
+	pc.mediaSectionTransportsMu.Lock()
+	defer pc.mediaSectionTransportsMu.Unlock()
+
+	if transport, ok := pc.mediaSectionTransports[mid]; ok {
+		return transport, nil
+	}
+
+	// Take an ICEGatherer from the ICE candidate pool if one is left
+	var gatherer *ICEGatherer
+	if len(pc.iceCandidatePool) > 0 {
+		gatherer, pc.iceCandidatePool = pc.iceCandidatePool[0], pc.iceCandidatePool[1:]
+	} else {
+		var err error
+		if gatherer, err = pc.createICEGatherer(); err != nil {
+			return nil, err
+		}
+	}
+	gatherer.sdpMid = mid
+	gatherer.sdpMLineIndex = uint16(mLineIndex)
+
+	// Candidates and the end of gathering are reported to the handlers of the first transport
+	if handler, ok := pc.iceGatherer.onLocalCandidateHandler.Load().(func(*ICECandidate)); ok && handler != nil {
+		gatherer.OnLocalCandidate(handler)
+	}
+	if handler, ok := pc.iceGatherer.onGatheringCompleteHandler.Load().(func()); ok && handler != nil {
+		gatherer.onGatheringCompleteHandler.Store(handler)
+	}
+
+	iceTransport := pc.createICETransport(gatherer)
+	iceTransport.statsID = "iceTransport-" + mid
+	dtlsTransport, err := pc.api.NewDTLSTransport(iceTransport, pc.configuration.Certificates)
+	if err != nil {
+		return nil, err
+	}
+
+	transport := &mediaSectionTransport{
+		iceGatherer:   gatherer,
+		iceTransport:  iceTransport,
+		dtlsTransport: dtlsTransport,
+	}
+	if pc.mediaSectionTransports == nil {
+		pc.mediaSectionTransports = map[string]*mediaSectionTransport{}
+	}
+	pc.mediaSectionTransports[mid] = transport
+
+	return transport, nil
+}
+
+// addMediaSectionTransports gives every media section but the first one its own transport.
+// Unbundled media sections are left out of the BUNDLE group.
+func (pc *PeerConnection) addMediaSectionTransports(mediaSections []mediaSection, unbundled bool) error {
+	for i := range mediaSections {
+		mediaSections[i].unbundled = unbundled
+		if i == 0 {
+			continue
+		}
+
+		transport, err := pc.mediaSectionTransportForMid(mediaSections[i].id, i)
+		if err != nil {
+			return err
+		}
+
+		iceParams, err := transport.iceGatherer.GetLocalParameters()
+		if err != nil {
+			return err
+		}
+
+		candidates, err := transport.iceGatherer.GetLocalCandidates()
+		if err != nil {
+			return err
+		}
+
+		mediaSections[i].iceParams = &iceParams
+		mediaSections[i].candidates = candidates
+	}
+
+	return nil
+}
+
+// stopMediaSectionTransports closes the transports of the media sections. This happens
+// when the remote accepted BUNDLE, all media is then sent over the first transport.
+func (pc *PeerConnection) stopMediaSectionTransports() error {
+	pc.mediaSectionTransportsMu.Lock()
+	transports := pc.mediaSectionTransports
+	pc.mediaSectionTransports = nil
+	pc.mediaSectionTransportsMu.Unlock()
+
+	closeErrs := []error{}
+	for _, transport := range transports {
+		closeErrs = append(closeErrs, transport.dtlsTransport.Stop(), transport.iceTransport.Stop())
+	}
+
+	return util.FlattenErrs(closeErrs)
+}
+
+// startMediaSectionTransports starts the media section transports that are negotiated in
+// the remote description and haven't been started yet. Like startTransports it blocks
+// until they are connected.
+func (pc *PeerConnection) startMediaSectionTransports(iceRole ICERole, remoteDesc *SessionDescription) {
+	toStart := map[*mediaSectionTransport]*sdp.MediaDescription{}
+	pc.mediaSectionTransportsMu.Lock()
+	for mid, transport := range pc.mediaSectionTransports {
+		if media := getByMid(mid, remoteDesc); media != nil && media.MediaName.Port.Value != 0 && !transport.started {
+			transport.started = true
+			toStart[transport] = media
+		}
+	}
+	pc.mediaSectionTransportsMu.Unlock()
+
+	if len(toStart) == 0 {
+		return
+	}
+
+	var wg sync.WaitGroup
+	for transport, media := range toStart {
+		wg.Add(1)
+		go func(transport *mediaSectionTransport, media *sdp.MediaDescription) {
+			defer wg.Done()
+
+			if err := pc.startMediaSectionTransport(transport, iceRole, descriptionForMedia(remoteDesc.parsed, media)); err != nil {
+				pc.log.Warnf("Failed to start transport of media section %s: %s", getMidValue(media), err)
+			}
+		}(transport, media)
+	}
+	wg.Wait()
+
+	pc.updateConnectionState(pc.ICEConnectionState(), pc.aggregateDTLSTransportState())
+}
+
+func (pc *PeerConnection) startMediaSectionTransport(transport *mediaSectionTransport, iceRole ICERole, remoteDesc *sdp.SessionDescription) error {
+	remoteUfrag, remotePwd, candidates, err := extractICEDetails(remoteDesc, pc.log)
+	if err != nil {
+		return err
+	}
+
+	fingerprints, err := extractFingerprints(remoteDesc)
+	if err != nil {
+		return err
+	}
+
+	for i := range candidates {
+		if err = transport.iceTransport.AddRemoteCandidate(&candidates[i]); err != nil {
+			return err
+		}
+	}
+
+	if err = transport.iceTransport.Start(
+		transport.iceGatherer,
+		ICEParameters{
+			UsernameFragment: remoteUfrag,
+			Password:         remotePwd,
+			ICELite:          false,
+		},
+		&iceRole,
+	); err != nil {
+		return err
+	}
+
+	if err = transport.dtlsTransport.Start(DTLSParameters{
+		Role:         dtlsRoleFromRemoteSDP(remoteDesc),
+		Fingerprints: fingerprints,
+	}); err != nil {
+		return err
+	}
+
+	pc.undeclaredMediaProcessor(transport.dtlsTransport)
+	return nil
+}
+
+// dtlsTransportForMid returns the DTLSTransport the media of a media section is sent over
+func (pc *PeerConnection) dtlsTransportForMid(mid string) *DTLSTransport {
+	pc.mediaSectionTransportsMu.RLock()
+	defer pc.mediaSectionTransportsMu.RUnlock()
+
+	if transport, ok := pc.mediaSectionTransports[mid]; ok {
+		return transport.dtlsTransport
+	}
+	return pc.dtlsTransport
+}
+
+// dtlsTransportForSSRC returns the DTLSTransport a local or remote stream is sent over
+func (pc *PeerConnection) dtlsTransportForSSRC(ssrc SSRC) *DTLSTransport {
+	for _, t := range pc.GetTransceivers() {
+		if sender := t.Sender(); sender != nil {
+			for _, encoding := range sender.GetParameters().Encodings {
+				if encoding.SSRC == ssrc || (encoding.RTX.SSRC != 0 && encoding.RTX.SSRC == ssrc) {
+					return sender.Transport()
+				}
+			}
+		}
+
+		if receiver := t.Receiver(); receiver != nil {
+			for _, track := range receiver.Tracks() {
+				if track.SSRC() == ssrc {
+					return receiver.Transport()
+				}
+			}
+		}
+	}
+
+	return pc.dtlsTransport
+}
+
+// bindTransceiverTransports moves the senders and receivers that haven't started yet
+// to the DTLSTransport of their media section
+func (pc *PeerConnection) bindTransceiverTransports(transceivers []*RTPTransceiver) {
+	for _, t := range transceivers {
+		transport := pc.dtlsTransportForMid(t.Mid())
+		if sender := t.Sender(); sender != nil && !sender.hasSent() {
+			sender.setTransport(transport)
+		}
+		if receiver := t.Receiver(); receiver != nil && !receiver.haveReceived() {
+			receiver.setTransport(transport)
+		}
+	}
+}
+
+// mediaSectionTransportForCandidate returns the transport of the media section a remote
+// candidate belongs to, or nil if it belongs to the first transport
+func (pc *PeerConnection) mediaSectionTransportForCandidate(candidate ICECandidateInit) *mediaSectionTransport {
+	transports := pc.getMediaSectionTransports()
+	if len(transports) == 0 {
+		return nil
+	}
+
+	if candidate.SDPMid != nil && *candidate.SDPMid != "" {
+		return transports[*candidate.SDPMid]
+	}
+
+	if remoteDesc := pc.RemoteDescription(); candidate.SDPMLineIndex != nil && remoteDesc != nil {
+		if index := int(*candidate.SDPMLineIndex); index < len(remoteDesc.parsed.MediaDescriptions) {
+			return transports[getMidValue(remoteDesc.parsed.MediaDescriptions[index])]
+		}
+	}
+
+	return nil
+}
+
+// writeRTCPToMediaSections sends every RTCP packet over the transport of the stream it is about
+func (pc *PeerConnection) writeRTCPToMediaSections(pkts []rtcp.Packet) (int, error) {
+	transports := []*DTLSTransport{}
+	packets := map[*DTLSTransport][]rtcp.Packet{}
+	for _, pkt := range pkts {
+		transport := pc.dtlsTransport
+		if destinationSSRCs := pkt.DestinationSSRC(); len(destinationSSRCs) != 0 {
+			transport = pc.dtlsTransportForSSRC(SSRC(destinationSSRCs[0]))
+		}
+
+		if _, ok := packets[transport]; !ok {
+			transports = append(transports, transport)
+		}
+		packets[transport] = append(packets[transport], pkt)
+	}
+
+	written := 0
+	for _, transport := range transports {
+		n, err := transport.WriteRTCP(packets[transport])
+		written += n
+		if err != nil {
+			return written, err
+		}
+	}
+
+	return written, nil
+}
+
+// aggregateICEConnectionState returns the ICEConnectionState that represents all ICE transports
+// https://www.w3.org/TR/webrtc/#rtciceconnectionstate-enum
+func (pc *PeerConnection) aggregateICEConnectionState() ICEConnectionState {
+	states := []ICEConnectionState{pc.iceTransport.State().toICEConnectionState()}
+	for _, transport := range pc.getMediaSectionTransports() {
+		states = append(states, transport.iceTransport.State().toICEConnectionState())
+	}
+
+	count := map[ICEConnectionState]int{}
+	for _, state := range states {
+		count[state]++
+	}
+
+	switch {
+	case count[ICEConnectionStateFailed] != 0:
+		return ICEConnectionStateFailed
+	case count[ICEConnectionStateDisconnected] != 0:
+		return ICEConnectionStateDisconnected
+	case count[ICEConnectionStateNew]+count[ICEConnectionStateClosed] == len(states):
+		return ICEConnectionStateNew
+	case count[ICEConnectionStateNew]+count[ICEConnectionStateChecking] != 0:
+		return ICEConnectionStateChecking
+	case count[ICEConnectionStateCompleted]+count[ICEConnectionStateClosed] == len(states):
+		return ICEConnectionStateCompleted
+	default:
+		return ICEConnectionStateConnected
+	}
+}
+
+// aggregateDTLSTransportState returns the least advanced state of the DTLS transports
+func (pc *PeerConnection) aggregateDTLSTransportState() DTLSTransportState {
+	rank := map[DTLSTransportState]int{
+		DTLSTransportStateFailed:     0,
+		DTLSTransportStateConnecting: 1,
+		DTLSTransportStateNew:        2,
+		DTLSTransportStateConnected:  3,
+		DTLSTransportStateClosed:     4,
+	}
+
+	state := pc.dtlsTransport.State()
+	for _, transport := range pc.getMediaSectionTransports() {
+		if s := transport.dtlsTransport.State(); rank[s] < rank[state] {
+			state = s
+		}
+	}
+	return state
+}
@@ -0,0 +1,143 @@
+//go:build !js
+// +build !js
+
+package webrtc
+
+import (
+	"sync"
+)
+
+// NegotiatorSignaler is used by a Negotiator to send its descriptions and
+// candidates to the remote peer. The remote peer passes what it receives to
+// HandleDescription and HandleCandidate of its own Negotiator.
+// The methods must not wait for the remote peer to handle what is sent.
+type NegotiatorSignaler interface {
+	SendDescription(description SessionDescription) error
+	SendCandidate(candidate ICECandidateInit) error
+}
+
+// Negotiator implements the perfect negotiation pattern on top of a PeerConnection.
+// https://w3c.github.io/webrtc-pc/#perfect-negotiation-example
+//
+// Both peers may start a negotiation whenever OnNegotiationNeeded fires. When their
+// offers collide the impolite peer ignores the offer of the remote peer, and the polite
+// peer rolls back its own offer and answers the remote one. Exactly one of the two
+// peers must be polite.
+type Negotiator struct {
+	pc       *PeerConnection
+	signaler NegotiatorSignaler
+	polite   bool
+
+	mu          sync.Mutex
+	ignoreOffer bool
+}
+
+// NewNegotiator returns a Negotiator for pc. It takes over the OnNegotiationNeeded
+// and OnICECandidate handlers of pc, they must not be set by the caller.
+func NewNegotiator(pc *PeerConnection, signaler NegotiatorSignaler, polite bool) *Negotiator {
+	n := &Negotiator{
+		pc:       pc,
+		signaler: signaler,
+		polite:   polite,
+	}
+
+	pc.OnNegotiationNeeded(n.onNegotiationNeeded)
+	pc.OnICECandidate(n.onICECandidate)
+	return n
+}
+
+func (n *Negotiator) onNegotiationNeeded() {
+	offer, err := func() (*SessionDescription, error) {
+		n.mu.Lock()
+		defer n.mu.Unlock()
+
+		// A remote offer got in first, negotiation is needed again once it is answered
+		if n.pc.SignalingState() != SignalingStateStable {
+			return nil, nil
+		}
+
+		offer, err := n.pc.CreateOffer(nil)
+		if err != nil {
+			return nil, err
+		}
+		if err = n.pc.SetLocalDescription(offer); err != nil {
+			return nil, err
+		}
+
+		return n.pc.LocalDescription(), nil
+	}()
+	if err == nil && offer != nil {
+		err = n.signaler.SendDescription(*offer)
+	}
+	if err != nil {
+		n.pc.log.Warnf("Negotiator failed to send offer: %v", err)
+	}
+}
+
+func (n *Negotiator) onICECandidate(candidate *ICECandidate) {
+	// The end of candidates is not signaled
+	if candidate == nil {
+		return
+	}
+
+	if err := n.signaler.SendCandidate(candidate.ToJSON()); err != nil {
+		n.pc.log.Warnf("Negotiator failed to send candidate: %v", err)
+	}
+}
+
+// HandleDescription applies a description received from the remote peer, and answers it
+// if it is an offer. An offer that collides with a local one is ignored by the impolite
+// peer, the polite peer rolls its own offer back.
+func (n *Negotiator) HandleDescription(description SessionDescription) error {
+	answer, err := func() (*SessionDescription, error) {
+		n.mu.Lock()
+		defer n.mu.Unlock()
+
+		offerCollision := description.Type == SDPTypeOffer && n.pc.SignalingState() != SignalingStateStable
+		n.ignoreOffer = !n.polite && offerCollision
+		if n.ignoreOffer {
+			return nil, nil
+		}
+
+		if offerCollision {
+			if err := n.pc.SetLocalDescription(SessionDescription{Type: SDPTypeRollback}); err != nil {
+				return nil, err
+			}
+		}
+
+		if err := n.pc.SetRemoteDescription(description); err != nil {
+			return nil, err
+		}
+		if description.Type != SDPTypeOffer {
+			return nil, nil
+		}
+
+		answer, err := n.pc.CreateAnswer(nil)
+		if err != nil {
+			return nil, err
+		}
+		if err = n.pc.SetLocalDescription(answer); err != nil {
+			return nil, err
+		}
+
+		return n.pc.LocalDescription(), nil
+	}()
+	if err != nil || answer == nil {
+		return err
+	}
+
+	return n.signaler.SendDescription(*answer)
+}
+
+// HandleCandidate adds a candidate received from the remote peer. The candidates of an
+// offer that was ignored are ignored as well.
+func (n *Negotiator) HandleCandidate(candidate ICECandidateInit) error {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+
+	if err := n.pc.AddICECandidate(candidate); err != nil && !n.ignoreOffer {
+		return err
+	}
+
+	return nil
+}
@@ -0,0 +1,121 @@
+//go:build !js
+// +build !js
+
+package webrtc
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/pion/transport/test"
+	"github.com/stretchr/testify/assert"
+)
+
+// testNegotiatorSignaler delivers to the Negotiator of the remote peer in order,
+// but only once both peers have sent their first offer so that they collide
+type testNegotiatorSignaler struct {
+	ctx      context.Context
+	offered  *sync.WaitGroup
+	once     sync.Once
+	messages chan interface{}
+}
+
+func (s *testNegotiatorSignaler) send(message interface{}) error {
+	select {
+	case s.messages <- message:
+	case <-s.ctx.Done():
+	}
+	return nil
+}
+
+func (s *testNegotiatorSignaler) SendDescription(description SessionDescription) error {
+	s.once.Do(s.offered.Done)
+	return s.send(description)
+}
+
+func (s *testNegotiatorSignaler) SendCandidate(candidate ICECandidateInit) error {
+	return s.send(candidate)
+}
+
+func (s *testNegotiatorSignaler) deliver(t *testing.T, remote *Negotiator) {
+	s.offered.Wait()
+	for {
+		select {
+		case <-s.ctx.Done():
+			return
+		case message := <-s.messages:
+			switch m := message.(type) {
+			case SessionDescription:
+				assert.NoError(t, remote.HandleDescription(m))
+			case ICECandidateInit:
+				assert.NoError(t, remote.HandleCandidate(m))
+			}
+		}
+	}
+}
+
+func TestNegotiator_Glare(t *testing.T) {
+	lim := test.TimeOut(time.Second * 30)
+	defer lim.Stop()
+
+	report := test.CheckRoutines(t)
+	defer report()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	offered := &sync.WaitGroup{}
+	offered.Add(2)
+
+	pcPolite, pcImpolite, err := newPair()
+	assert.NoError(t, err)
+
+	politeSignaler := &testNegotiatorSignaler{ctx: ctx, offered: offered, messages: make(chan interface{}, 64)}
+	impoliteSignaler := &testNegotiatorSignaler{ctx: ctx, offered: offered, messages: make(chan interface{}, 64)}
+	polite := NewNegotiator(pcPolite, politeSignaler, true)
+	impolite := NewNegotiator(pcImpolite, impoliteSignaler, false)
+
+	var delivered sync.WaitGroup
+	delivered.Add(2)
+	go func() {
+		defer delivered.Done()
+		politeSignaler.deliver(t, impolite)
+	}()
+	go func() {
+		defer delivered.Done()
+		impoliteSignaler.deliver(t, polite)
+	}()
+
+	// Both peers add a track at the same time, each one gets the track of the other
+	tracks := []*TrackLocalStaticSample{}
+	var onTrack sync.WaitGroup
+	onTrack.Add(2)
+	for _, pc := range []*PeerConnection{pcPolite, pcImpolite} {
+		once := &sync.Once{}
+		pc.OnTrack(func(*TrackRemote, *RTPReceiver) {
+			once.Do(onTrack.Done)
+		})
+
+		track, trackErr := NewTrackLocalStaticSample(RTPCodecCapability{MimeType: MimeTypeVP8}, "video", "pion")
+		assert.NoError(t, trackErr)
+		tracks = append(tracks, track)
+	}
+	_, err = pcPolite.AddTrack(tracks[0])
+	assert.NoError(t, err)
+	_, err = pcImpolite.AddTrack(tracks[1])
+	assert.NoError(t, err)
+
+	connected := untilConnectionState(PeerConnectionStateConnected, pcPolite, pcImpolite)
+	onTrackFired, onTrackFiredFunc := context.WithCancel(context.Background())
+	go func() {
+		onTrack.Wait()
+		onTrackFiredFunc()
+	}()
+
+	sendVideoUntilDone(onTrackFired.Done(), t, tracks)
+	connected.Wait()
+
+	cancel()
+	delivered.Wait()
+	closePairNow(t, pcPolite, pcImpolite)
+}
@@ -18,6 +18,7 @@ import (
 
 	"github.com/pion/ice/v2"
 	"github.com/pion/interceptor"
+	"github.com/pion/interceptor/pkg/cc"
 	"github.com/pion/logging"
 	"github.com/pion/rtcp"
 	"github.com/pion/sdp/v3"
@@ -67,23 +68,37 @@ type PeerConnection struct {
 
 	rtpTransceivers []*RTPTransceiver
 
+	// Transceivers created by the pending remote offer, they are removed if it is rolled back
+	remoteOfferTransceivers []*RTPTransceiver
+
 	onSignalingStateChangeHandler     func(SignalingState)
 	onICEConnectionStateChangeHandler atomic.Value // func(ICEConnectionState)
 	onConnectionStateChangeHandler    atomic.Value // func(PeerConnectionState)
 	onTrackHandler                    func(*TrackRemote, *RTPReceiver)
 	onDataChannelHandler              func(*DataChannel)
 	onNegotiationNeededHandler        atomic.Value // func()
+	onBandwidthEstimateHandler        atomic.Value // func(int)
 
 	iceGatherer   *ICEGatherer
 	iceTransport  *ICETransport
 	dtlsTransport *DTLSTransport
 	sctpTransport *SCTPTransport
 
+	// Transports of the media sections that are not carried by the transports above, by mid
+	mediaSectionTransportsMu sync.RWMutex
+	mediaSectionTransports   map[string]*mediaSectionTransport
+
+	// ICEGatherers that pre-gather candidates for media sections with their own transport,
+	// iceGatherer is the first one of the ICE candidate pool. Guarded by mediaSectionTransportsMu
+	iceCandidatePool []*ICEGatherer
+
 	// A reference to the associated API state used by this connection
 	api *API
 	log logging.LeveledLogger
 
 	interceptorRTCPWriter interceptor.RTCPWriter
+	statsInterceptor      *statsInterceptor
+	bandwidthEstimator    cc.BandwidthEstimator
 }
 
 // NewPeerConnection creates a PeerConnection with the default codecs and
@@ -142,9 +157,24 @@ func (api *API) NewPeerConnection(configuration Configuration) (*PeerConnection,
 		return nil, err
 	}
 
+	// The stats interceptor is bound first so it observes packets as they are on the wire
+	pc.statsInterceptor = newStatsInterceptor()
+	interceptors := []interceptor.Interceptor{pc.statsInterceptor}
+
+	if api.settingEngine.bandwidthEstimation.enabled {
+		var bandwidthEstimationInterceptors []interceptor.Interceptor
+		pc.bandwidthEstimator, bandwidthEstimationInterceptors, err = createBandwidthEstimator(api.settingEngine.bandwidthEstimation.factory)
+		if err != nil {
+			return nil, err
+		}
+		pc.bandwidthEstimator.OnTargetBitrateChange(pc.onBandwidthEstimate)
+		interceptors = append(interceptors, bandwidthEstimationInterceptors...)
+	}
+
 	pc.api = &API{
-		settingEngine: api.settingEngine,
-		interceptor:   i,
+		settingEngine:    api.settingEngine,
+		certificateStore: api.certificateStore,
+		interceptor:      interceptor.NewChain(append(interceptors, i)),
 	}
 
 	if api.settingEngine.disableMediaEngineCopy {
@@ -153,6 +183,14 @@ func (api *API) NewPeerConnection(configuration Configuration) (*PeerConnection,
 		pc.api.mediaEngine = api.mediaEngine.copy()
 	}
 
+	if pc.bandwidthEstimator != nil {
+		for _, typ := range []RTPCodecType{RTPCodecTypeVideo, RTPCodecTypeAudio} {
+			if err = pc.api.mediaEngine.RegisterHeaderExtension(RTPHeaderExtensionCapability{URI: sdp.TransportCCURI}, typ); err != nil {
+				return nil, err
+			}
+		}
+	}
+
 	if err = pc.initConfiguration(configuration); err != nil {
 		return nil, err
 	}
@@ -163,9 +201,13 @@ func (api *API) NewPeerConnection(configuration Configuration) (*PeerConnection,
 	}
 
 	// Create the ice transport
-	iceTransport := pc.createICETransport()
+	iceTransport := pc.createICETransport(pc.iceGatherer)
 	pc.iceTransport = iceTransport
 
+	if err = pc.updateICECandidatePool(); err != nil {
+		return nil, err
+	}
+
 	// Create the DTLS transport
 	dtlsTransport, err := pc.api.NewDTLSTransport(pc.iceTransport, pc.configuration.Certificates)
 	if err != nil {
@@ -210,6 +252,12 @@ func (pc *PeerConnection) initConfiguration(configuration Configuration) error {
 			}
 			pc.configuration.Certificates = append(pc.configuration.Certificates, x509Cert)
 		}
+	} else if pc.api.certificateStore != nil {
+		certificate, err := pc.api.certificateStore.Certificate()
+		if err != nil {
+			return err
+		}
+		pc.configuration.Certificates = []Certificate{certificate}
 	} else {
 		sk, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
 		if err != nil {
@@ -432,9 +480,12 @@ func (pc *PeerConnection) checkNegotiationNeeded() bool { //nolint:gocognit
 // ICE candidate gathering only begins when SetLocalDescription or
 // SetRemoteDescription is called.
 // Take note that the handler will be called with a nil pointer when
-// gathering is finished.
+// gathering is finished, once for every transport with BundlePolicyMaxCompat.
 func (pc *PeerConnection) OnICECandidate(f func(*ICECandidate)) {
 	pc.iceGatherer.OnLocalCandidate(f)
+	for _, transport := range pc.getMediaSectionTransports() {
+		transport.iceGatherer.OnLocalCandidate(f)
+	}
 }
 
 // OnICEGatheringStateChange sets an event handler which is invoked when the
@@ -494,6 +545,25 @@ func (pc *PeerConnection) onConnectionStateChange(cs PeerConnectionState) {
 	}
 }
 
+// OnBandwidthEstimate sets an event handler which is called when the bandwidth
+// estimator changes the target bitrate, in bits per second, of this PeerConnection.
+// See SettingEngine.EnableBandwidthEstimation
+func (pc *PeerConnection) OnBandwidthEstimate(f func(bitrate int)) {
+	pc.onBandwidthEstimateHandler.Store(f)
+}
+
+func (pc *PeerConnection) onBandwidthEstimate(bitrate int) {
+	if handler, ok := pc.onBandwidthEstimateHandler.Load().(func(int)); ok && handler != nil {
+		handler(bitrate)
+	}
+
+	for _, transceiver := range pc.GetTransceivers() {
+		if sender := transceiver.Sender(); sender != nil {
+			sender.setTargetBitrate(bitrate)
+		}
+	}
+}
+
 // SetConfiguration updates the configuration of this PeerConnection object.
 func (pc *PeerConnection) SetConfiguration(configuration Configuration) error { //nolint:gocognit
 	// https://www.w3.org/TR/webrtc/#dom-rtcpeerconnection-setconfiguration (step #2)
@@ -546,6 +616,12 @@ func (pc *PeerConnection) SetConfiguration(configuration Configuration) error {
 			return &rtcerr.InvalidModificationError{Err: ErrModifyingICECandidatePoolSize}
 		}
 		pc.configuration.ICECandidatePoolSize = configuration.ICECandidatePoolSize
+
+		if pc.LocalDescription() == nil {
+			if err := pc.updateICECandidatePool(); err != nil {
+				return err
+			}
+		}
 	}
 
 	// https://www.w3.org/TR/webrtc/#set-the-configuration (step #8)
@@ -726,6 +802,65 @@ func (pc *PeerConnection) createICEGatherer() (*ICEGatherer, error) {
 	return g, nil
 }
 
+// updateICECandidatePool starts gathering candidates ahead of SetLocalDescription
+// for Configuration.ICECandidatePoolSize ICEGatherers. The first one is the ICEGatherer
+// of the PeerConnection, the others are used by media sections that get their own transport.
+// Pooled ICEGatherers past the pool size are discarded.
+func (pc *PeerConnection) updateICECandidatePool() error {
+	poolSize := int(pc.configuration.ICECandidatePoolSize)
+
+	pc.mediaSectionTransportsMu.Lock()
+	defer pc.mediaSectionTransportsMu.Unlock()
+
+	if poolSize == 0 {
+		closeErrs := []error{pc.iceGatherer.discardPool()}
+		for _, g := range pc.iceCandidatePool {
+			closeErrs = append(closeErrs, g.Close())
+		}
+		pc.iceCandidatePool = nil
+		return util.FlattenErrs(closeErrs)
+	}
+
+	if err := pc.iceGatherer.gatherPool(); err != nil {
+		return err
+	}
+
+	if len(pc.iceCandidatePool) > poolSize-1 {
+		closeErrs := []error{}
+		for _, g := range pc.iceCandidatePool[poolSize-1:] {
+			closeErrs = append(closeErrs, g.Close())
+		}
+		pc.iceCandidatePool = pc.iceCandidatePool[:poolSize-1]
+		return util.FlattenErrs(closeErrs)
+	}
+
+	for len(pc.iceCandidatePool) < poolSize-1 {
+		g, err := pc.createICEGatherer()
+		if err != nil {
+			return err
+		}
+		if err = g.gatherPool(); err != nil {
+			return err
+		}
+		pc.iceCandidatePool = append(pc.iceCandidatePool, g)
+	}
+	return nil
+}
+
+// discardICECandidatePool closes the pooled ICEGatherers no media section has taken
+func (pc *PeerConnection) discardICECandidatePool() error {
+	pc.mediaSectionTransportsMu.Lock()
+	pool := pc.iceCandidatePool
+	pc.iceCandidatePool = nil
+	pc.mediaSectionTransportsMu.Unlock()
+
+	closeErrs := []error{}
+	for _, g := range pool {
+		closeErrs = append(closeErrs, g.Close())
+	}
+	return util.FlattenErrs(closeErrs)
+}
+
 // Update the PeerConnectionState given the state of relevant transports
 // https://www.w3.org/TR/webrtc/#rtcpeerconnectionstate-enum
 func (pc *PeerConnection) updateConnectionState(iceConnectionState ICEConnectionState, dtlsTransportState DTLSTransportState) {
@@ -762,31 +897,24 @@ func (pc *PeerConnection) updateConnectionState(iceConnectionState ICEConnection
 	pc.onConnectionStateChange(connectionState)
 }
 
-func (pc *PeerConnection) createICETransport() *ICETransport {
-	t := pc.api.NewICETransport(pc.iceGatherer)
+func (pc *PeerConnection) createICETransport(gatherer *ICEGatherer) *ICETransport {
+	t := pc.api.NewICETransport(gatherer)
 	t.internalOnConnectionStateChangeHandler.Store(func(state ICETransportState) {
-		var cs ICEConnectionState
-		switch state {
-		case ICETransportStateNew:
-			cs = ICEConnectionStateNew
-		case ICETransportStateChecking:
-			cs = ICEConnectionStateChecking
-		case ICETransportStateConnected:
-			cs = ICEConnectionStateConnected
-		case ICETransportStateCompleted:
-			cs = ICEConnectionStateCompleted
-		case ICETransportStateFailed:
-			cs = ICEConnectionStateFailed
-		case ICETransportStateDisconnected:
-			cs = ICEConnectionStateDisconnected
-		case ICETransportStateClosed:
-			cs = ICEConnectionStateClosed
-		default:
+		cs := state.toICEConnectionState()
+		if cs == ICEConnectionState(Unknown) {
 			pc.log.Warnf("OnConnectionStateChange: unhandled ICE state: %s", state)
 			return
 		}
+
+		// With media sections that are not bundled the state represents all ICE transports
+		if t != pc.iceTransport || pc.hasMediaSectionTransports() {
+			if cs = pc.aggregateICEConnectionState(); cs == pc.ICEConnectionState() {
+				return
+			}
+		}
+
 		pc.onICEConnectionStateChange(cs)
-		pc.updateConnectionState(cs, pc.dtlsTransport.State())
+		pc.updateConnectionState(cs, pc.aggregateDTLSTransportState())
 	})
 
 	return t
@@ -962,6 +1090,15 @@ func (pc *PeerConnection) SetLocalDescription(desc SessionDescription) error {
 		return &rtcerr.InvalidStateError{Err: ErrConnectionClosed}
 	}
 
+	// A rollback has no SDP, it discards the pending local offer
+	if desc.Type == SDPTypeRollback {
+		if err := pc.setDescription(&desc, stateChangeOpSetLocal); err != nil {
+			return err
+		}
+		pc.releaseRolledBackMids()
+		return nil
+	}
+
 	haveLocalDescription := pc.currentLocalDescription != nil
 
 	// JSEP 5.4
@@ -992,21 +1129,86 @@ func (pc *PeerConnection) SetLocalDescription(desc SessionDescription) error {
 	remoteDesc := pc.RemoteDescription()
 	if weAnswer && remoteDesc != nil {
 		_ = setRTPTransceiverCurrentDirection(&desc, currentTransceivers, false)
+		pc.bindTransceiverTransports(currentTransceivers)
 		if err := pc.startRTPSenders(currentTransceivers); err != nil {
 			return err
 		}
 		pc.configureRTPReceivers(haveLocalDescription, remoteDesc, currentTransceivers)
 		pc.ops.Enqueue(func() {
+			pc.startMediaSectionTransports(pc.iceTransport.Role(), remoteDesc)
 			pc.startRTP(haveLocalDescription, remoteDesc, currentTransceivers)
 		})
 	}
 
+	// Pooled candidates are only used by the first local description
+	if err := pc.discardICECandidatePool(); err != nil {
+		return err
+	}
+
+	for _, transport := range pc.getMediaSectionTransports() {
+		if transport.iceGatherer.State() == ICEGathererStateNew {
+			if err := transport.iceGatherer.Gather(); err != nil {
+				return err
+			}
+		}
+	}
+
 	if pc.iceGatherer.State() == ICEGathererStateNew {
 		return pc.iceGatherer.Gather()
 	}
 	return nil
 }
 
+// releaseRolledBackMids clears the mids that were only assigned by a rolled back offer,
+// so the transceivers can be matched with the media sections of the next remote offer.
+// https://datatracker.ietf.org/doc/html/rfc8829#section-4.1.8.2
+func (pc *PeerConnection) releaseRolledBackMids() {
+	pc.mu.Lock()
+	current := pc.currentLocalDescription
+	pc.mu.Unlock()
+
+	negotiated := map[string]bool{}
+	if current != nil && current.parsed != nil {
+		for _, media := range current.parsed.MediaDescriptions {
+			negotiated[getMidValue(media)] = true
+		}
+	}
+
+	for _, t := range pc.GetTransceivers() {
+		if mid := t.Mid(); mid != "" && !negotiated[mid] {
+			t.mid.Store("")
+		}
+	}
+}
+
+// removeRolledBackTransceivers removes the transceivers created by a rolled back remote offer,
+// unless a track has been attached to them since.
+// https://datatracker.ietf.org/doc/html/rfc8829#section-4.1.8.2
+func (pc *PeerConnection) removeRolledBackTransceivers() error {
+	pc.mu.Lock()
+	removed := []*RTPTransceiver{}
+	for _, created := range pc.remoteOfferTransceivers {
+		if created.Sender() != nil {
+			continue
+		}
+		for i, t := range pc.rtpTransceivers {
+			if t == created {
+				pc.rtpTransceivers = append(pc.rtpTransceivers[:i], pc.rtpTransceivers[i+1:]...)
+				removed = append(removed, t)
+				break
+			}
+		}
+	}
+	pc.remoteOfferTransceivers = nil
+	pc.mu.Unlock()
+
+	closeErrs := []error{}
+	for _, t := range removed {
+		closeErrs = append(closeErrs, t.Stop())
+	}
+	return util.FlattenErrs(closeErrs)
+}
+
 // LocalDescription returns PendingLocalDescription if it is not null and
 // otherwise it returns CurrentLocalDescription. This property is used to
 // determine if SetLocalDescription has already been called.
@@ -1025,6 +1227,16 @@ func (pc *PeerConnection) SetRemoteDescription(desc SessionDescription) error {
 		return &rtcerr.InvalidStateError{Err: ErrConnectionClosed}
 	}
 
+	// A rollback has no SDP, it discards the pending remote offer
+	if desc.Type == SDPTypeRollback {
+		if err := pc.setDescription(&desc, stateChangeOpSetRemote); err != nil {
+			return err
+		}
+		err := pc.removeRolledBackTransceivers()
+		pc.releaseRolledBackMids()
+		return err
+	}
+
 	isRenegotation := pc.currentRemoteDescription != nil
 
 	if _, err := desc.Unmarshal(); err != nil {
@@ -1047,6 +1259,12 @@ func (pc *PeerConnection) SetRemoteDescription(desc SessionDescription) error {
 
 	weOffer := desc.Type == SDPTypeAnswer
 
+	if !weOffer {
+		pc.mu.Lock()
+		pc.remoteOfferTransceivers = nil
+		pc.mu.Unlock()
+	}
+
 	if !weOffer && !detectedPlanB {
 		for _, media := range pc.RemoteDescription().parsed.MediaDescriptions {
 			midValue := getMidValue(media)
@@ -1090,6 +1308,7 @@ func (pc *PeerConnection) SetRemoteDescription(desc SessionDescription) error {
 				t = newRTPTransceiver(receiver, nil, localDirection, kind, pc.api)
 				pc.mu.Lock()
 				pc.addRTPTransceiver(t)
+				pc.remoteOfferTransceivers = append(pc.remoteOfferTransceivers, t)
 				pc.mu.Unlock()
 
 				// if transceiver is create by remote sdp, set prefer codec same as remote peer
@@ -1123,7 +1342,26 @@ func (pc *PeerConnection) SetRemoteDescription(desc SessionDescription) error {
 		}
 	}
 
-	remoteUfrag, remotePwd, candidates, err := extractICEDetails(desc.parsed, pc.log)
+	// If the remote doesn't accept BUNDLE the transport of the PeerConnection only
+	// carries the first media section, the others are started with their own transport
+	iceDescription := desc.parsed
+	if pc.configuration.BundlePolicy == BundlePolicyMaxCompat && !descriptionIsBundled(desc.parsed) && len(desc.parsed.MediaDescriptions) != 0 {
+		iceDescription = descriptionForMedia(desc.parsed, desc.parsed.MediaDescriptions[0])
+	} else {
+		// A bundled max-compat offer has a transport for each media section, the one of
+		// the BUNDLE group is used
+		if media := bundleTransportMedia(desc.parsed); media != nil {
+			iceDescription = descriptionForMedia(desc.parsed, media)
+		}
+
+		if !isRenegotation {
+			if err := pc.stopMediaSectionTransports(); err != nil {
+				return err
+			}
+		}
+	}
+
+	remoteUfrag, remotePwd, candidates, err := extractICEDetails(iceDescription, pc.log)
 	if err != nil {
 		return err
 	}
@@ -1152,11 +1390,13 @@ func (pc *PeerConnection) SetRemoteDescription(desc SessionDescription) error {
 	if isRenegotation {
 		if weOffer {
 			_ = setRTPTransceiverCurrentDirection(&desc, currentTransceivers, true)
+			pc.bindTransceiverTransports(currentTransceivers)
 			if err = pc.startRTPSenders(currentTransceivers); err != nil {
 				return err
 			}
 			pc.configureRTPReceivers(true, &desc, currentTransceivers)
 			pc.ops.Enqueue(func() {
+				pc.startMediaSectionTransports(pc.iceTransport.Role(), &desc)
 				pc.startRTP(true, &desc, currentTransceivers)
 			})
 		}
@@ -1165,7 +1405,7 @@ func (pc *PeerConnection) SetRemoteDescription(desc SessionDescription) error {
 
 	remoteIsLite := isIceLiteSet(desc.parsed)
 
-	fingerprint, fingerprintHash, err := extractFingerprint(desc.parsed)
+	fingerprints, err := extractFingerprints(iceDescription)
 	if err != nil {
 		return err
 	}
@@ -1182,6 +1422,7 @@ func (pc *PeerConnection) SetRemoteDescription(desc SessionDescription) error {
 	// the connection is actually established.
 	if weOffer {
 		_ = setRTPTransceiverCurrentDirection(&desc, currentTransceivers, true)
+		pc.bindTransceiverTransports(currentTransceivers)
 		if err := pc.startRTPSenders(currentTransceivers); err != nil {
 			return err
 		}
@@ -1190,8 +1431,9 @@ func (pc *PeerConnection) SetRemoteDescription(desc SessionDescription) error {
 	}
 
 	pc.ops.Enqueue(func() {
-		pc.startTransports(iceRole, dtlsRoleFromRemoteSDP(desc.parsed), remoteUfrag, remotePwd, fingerprint, fingerprintHash)
+		pc.startTransports(iceRole, dtlsRoleFromRemoteSDP(iceDescription), remoteUfrag, remotePwd, fingerprints)
 		if weOffer {
+			pc.startMediaSectionTransports(iceRole, &desc)
 			pc.startRTP(false, &desc, currentTransceivers)
 		}
 	})
@@ -1359,7 +1601,7 @@ func (pc *PeerConnection) configureRTPReceivers(isRenegotiation bool, remoteDesc
 				continue
 			}
 
-			receiver, err := pc.api.NewRTPReceiver(receiver.kind, pc.dtlsTransport)
+			receiver, err := pc.api.NewRTPReceiver(receiver.kind, pc.dtlsTransportForMid(t.Mid()))
 			if err != nil {
 				pc.log.Warnf("Failed to create new RtpReceiver: %s", err)
 				continue
@@ -1438,6 +1680,10 @@ func (pc *PeerConnection) startRTPReceivers(remoteDesc *SessionDescription, curr
 func (pc *PeerConnection) startRTPSenders(currentTransceivers []*RTPTransceiver) error {
 	for _, transceiver := range currentTransceivers {
 		if sender := transceiver.Sender(); sender != nil && sender.isNegotiated() && !sender.hasSent() {
+			if pc.bandwidthEstimator != nil {
+				sender.setTargetBitrate(pc.bandwidthEstimator.GetTargetBitrate())
+			}
+
 			err := sender.Send(sender.GetParameters())
 			if err != nil {
 				return err
@@ -1508,7 +1754,7 @@ func (pc *PeerConnection) handleUndeclaredSSRC(ssrc SSRC, remoteDescription *Ses
 	return true, nil
 }
 
-func (pc *PeerConnection) handleIncomingSSRC(rtpStream io.Reader, ssrc SSRC) error { //nolint:gocognit
+func (pc *PeerConnection) handleIncomingSSRC(rtpStream io.Reader, ssrc SSRC, dtlsTransport *DTLSTransport) error { //nolint:gocognit
 	remoteDescription := pc.RemoteDescription()
 	if remoteDescription == nil {
 		return errPeerConnRemoteDescriptionNil
@@ -1519,6 +1765,9 @@ func (pc *PeerConnection) handleIncomingSSRC(rtpStream io.Reader, ssrc SSRC) err
 		if track.repairSsrc != nil && ssrc == *track.repairSsrc {
 			return nil
 		}
+		if track.fecSsrc != nil && ssrc == *track.fecSsrc {
+			return nil
+		}
 		for _, trackSsrc := range track.ssrcs {
 			if ssrc == trackSsrc {
 				return nil
@@ -1562,7 +1811,7 @@ func (pc *PeerConnection) handleIncomingSSRC(rtpStream io.Reader, ssrc SSRC) err
 	}
 
 	streamInfo := createStreamInfo("", ssrc, params.Codecs[0].PayloadType, params.Codecs[0].RTPCodecCapability, params.HeaderExtensions)
-	readStream, interceptor, rtcpReadStream, rtcpInterceptor, err := pc.dtlsTransport.streamsForSSRC(ssrc, *streamInfo)
+	readStream, interceptor, rtcpReadStream, rtcpInterceptor, err := dtlsTransport.streamsForSSRC(ssrc, *streamInfo)
 	if err != nil {
 		return err
 	}
@@ -1607,15 +1856,15 @@ func (pc *PeerConnection) handleIncomingSSRC(rtpStream io.Reader, ssrc SSRC) err
 }
 
 // undeclaredMediaProcessor handles RTP/RTCP packets that don't match any a:ssrc lines
-func (pc *PeerConnection) undeclaredMediaProcessor() {
-	go pc.undeclaredRTPMediaProcessor()
-	go pc.undeclaredRTCPMediaProcessor()
+func (pc *PeerConnection) undeclaredMediaProcessor(dtlsTransport *DTLSTransport) {
+	go pc.undeclaredRTPMediaProcessor(dtlsTransport)
+	go pc.undeclaredRTCPMediaProcessor(dtlsTransport)
 }
 
-func (pc *PeerConnection) undeclaredRTPMediaProcessor() {
+func (pc *PeerConnection) undeclaredRTPMediaProcessor(dtlsTransport *DTLSTransport) {
 	var simulcastRoutineCount uint64
 	for {
-		srtpSession, err := pc.dtlsTransport.getSRTPSession()
+		srtpSession, err := dtlsTransport.getSRTPSession()
 		if err != nil {
 			pc.log.Warnf("undeclaredMediaProcessor failed to open SrtpSession: %v", err)
 			return
@@ -1637,21 +1886,21 @@ func (pc *PeerConnection) undeclaredRTPMediaProcessor() {
 		if atomic.AddUint64(&simulcastRoutineCount, 1) >= simulcastMaxProbeRoutines {
 			atomic.AddUint64(&simulcastRoutineCount, ^uint64(0))
 			pc.log.Warn(ErrSimulcastProbeOverflow.Error())
-			pc.dtlsTransport.storeSimulcastStream(stream)
+			dtlsTransport.storeSimulcastStream(stream)
 			continue
 		}
 
 		go func(rtpStream io.Reader, ssrc SSRC) {
-			if err := pc.handleIncomingSSRC(rtpStream, ssrc); err != nil {
+			if err := pc.handleIncomingSSRC(rtpStream, ssrc, dtlsTransport); err != nil {
 				pc.log.Errorf(incomingUnhandledRTPSsrc, ssrc, err)
-				pc.dtlsTransport.storeSimulcastStream(stream)
+				dtlsTransport.storeSimulcastStream(stream)
 			}
 			atomic.AddUint64(&simulcastRoutineCount, ^uint64(0))
 		}(stream, SSRC(ssrc))
 	}
 }
 
-func (pc *PeerConnection) undeclaredRTCPMediaProcessor() {
+func (pc *PeerConnection) undeclaredRTCPMediaProcessor(dtlsTransport *DTLSTransport) {
 	var unhandledStreams []*srtp.ReadStreamSRTCP
 	defer func() {
 		for _, s := range unhandledStreams {
@@ -1659,7 +1908,7 @@ func (pc *PeerConnection) undeclaredRTCPMediaProcessor() {
 		}
 	}()
 	for {
-		srtcpSession, err := pc.dtlsTransport.getSRTCPSession()
+		srtcpSession, err := dtlsTransport.getSRTCPSession()
 		if err != nil {
 			pc.log.Warnf("undeclaredMediaProcessor failed to open SrtcpSession: %v", err)
 			return
@@ -1716,6 +1965,9 @@ func (pc *PeerConnection) AddICECandidate(candidate ICECandidateInit) error {
 		iceCandidate = &c
 	}
 
+	if transport := pc.mediaSectionTransportForCandidate(candidate); transport != nil {
+		return transport.iceTransport.AddRemoteCandidate(iceCandidate)
+	}
 	return pc.iceTransport.AddRemoteCandidate(iceCandidate)
 }
 
@@ -1778,7 +2030,7 @@ func (pc *PeerConnection) AddTrack(track TrackLocal) (*RTPSender, error) {
 		// that's worked for all browsers.
 		if !t.stopped && t.kind == track.Kind() && t.Sender() == nil &&
 			!(currentDirection == RTPTransceiverDirectionSendrecv || currentDirection == RTPTransceiverDirectionSendonly) {
-			sender, err := pc.api.NewRTPSender(track, pc.dtlsTransport)
+			sender, err := pc.api.NewRTPSender(track, pc.dtlsTransportForMid(t.Mid()))
 			if err == nil {
 				err = t.SetSender(sender, track)
 				if err != nil {
@@ -1961,6 +2213,10 @@ func (pc *PeerConnection) CreateDataChannel(label string, options *DataChannelIn
 			return nil, &rtcerr.TypeError{Err: ErrProtocolTooLarge}
 		}
 
+		if options.Priority != nil {
+			params.Priority = *options.Priority
+		}
+
 		// https://w3c.github.io/webrtc-pc/#peer-to-peer-data-api (Step #12)
 		if options.Negotiated != nil {
 			params.Negotiated = *options.Negotiated
@@ -2009,6 +2265,9 @@ func (pc *PeerConnection) WriteRTCP(pkts []rtcp.Packet) error {
 }
 
 func (pc *PeerConnection) writeRTCP(pkts []rtcp.Packet, _ interceptor.Attributes) (int, error) {
+	if pc.hasMediaSectionTransports() {
+		return pc.writeRTCPToMediaSections(pkts)
+	}
 	return pc.dtlsTransport.WriteRTCP(pkts)
 }
 
@@ -2061,6 +2320,8 @@ func (pc *PeerConnection) Close() error {
 	if pc.iceTransport != nil {
 		closeErrs = append(closeErrs, pc.iceTransport.Stop())
 	}
+	closeErrs = append(closeErrs, pc.stopMediaSectionTransports())
+	closeErrs = append(closeErrs, pc.discardICECandidatePool())
 
 	// https://www.w3.org/TR/webrtc/#dom-rtcpeerconnection-close (step #11)
 	pc.updateConnectionState(pc.ICEConnectionState(), pc.dtlsTransport.State())
@@ -2086,7 +2347,7 @@ func (pc *PeerConnection) CurrentLocalDescription() *SessionDescription {
 	iceGather := pc.iceGatherer
 	iceGatheringState := pc.ICEGatheringState()
 	pc.mu.Unlock()
-	return populateLocalCandidates(localDescription, iceGather, iceGatheringState)
+	return populateLocalCandidates(localDescription, iceGather, pc.getMediaSectionTransports(), iceGatheringState)
 }
 
 // PendingLocalDescription represents a local description that is in the
@@ -2099,7 +2360,7 @@ func (pc *PeerConnection) PendingLocalDescription() *SessionDescription {
 	iceGather := pc.iceGatherer
 	iceGatheringState := pc.ICEGatheringState()
 	pc.mu.Unlock()
-	return populateLocalCandidates(localDescription, iceGather, iceGatheringState)
+	return populateLocalCandidates(localDescription, iceGather, pc.getMediaSectionTransports(), iceGatheringState)
 }
 
 // CurrentRemoteDescription represents the last remote description that was
@@ -2138,14 +2399,24 @@ func (pc *PeerConnection) ICEGatheringState() ICEGatheringState {
 		return ICEGatheringStateNew
 	}
 
-	switch pc.iceGatherer.State() {
-	case ICEGathererStateNew:
-		return ICEGatheringStateNew
-	case ICEGathererStateGathering:
-		return ICEGatheringStateGathering
-	default:
-		return ICEGatheringStateComplete
+	gatheringState := ICEGatheringStateComplete
+	gatherers := []*ICEGatherer{pc.iceGatherer}
+	for _, transport := range pc.getMediaSectionTransports() {
+		gatherers = append(gatherers, transport.iceGatherer)
 	}
+
+	for _, gatherer := range gatherers {
+		switch gatherer.State() {
+		case ICEGathererStateNew:
+			if gatheringState == ICEGatheringStateComplete {
+				gatheringState = ICEGatheringStateNew
+			}
+		case ICEGathererStateGathering:
+			gatheringState = ICEGatheringStateGathering
+		default:
+		}
+	}
+	return gatheringState
 }
 
 // ConnectionState attribute returns the connection state of the
@@ -2172,6 +2443,10 @@ func (pc *PeerConnection) GetStats() StatsReport {
 	if pc.iceGatherer != nil {
 		pc.iceGatherer.collectStats(statsCollector)
 	}
+	for _, transport := range pc.getMediaSectionTransports() {
+		transport.iceGatherer.collectStats(statsCollector)
+		transport.iceTransport.collectStats(statsCollector)
+	}
 	if pc.iceTransport != nil {
 		pc.iceTransport.collectStats(statsCollector)
 	}
@@ -2193,6 +2468,15 @@ func (pc *PeerConnection) GetStats() StatsReport {
 	}
 	pc.sctpTransport.collectStats(statsCollector)
 
+	for _, t := range pc.rtpTransceivers {
+		if sender := t.Sender(); sender != nil {
+			sender.collectStats(statsCollector, pc.statsInterceptor)
+		}
+		if receiver := t.Receiver(); receiver != nil {
+			receiver.collectStats(statsCollector, pc.statsInterceptor)
+		}
+	}
+
 	stats := PeerConnectionStats{
 		Timestamp:             statsTimestampNow(),
 		Type:                  StatsTypePeerConnection,
@@ -2219,7 +2503,7 @@ func (pc *PeerConnection) GetStats() StatsReport {
 }
 
 // Start all transports. PeerConnection now has enough state
-func (pc *PeerConnection) startTransports(iceRole ICERole, dtlsRole DTLSRole, remoteUfrag, remotePwd, fingerprint, fingerprintHash string) {
+func (pc *PeerConnection) startTransports(iceRole ICERole, dtlsRole DTLSRole, remoteUfrag, remotePwd string, fingerprints []DTLSFingerprint) {
 	// Start the ice transport
 	err := pc.iceTransport.Start(
 		pc.iceGatherer,
@@ -2238,9 +2522,9 @@ func (pc *PeerConnection) startTransports(iceRole ICERole, dtlsRole DTLSRole, re
 	// Start the dtls transport
 	err = pc.dtlsTransport.Start(DTLSParameters{
 		Role:         dtlsRole,
-		Fingerprints: []DTLSFingerprint{{Algorithm: fingerprintHash, Value: fingerprint}},
+		Fingerprints: fingerprints,
 	})
-	pc.updateConnectionState(pc.ICEConnectionState(), pc.dtlsTransport.State())
+	pc.updateConnectionState(pc.ICEConnectionState(), pc.aggregateDTLSTransportState())
 	if err != nil {
 		pc.log.Warnf("Failed to start manager: %s", err)
 		return
@@ -2250,11 +2534,12 @@ func (pc *PeerConnection) startTransports(iceRole ICERole, dtlsRole DTLSRole, re
 // nolint: gocognit
 func (pc *PeerConnection) startRTP(isRenegotiation bool, remoteDesc *SessionDescription, currentTransceivers []*RTPTransceiver) {
 	if !isRenegotiation {
-		pc.undeclaredMediaProcessor()
+		pc.undeclaredMediaProcessor(pc.dtlsTransport)
 	}
 
 	pc.startRTPReceivers(remoteDesc, currentTransceivers)
-	if haveApplicationMediaSection(remoteDesc.parsed) {
+	if media := haveDataChannel(remoteDesc); media != nil {
+		pc.sctpTransport.setDTLSTransport(pc.dtlsTransportForMid(getMidValue(media)))
 		pc.startSCTP()
 	}
 }
@@ -2322,10 +2607,24 @@ func (pc *PeerConnection) generateUnmatchedSDP(transceivers []*RTPTransceiver, u
 		}
 	}
 
-	dtlsFingerprints, err := pc.configuration.Certificates[0].GetFingerprints()
+	switch pc.configuration.BundlePolicy {
+	case BundlePolicyMaxCompat:
+		// Every media section can be used on its own if the remote doesn't accept BUNDLE
+		if err = pc.addMediaSectionTransports(mediaSections, false); err != nil {
+			return nil, err
+		}
+	case BundlePolicyMaxBundle:
+		for i := 1; i < len(mediaSections); i++ {
+			mediaSections[i].bundleOnly = true
+		}
+	default:
+	}
+
+	dtlsParams, err := pc.dtlsTransport.GetLocalParameters()
 	if err != nil {
 		return nil, err
 	}
+	dtlsFingerprints := dtlsParams.Fingerprints
 
 	return populateSDP(d, isPlanB, dtlsFingerprints, pc.api.settingEngine.sdpMediaLevelFingerprints, pc.api.settingEngine.candidates.ICELite, true, pc.api.mediaEngine, connectionRoleFromDtlsRole(defaultDtlsRoleOffer), candidates, iceParams, mediaSections, pc.ICEGatheringState())
 }
@@ -2449,16 +2748,34 @@ func (pc *PeerConnection) generateMatchedSDP(transceivers []*RTPTransceiver, use
 		pc.log.Info("Plan-B Offer detected; responding with Plan-B Answer")
 	}
 
-	dtlsFingerprints, err := pc.configuration.Certificates[0].GetFingerprints()
+	// The remote doesn't accept BUNDLE, every media section is sent over its own transport
+	if pc.configuration.BundlePolicy == BundlePolicyMaxCompat && !descriptionIsBundled(remoteDescription.parsed) {
+		if err = pc.addMediaSectionTransports(mediaSections, true); err != nil {
+			return nil, err
+		}
+	}
+
+	dtlsParams, err := pc.dtlsTransport.GetLocalParameters()
 	if err != nil {
 		return nil, err
 	}
+	dtlsFingerprints := dtlsParams.Fingerprints
 
 	return populateSDP(d, detectedPlanB, dtlsFingerprints, pc.api.settingEngine.sdpMediaLevelFingerprints, pc.api.settingEngine.candidates.ICELite, isExtmapAllowMixed, pc.api.mediaEngine, connectionRole, candidates, iceParams, mediaSections, pc.ICEGatheringState())
 }
 
 func (pc *PeerConnection) setGatherCompleteHandler(handler func()) {
-	pc.iceGatherer.onGatheringCompleteHandler.Store(handler)
+	// With media sections that are not bundled gathering is complete once every transport is done
+	onGatheringComplete := func() {
+		if pc.ICEGatheringState() == ICEGatheringStateComplete {
+			handler()
+		}
+	}
+
+	pc.iceGatherer.onGatheringCompleteHandler.Store(onGatheringComplete)
+	for _, transport := range pc.getMediaSectionTransports() {
+		transport.iceGatherer.onGatheringCompleteHandler.Store(onGatheringComplete)
+	}
 }
 
 // SCTP returns the SCTPTransport for this PeerConnection
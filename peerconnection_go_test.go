@@ -1584,3 +1584,248 @@ a=ssrc:1455629982 cname:{61fd3093-0326-4b12-8258-86bdc1fe677a}
 
 	assert.NoError(t, peerConnection.Close())
 }
+
+func TestPeerConnection_BundlePolicyMaxCompat(t *testing.T) {
+	lim := test.TimeOut(time.Second * 30)
+	defer lim.Stop()
+
+	report := test.CheckRoutines(t)
+	defer report()
+
+	addVideoTracks := func(pc *PeerConnection) []*TrackLocalStaticSample {
+		tracks := []*TrackLocalStaticSample{}
+		for i := 0; i < 2; i++ {
+			track, err := NewTrackLocalStaticSample(RTPCodecCapability{MimeType: MimeTypeVP8}, fmt.Sprintf("video%d", i), "pion")
+			assert.NoError(t, err)
+
+			_, err = pc.AddTrack(track)
+			assert.NoError(t, err)
+
+			tracks = append(tracks, track)
+		}
+		return tracks
+	}
+
+	t.Run("Bundled", func(t *testing.T) {
+		pcOffer, err := NewPeerConnection(Configuration{BundlePolicy: BundlePolicyMaxCompat})
+		assert.NoError(t, err)
+
+		pcAnswer, err := NewPeerConnection(Configuration{})
+		assert.NoError(t, err)
+
+		addVideoTracks(pcOffer)
+
+		offer, err := pcOffer.CreateOffer(nil)
+		assert.NoError(t, err)
+
+		parsed := offer.parsed
+		assert.Len(t, parsed.MediaDescriptions, 2)
+
+		firstUfrag, _ := parsed.MediaDescriptions[0].Attribute("ice-ufrag")
+		secondUfrag, _ := parsed.MediaDescriptions[1].Attribute("ice-ufrag")
+		assert.NotEqual(t, firstUfrag, secondUfrag)
+		assert.True(t, descriptionIsBundled(parsed))
+
+		connected := untilConnectionState(PeerConnectionStateConnected, pcOffer, pcAnswer)
+
+		assert.NoError(t, signalPair(pcOffer, pcAnswer))
+		connected.Wait()
+
+		assert.False(t, pcOffer.hasMediaSectionTransports())
+
+		closePairNow(t, pcOffer, pcAnswer)
+	})
+
+	t.Run("Unbundled", func(t *testing.T) {
+		pcOffer, err := NewPeerConnection(Configuration{BundlePolicy: BundlePolicyMaxCompat})
+		assert.NoError(t, err)
+
+		pcAnswer, err := NewPeerConnection(Configuration{BundlePolicy: BundlePolicyMaxCompat})
+		assert.NoError(t, err)
+
+		tracks := addVideoTracks(pcOffer)
+
+		onTrackFired, onTrackFiredFunc := context.WithCancel(context.Background())
+		pcAnswer.OnTrack(func(track *TrackRemote, r *RTPReceiver) {
+			if track.StreamID() == "pion" && track.ID() == "video1" {
+				onTrackFiredFunc()
+			}
+		})
+
+		connected := untilConnectionState(PeerConnectionStateConnected, pcOffer, pcAnswer)
+
+		assert.NoError(t, signalPairWithModification(pcOffer, pcAnswer, func(sessionDescription string) string {
+			return regexp.MustCompile("a=group:BUNDLE[^\r\n]*\r\n").ReplaceAllString(sessionDescription, "")
+		}))
+
+		answer := pcAnswer.CurrentLocalDescription()
+		assert.NotNil(t, answer)
+		assert.False(t, strings.Contains(answer.SDP, "a=group:BUNDLE"))
+
+		senders := pcOffer.GetSenders()
+		assert.Len(t, senders, 2)
+		assert.NotEqual(t, senders[0].Transport(), senders[1].Transport())
+
+		connected.Wait()
+
+		sendVideoUntilDone(onTrackFired.Done(), t, []*TrackLocalStaticSample{tracks[1]})
+
+		// The stats of the stream refer to the transport of its media section
+		report := pcOffer.GetStats()
+		outboundStats, ok := report.GetOutboundRTPStreamStats(senders[1].GetParameters().Encodings[0].SSRC)
+		assert.True(t, ok)
+		assert.Equal(t, "iceTransport-"+pcOffer.GetTransceivers()[1].Mid(), outboundStats.TransportID)
+		_, ok = report[outboundStats.TransportID].(TransportStats)
+		assert.True(t, ok)
+
+		closePairNow(t, pcOffer, pcAnswer)
+	})
+
+	t.Run("MaxBundle", func(t *testing.T) {
+		pcOffer, err := NewPeerConnection(Configuration{BundlePolicy: BundlePolicyMaxBundle})
+		assert.NoError(t, err)
+
+		pcAnswer, err := NewPeerConnection(Configuration{})
+		assert.NoError(t, err)
+
+		tracks := addVideoTracks(pcOffer)
+
+		offer, err := pcOffer.CreateOffer(nil)
+		assert.NoError(t, err)
+		assert.Equal(t, 1, strings.Count(offer.SDP, "a=bundle-only"))
+
+		// Only the first media section has a port
+		parsed := offer.parsed
+		assert.Len(t, parsed.MediaDescriptions, 2)
+		assert.Equal(t, 9, parsed.MediaDescriptions[0].MediaName.Port.Value)
+		assert.Equal(t, 0, parsed.MediaDescriptions[1].MediaName.Port.Value)
+
+		// The bundle-only media section is accepted into the BUNDLE group
+		onTrackFired, onTrackFiredFunc := context.WithCancel(context.Background())
+		pcAnswer.OnTrack(func(track *TrackRemote, r *RTPReceiver) {
+			if track.StreamID() == "pion" && track.ID() == "video1" {
+				onTrackFiredFunc()
+			}
+		})
+
+		connected := untilConnectionState(PeerConnectionStateConnected, pcOffer, pcAnswer)
+
+		assert.NoError(t, signalPair(pcOffer, pcAnswer))
+		connected.Wait()
+
+		sendVideoUntilDone(onTrackFired.Done(), t, []*TrackLocalStaticSample{tracks[1]})
+
+		closePairNow(t, pcOffer, pcAnswer)
+	})
+}
+
+func TestPeerConnection_ICECandidatePool(t *testing.T) {
+	lim := test.TimeOut(time.Second * 30)
+	defer lim.Stop()
+
+	report := test.CheckRoutines(t)
+	defer report()
+
+	t.Run("Pre-gathered", func(t *testing.T) {
+		pc, err := NewPeerConnection(Configuration{ICECandidatePoolSize: 1})
+		assert.NoError(t, err)
+
+		// Candidates are gathered without changing the gathering state
+		for {
+			candidates, err := pc.iceGatherer.GetLocalCandidates()
+			assert.NoError(t, err)
+			if len(candidates) != 0 {
+				break
+			}
+			time.Sleep(10 * time.Millisecond)
+		}
+		assert.Equal(t, ICEGatheringStateNew, pc.ICEGatheringState())
+
+		var candidateCount int
+		pc.OnICECandidate(func(c *ICECandidate) {
+			if c != nil {
+				candidateCount++
+			}
+		})
+
+		_, err = pc.CreateDataChannel("initial_data_channel", nil)
+		assert.NoError(t, err)
+
+		offer, err := pc.CreateOffer(nil)
+		assert.NoError(t, err)
+
+		gatherComplete := GatheringCompletePromise(pc)
+		assert.NoError(t, pc.SetLocalDescription(offer))
+		<-gatherComplete
+
+		assert.NotZero(t, candidateCount)
+		assert.Contains(t, pc.LocalDescription().SDP, "a=candidate:")
+
+		assert.NoError(t, pc.Close())
+	})
+
+	t.Run("Discarded from OnICECandidate", func(t *testing.T) {
+		pc, err := NewPeerConnection(Configuration{ICECandidatePoolSize: 1})
+		assert.NoError(t, err)
+
+		for {
+			candidates, err := pc.iceGatherer.GetLocalCandidates()
+			assert.NoError(t, err)
+			if len(candidates) != 0 {
+				break
+			}
+			time.Sleep(10 * time.Millisecond)
+		}
+
+		// The handlers are called without holding the pool, it can be discarded while
+		// its candidates are handed out
+		discarded := make(chan error, 1)
+		var once sync.Once
+		pc.OnICECandidate(func(c *ICECandidate) {
+			once.Do(func() {
+				discarded <- pc.iceGatherer.discardPool()
+			})
+		})
+
+		_, err = pc.CreateDataChannel("initial_data_channel", nil)
+		assert.NoError(t, err)
+
+		offer, err := pc.CreateOffer(nil)
+		assert.NoError(t, err)
+
+		assert.NoError(t, pc.SetLocalDescription(offer))
+		assert.NoError(t, <-discarded)
+
+		assert.NoError(t, pc.Close())
+	})
+
+	t.Run("Lowered", func(t *testing.T) {
+		pc, err := NewPeerConnection(Configuration{ICECandidatePoolSize: 3})
+		assert.NoError(t, err)
+		assert.Len(t, pc.iceCandidatePool, 2)
+
+		assert.NoError(t, pc.SetConfiguration(Configuration{ICECandidatePoolSize: 1}))
+		assert.Len(t, pc.iceCandidatePool, 0)
+
+		assert.NoError(t, pc.Close())
+	})
+
+	t.Run("Consumed", func(t *testing.T) {
+		pc, err := NewPeerConnection(Configuration{ICECandidatePoolSize: 3, BundlePolicy: BundlePolicyMaxCompat})
+		assert.NoError(t, err)
+
+		for i := 0; i < 2; i++ {
+			_, err = pc.AddTransceiverFromKind(RTPCodecTypeVideo)
+			assert.NoError(t, err)
+		}
+
+		offer, err := pc.CreateOffer(nil)
+		assert.NoError(t, err)
+		assert.Len(t, pc.iceCandidatePool, 1)
+
+		assert.NoError(t, pc.SetLocalDescription(offer))
+		assert.Len(t, pc.iceCandidatePool, 0)
+
+		assert.NoError(t, pc.Close())
+	})
+}
@@ -27,6 +27,10 @@ type PeerConnection struct {
 	onICEConnectionStateChangeHandler *js.Func
 	onICECandidateHandler             *js.Func
 	onICEGatheringStateChangeHandler  *js.Func
+	onTrackHandler                    *js.Func
+
+	// The MediaStreams the local tracks are sent in, indexed by StreamID
+	localStreams map[string]js.Value
 
 	// Used by GatheringCompletePromise
 	onGatherCompleteHandler func()
@@ -51,8 +55,9 @@ func (api *API) NewPeerConnection(configuration Configuration) (_ *PeerConnectio
 	configMap := configurationToValue(configuration)
 	underlying := js.Global().Get("window").Get("RTCPeerConnection").New(configMap)
 	return &PeerConnection{
-		underlying: underlying,
-		api:        api,
+		underlying:   underlying,
+		localStreams: map[string]js.Value{},
+		api:          api,
 	}, nil
 }
 
@@ -102,6 +107,29 @@ func (pc *PeerConnection) OnDataChannel(f func(*DataChannel)) {
 	pc.underlying.Set("ondatachannel", onDataChannelHandler)
 }
 
+// OnTrack sets an event handler which is called when remote track
+// arrives from a remote peer.
+func (pc *PeerConnection) OnTrack(f func(*TrackRemote, *RTPReceiver)) {
+	if pc.onTrackHandler != nil {
+		oldHandler := pc.onTrackHandler
+		defer oldHandler.Release()
+	}
+	onTrackHandler := js.FuncOf(func(this js.Value, args []js.Value) interface{} {
+		receiver := &RTPReceiver{underlying: args[0].Get("receiver")}
+		track := &TrackRemote{
+			underlying: args[0].Get("track"),
+			receiver:   receiver,
+		}
+		if streams := args[0].Get("streams"); streams.Truthy() && streams.Length() > 0 {
+			track.streamID = streams.Index(0).Get("id").String()
+		}
+		go f(track, receiver)
+		return js.Undefined()
+	})
+	pc.onTrackHandler = &onTrackHandler
+	pc.underlying.Set("ontrack", onTrackHandler)
+}
+
 // OnNegotiationNeeded sets an event handler which is invoked when
 // a change has occurred which requires session negotiation
 func (pc *PeerConnection) OnNegotiationNeeded(f func()) {
@@ -419,6 +447,9 @@ func (pc *PeerConnection) Close() (err error) {
 	if pc.onICEGatheringStateChangeHandler != nil {
 		pc.onICEGatheringStateChangeHandler.Release()
 	}
+	if pc.onTrackHandler != nil {
+		pc.onTrackHandler.Release()
+	}
 
 	return nil
 }
@@ -523,6 +554,127 @@ func (pc *PeerConnection) GetTransceivers() (transceivers []*RTPTransceiver) {
 	return
 }
 
+// AddTransceiverFromTrack Create a new RtpTransceiver(SendRecv or SendOnly) and add it to the set of transceivers.
+func (pc *PeerConnection) AddTransceiverFromTrack(track TrackLocal, init ...RTPTransceiverInit) (transceiver *RTPTransceiver, err error) {
+	defer func() {
+		if e := recover(); e != nil {
+			err = recoveryToError(e)
+		}
+	}()
+
+	if len(init) > 1 {
+		return nil, errPeerConnAddTransceiverFromTrackOnlyAcceptsOne
+	}
+
+	trackValue, err := trackLocalToValue(track)
+	if err != nil {
+		return nil, err
+	}
+
+	initValue := map[string]interface{}{
+		"streams": pc.localStreamsToValue(track),
+	}
+	if len(init) == 1 {
+		initValue["direction"] = init[0].Direction.String()
+	}
+
+	return &RTPTransceiver{
+		underlying: pc.underlying.Call("addTransceiver", trackValue, initValue),
+	}, nil
+}
+
+// AddTrack adds a Track to the PeerConnection
+func (pc *PeerConnection) AddTrack(track TrackLocal) (_ *RTPSender, err error) {
+	defer func() {
+		if e := recover(); e != nil {
+			err = recoveryToError(e)
+		}
+	}()
+
+	if track == nil {
+		return nil, errRTPSenderTrackNil
+	}
+
+	trackValue, err := trackLocalToValue(track)
+	if err != nil {
+		return nil, err
+	}
+
+	args := append([]interface{}{trackValue}, pc.localStreamsToValue(track)...)
+	return &RTPSender{
+		underlying: pc.underlying.Call("addTrack", args...),
+	}, nil
+}
+
+// RemoveTrack removes a Track from the PeerConnection
+func (pc *PeerConnection) RemoveTrack(sender *RTPSender) (err error) {
+	defer func() {
+		if e := recover(); e != nil {
+			err = recoveryToError(e)
+		}
+	}()
+
+	pc.underlying.Call("removeTrack", sender.underlying)
+	return nil
+}
+
+// localStreamsToValue returns the MediaStream a track is sent in, empty if the track
+// has no StreamID. A MediaStream is created the first time a StreamID is used. The
+// id of the MediaStream is chosen by the browser, the remote peer doesn't see the StreamID.
+func (pc *PeerConnection) localStreamsToValue(track TrackLocal) []interface{} {
+	streamID := track.StreamID()
+	if streamID == "" {
+		return []interface{}{}
+	}
+
+	stream, ok := pc.localStreams[streamID]
+	if !ok {
+		stream = js.Global().Get("window").Get("MediaStream").New()
+		pc.localStreams[streamID] = stream
+	}
+	return []interface{}{stream}
+}
+
+// GetSenders returns the RTPSender that are currently attached to this PeerConnection
+func (pc *PeerConnection) GetSenders() (senders []*RTPSender) {
+	rawSenders := pc.underlying.Call("getSenders")
+	senders = make([]*RTPSender, rawSenders.Length())
+
+	for i := 0; i < rawSenders.Length(); i++ {
+		senders[i] = &RTPSender{
+			underlying: rawSenders.Index(i),
+		}
+	}
+
+	return
+}
+
+// GetReceivers returns the RTPReceivers that are currently attached to this PeerConnection
+func (pc *PeerConnection) GetReceivers() (receivers []*RTPReceiver) {
+	rawReceivers := pc.underlying.Call("getReceivers")
+	receivers = make([]*RTPReceiver, rawReceivers.Length())
+
+	for i := 0; i < rawReceivers.Length(); i++ {
+		receivers[i] = &RTPReceiver{
+			underlying: rawReceivers.Index(i),
+		}
+	}
+
+	return
+}
+
+// GetStats return data providing statistics about the overall connection. The stats
+// reported by the browser are converted into the Stats structs of their StatsType,
+// an empty report is returned if the browser fails to collect them.
+func (pc *PeerConnection) GetStats() StatsReport {
+	reportValue, err := awaitPromise(pc.underlying.Call("getStats"))
+	if err != nil {
+		return StatsReport{}
+	}
+
+	return valueToStatsReport(reportValue)
+}
+
 // SCTP returns the SCTPTransport for this PeerConnection
 //
 // The SCTP transport over which SCTP data is sent and received. If SCTP has not been negotiated, the value is nil.
@@ -761,9 +913,17 @@ func dataChannelInitToValue(options *DataChannelInit) js.Value {
 		"protocol":          stringPointerToValue(options.Protocol),
 		"negotiated":        boolPointerToValue(options.Negotiated),
 		"id":                uint16PointerToValue(options.ID),
+		"priority":          priorityTypePointerToValue(options.Priority),
 	})
 }
 
+func priorityTypePointerToValue(priority *PriorityType) js.Value {
+	if priority == nil {
+		return js.Undefined()
+	}
+	return js.ValueOf(priority.String())
+}
+
 func rtpTransceiverInitInitToValue(init RTPTransceiverInit) js.Value {
 	return js.ValueOf(map[string]interface{}{
 		"direction": init.Direction.String(),
@@ -101,3 +101,59 @@ func TestValueToICEServer(t *testing.T) {
 		assert.Equal(t, testCase, s)
 	}
 }
+
+func TestPeerConnection_Media(t *testing.T) {
+	pcOffer, pcAnswer, err := newPair()
+	assert.NoError(t, err)
+
+	mediaStreamTrack := js.Global().Get("nonstandard").Get("RTCAudioSource").New().Call("createTrack")
+	track := NewTrackLocalMediaStreamTrack(mediaStreamTrack, "pion")
+	assert.Equal(t, RTPCodecTypeAudio, track.Kind())
+
+	_, err = pcOffer.AddTrack(&notJSTrackLocal{})
+	assert.ErrorIs(t, err, errTrackLocalNotMediaStreamTrack)
+
+	sender, err := pcOffer.AddTrack(track)
+	assert.NoError(t, err)
+	assert.Equal(t, mediaStreamTrack.Get("id").String(), sender.Track().ID())
+
+	onTrack := make(chan *TrackRemote, 1)
+	pcAnswer.OnTrack(func(track *TrackRemote, receiver *RTPReceiver) {
+		assert.Equal(t, track.ID(), receiver.Track().ID())
+		onTrack <- track
+	})
+
+	assert.NoError(t, signalPair(pcOffer, pcAnswer))
+
+	remoteTrack := <-onTrack
+	assert.Equal(t, RTPCodecTypeAudio, remoteTrack.Kind())
+	assert.NotEmpty(t, remoteTrack.StreamID())
+	assert.Len(t, pcOffer.GetSenders(), 1)
+	assert.Len(t, pcAnswer.GetReceivers(), 1)
+
+	hasPeerConnectionStats := false
+	for _, stats := range pcOffer.GetStats() {
+		if _, ok := stats.(PeerConnectionStats); ok {
+			hasPeerConnectionStats = true
+		}
+	}
+	assert.True(t, hasPeerConnectionStats)
+
+	assert.NoError(t, sender.ReplaceTrack(nil))
+	assert.Nil(t, sender.Track())
+	assert.NoError(t, pcOffer.RemoveTrack(sender))
+
+	closePairNow(t, pcOffer, pcAnswer)
+}
+
+// notJSTrackLocal is a TrackLocal that is not backed by a MediaStreamTrack
+type notJSTrackLocal struct{}
+
+func (s *notJSTrackLocal) Bind(TrackLocalContext) (RTPCodecParameters, error) {
+	return RTPCodecParameters{}, nil
+}
+func (s *notJSTrackLocal) Unbind(TrackLocalContext) error { return nil }
+func (s *notJSTrackLocal) ID() string                     { return "audio" }
+func (s *notJSTrackLocal) RID() string                    { return "" }
+func (s *notJSTrackLocal) StreamID() string               { return "pion" }
+func (s *notJSTrackLocal) Kind() RTPCodecType             { return RTPCodecTypeAudio }
@@ -15,6 +15,10 @@ import (
 	"testing"
 	"time"
 
+	"github.com/pion/interceptor"
+	"github.com/pion/interceptor/pkg/cc"
+	mock_interceptor "github.com/pion/interceptor/pkg/mock"
+	"github.com/pion/interceptor/pkg/twcc"
 	"github.com/pion/logging"
 	"github.com/pion/randutil"
 	"github.com/pion/rtcp"
@@ -1336,3 +1340,405 @@ func TestPeerConnection_Simulcast(t *testing.T) {
 		closePairNow(t, pcOffer, pcAnswer)
 	})
 }
+
+type fakeBandwidthEstimator struct {
+	mu              sync.Mutex
+	onTargetBitrate func(int)
+}
+
+func (f *fakeBandwidthEstimator) AddStream(_ *interceptor.StreamInfo, writer interceptor.RTPWriter) interceptor.RTPWriter {
+	return writer
+}
+func (f *fakeBandwidthEstimator) WriteRTCP([]rtcp.Packet, interceptor.Attributes) error { return nil }
+func (f *fakeBandwidthEstimator) GetTargetBitrate() int                                 { return 100_000 }
+func (f *fakeBandwidthEstimator) GetStats() map[string]interface{}                      { return nil }
+func (f *fakeBandwidthEstimator) Close() error                                          { return nil }
+
+func (f *fakeBandwidthEstimator) OnTargetBitrateChange(onTargetBitrate func(int)) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.onTargetBitrate = onTargetBitrate
+}
+
+func (f *fakeBandwidthEstimator) setTargetBitrate(bitrate int) {
+	f.mu.Lock()
+	onTargetBitrate := f.onTargetBitrate
+	f.mu.Unlock()
+	onTargetBitrate(bitrate)
+}
+
+type bitrateTrackLocal struct {
+	*TrackLocalStaticSample
+	bound       chan TrackLocalContext
+	bitrateChan chan int
+}
+
+func (b *bitrateTrackLocal) Bind(t TrackLocalContext) (RTPCodecParameters, error) {
+	t.OnTargetBitrateChange(func(bitrate int) {
+		b.bitrateChan <- bitrate
+	})
+	b.bound <- t
+	return b.TrackLocalStaticSample.Bind(t)
+}
+
+// Assert that the estimates of the BandwidthEstimator are delivered to the
+// PeerConnection and to the TrackLocals
+func TestPeerConnection_BandwidthEstimation(t *testing.T) {
+	lim := test.TimeOut(time.Second * 30)
+	defer lim.Stop()
+
+	report := test.CheckRoutines(t)
+	defer report()
+
+	estimator := &fakeBandwidthEstimator{}
+	s := SettingEngine{}
+	s.EnableBandwidthEstimation(func() (cc.BandwidthEstimator, error) {
+		return estimator, nil
+	})
+
+	m := &MediaEngine{}
+	assert.NoError(t, m.RegisterDefaultCodecs())
+
+	pcOffer, err := NewAPI(WithMediaEngine(m), WithSettingEngine(s)).NewPeerConnection(Configuration{})
+	assert.NoError(t, err)
+
+	pcAnswer, err := NewAPI(WithMediaEngine(m)).NewPeerConnection(Configuration{})
+	assert.NoError(t, err)
+
+	sample, err := NewTrackLocalStaticSample(RTPCodecCapability{MimeType: MimeTypeVP8}, "video", "pion")
+	assert.NoError(t, err)
+
+	track := &bitrateTrackLocal{TrackLocalStaticSample: sample, bound: make(chan TrackLocalContext, 1), bitrateChan: make(chan int, 1)}
+	_, err = pcOffer.AddTrack(track)
+	assert.NoError(t, err)
+
+	offer, err := pcOffer.CreateOffer(nil)
+	assert.NoError(t, err)
+	assert.Contains(t, offer.SDP, sdp.TransportCCURI)
+
+	estimateChan := make(chan int, 1)
+	pcOffer.OnBandwidthEstimate(func(bitrate int) {
+		estimateChan <- bitrate
+	})
+
+	assert.NoError(t, signalPair(pcOffer, pcAnswer))
+
+	trackContext := <-track.bound
+	assert.Equal(t, 100_000, trackContext.TargetBitrate())
+
+	estimator.setTargetBitrate(500_000)
+	assert.Equal(t, 500_000, <-estimateChan)
+	assert.Equal(t, 500_000, <-track.bitrateChan)
+	assert.Equal(t, 500_000, trackContext.TargetBitrate())
+
+	closePairNow(t, pcOffer, pcAnswer)
+}
+
+// Assert that lost packets are recovered with FlexFEC and ULPFEC. Every other media packet
+// is dropped by the receiver before the FEC is processed, with an overhead of 50% each
+// one of them can be recovered. The sender adds the TWCC header extension in an interceptor,
+// the recovered packets have to be the ones that were sent, extension included.
+func TestPeerConnection_FEC(t *testing.T) {
+	lim := test.TimeOut(time.Second * 30)
+	defer lim.Stop()
+
+	report := test.CheckRoutines(t)
+	defer report()
+
+	const (
+		vp8PayloadType = 96
+		redPayloadType = 117
+		recoveredRun   = 20
+	)
+
+	for name, fecCodecs := range map[string][]RTPCodecParameters{
+		"FlexFEC03": {
+			{RTPCodecCapability: RTPCodecCapability{MimeTypeFlexFEC03, 90000, 0, "repair-window=10000000", nil}, PayloadType: 119},
+		},
+		"ULPFEC": {
+			{RTPCodecCapability: RTPCodecCapability{MimeTypeRED, 90000, 0, "", nil}, PayloadType: redPayloadType},
+			{RTPCodecCapability: RTPCodecCapability{MimeTypeULPFEC, 90000, 0, "", nil}, PayloadType: 116},
+		},
+	} {
+		fecCodecs := fecCodecs
+		t.Run(name, func(t *testing.T) {
+			createAPI := func(s SettingEngine, ir *interceptor.Registry) *API {
+				m := &MediaEngine{}
+				assert.NoError(t, m.RegisterCodec(RTPCodecParameters{
+					RTPCodecCapability: RTPCodecCapability{MimeTypeVP8, 90000, 0, "", nil},
+					PayloadType:        vp8PayloadType,
+				}, RTPCodecTypeVideo))
+				for _, codec := range fecCodecs {
+					assert.NoError(t, m.RegisterCodec(codec, RTPCodecTypeVideo))
+				}
+				assert.NoError(t, m.RegisterHeaderExtension(RTPHeaderExtensionCapability{URI: sdp.TransportCCURI}, RTPCodecTypeVideo))
+
+				return NewAPI(WithMediaEngine(m), WithSettingEngine(s), WithInterceptorRegistry(ir))
+			}
+
+			s := SettingEngine{}
+			s.SetFECOverhead(50)
+
+			headerExtension, err := twcc.NewHeaderExtensionInterceptor()
+			assert.NoError(t, err)
+
+			ir := &interceptor.Registry{}
+			ir.Add(headerExtension)
+
+			dropIR := &interceptor.Registry{}
+			dropIR.Add(&mock_interceptor.Factory{
+				NewInterceptorFn: func(_ string) (interceptor.Interceptor, error) {
+					return &mock_interceptor.Interceptor{
+						BindRemoteStreamFn: func(info *interceptor.StreamInfo, reader interceptor.RTPReader) interceptor.RTPReader {
+							mediaPackets := 0
+							return interceptor.RTPReaderFunc(func(b []byte, a interceptor.Attributes) (int, interceptor.Attributes, error) {
+								for {
+									n, attributes, readErr := reader.Read(b, a)
+									if readErr != nil {
+										return n, attributes, readErr
+									}
+
+									packet := &rtp.Packet{}
+									if readErr = packet.Unmarshal(b[:n]); readErr != nil {
+										return n, attributes, readErr
+									}
+
+									isMedia := packet.PayloadType == vp8PayloadType ||
+										(packet.PayloadType == redPayloadType && packet.Payload[0]&rtpPayloadTypeBitmask == vp8PayloadType)
+									if !isMedia {
+										return n, attributes, nil
+									} else if mediaPackets++; mediaPackets%2 == 1 {
+										return n, attributes, nil
+									}
+								}
+							})
+						},
+					}, nil
+				},
+			})
+
+			pcOffer, err := createAPI(s, ir).NewPeerConnection(Configuration{})
+			assert.NoError(t, err)
+
+			pcAnswer, err := createAPI(SettingEngine{}, dropIR).NewPeerConnection(Configuration{})
+			assert.NoError(t, err)
+
+			track, err := NewTrackLocalStaticRTP(RTPCodecCapability{MimeType: MimeTypeVP8}, "video", "pion")
+			assert.NoError(t, err)
+
+			_, err = pcOffer.AddTrack(track)
+			assert.NoError(t, err)
+
+			recovered, recoveredCancel := context.WithCancel(context.Background())
+			pcAnswer.OnTrack(func(trackRemote *TrackRemote, receiver *RTPReceiver) {
+				assert.Equal(t, PayloadType(vp8PayloadType), trackRemote.PayloadType())
+
+				var transportCCID uint8
+				for _, extension := range receiver.GetParameters().HeaderExtensions {
+					if extension.URI == sdp.TransportCCURI {
+						transportCCID = uint8(extension.ID)
+					}
+				}
+
+				// Without recovery only every other packet is read
+				seen := map[uint16]bool{}
+				for {
+					pkt, _, readErr := trackRemote.ReadRTP()
+					if readErr != nil {
+						return
+					}
+					assert.Equal(t, uint8(vp8PayloadType), pkt.PayloadType)
+					assert.NotNil(t, pkt.GetExtension(transportCCID))
+
+					index := uint16(pkt.Payload[0])<<8 | uint16(pkt.Payload[1])
+					seen[index] = true
+
+					run := 0
+					for run < recoveredRun && seen[index-uint16(run)] {
+						run++
+					}
+					if run == recoveredRun {
+						recoveredCancel()
+						return
+					}
+				}
+			})
+
+			assert.NoError(t, signalPair(pcOffer, pcAnswer))
+
+			func() {
+				ticker := time.NewTicker(time.Millisecond * 5)
+				defer ticker.Stop()
+				for index := uint16(0); ; index++ {
+					select {
+					case <-recovered.Done():
+						return
+					case <-ticker.C:
+						assert.NoError(t, track.WriteRTP(&rtp.Packet{
+							Header: rtp.Header{
+								Version:        2,
+								SequenceNumber: index,
+								Timestamp:      uint32(index) * 3000,
+							},
+							Payload: []byte{byte(index >> 8), byte(index), 0x00, 0x00},
+						}))
+					}
+				}
+			}()
+
+			closePairNow(t, pcOffer, pcAnswer)
+		})
+	}
+}
+
+// Assert that when the media is sent inside of RED for ULPFEC, its retransmissions
+// use the RTX codec associated with RED
+func TestPeerConnection_FEC_RTX(t *testing.T) {
+	lim := test.TimeOut(time.Second * 30)
+	defer lim.Stop()
+
+	report := test.CheckRoutines(t)
+	defer report()
+
+	m := &MediaEngine{}
+	for _, codec := range []RTPCodecParameters{
+		{RTPCodecCapability: RTPCodecCapability{MimeTypeVP8, 90000, 0, "", []RTCPFeedback{{"nack", ""}}}, PayloadType: 96},
+		{RTPCodecCapability: RTPCodecCapability{MimeTypeRTX, 90000, 0, "apt=96", nil}, PayloadType: 97},
+		{RTPCodecCapability: RTPCodecCapability{MimeTypeRED, 90000, 0, "", nil}, PayloadType: 117},
+		{RTPCodecCapability: RTPCodecCapability{MimeTypeRTX, 90000, 0, "apt=117", nil}, PayloadType: 106},
+		{RTPCodecCapability: RTPCodecCapability{MimeTypeULPFEC, 90000, 0, "", nil}, PayloadType: 116},
+	} {
+		assert.NoError(t, m.RegisterCodec(codec, RTPCodecTypeVideo))
+	}
+
+	s := SettingEngine{}
+	s.SetFECOverhead(50)
+
+	streamInfos := make(chan *interceptor.StreamInfo, 1)
+	ir := &interceptor.Registry{}
+	ir.Add(&mock_interceptor.Factory{
+		NewInterceptorFn: func(_ string) (interceptor.Interceptor, error) {
+			return &mock_interceptor.Interceptor{
+				BindLocalStreamFn: func(info *interceptor.StreamInfo, writer interceptor.RTPWriter) interceptor.RTPWriter {
+					streamInfos <- info
+					return writer
+				},
+			}, nil
+		},
+	})
+
+	pcOffer, err := NewAPI(WithMediaEngine(m), WithSettingEngine(s), WithInterceptorRegistry(ir)).NewPeerConnection(Configuration{})
+	assert.NoError(t, err)
+
+	pcAnswer, err := NewAPI(WithMediaEngine(m)).NewPeerConnection(Configuration{})
+	assert.NoError(t, err)
+
+	track, err := NewTrackLocalStaticRTP(RTPCodecCapability{MimeType: MimeTypeVP8}, "video", "pion")
+	assert.NoError(t, err)
+
+	sender, err := pcOffer.AddTrack(track)
+	assert.NoError(t, err)
+
+	assert.NoError(t, signalPair(pcOffer, pcAnswer))
+
+	info := <-streamInfos
+	assert.Equal(t, sender.GetParameters().Encodings[0].RTX.SSRC, info.Attributes[rtxSSRCAttribute{}])
+	assert.Equal(t, PayloadType(106), info.Attributes[rtxPayloadTypeAttribute{}])
+
+	closePairNow(t, pcOffer, pcAnswer)
+}
+
+func TestPeerConnection_OpusRED(t *testing.T) {
+	lim := test.TimeOut(time.Second * 30)
+	defer lim.Stop()
+
+	report := test.CheckRoutines(t)
+	defer report()
+
+	const (
+		opusPayloadType = 111
+		redPayloadType  = 63
+		recoveredRun    = 20
+	)
+
+	m := &MediaEngine{}
+	assert.NoError(t, m.RegisterDefaultCodecs())
+
+	// Only every third packet makes it, the two lost before it are carried as redundancy
+	ir := &interceptor.Registry{}
+	ir.Add(&mock_interceptor.Factory{
+		NewInterceptorFn: func(_ string) (interceptor.Interceptor, error) {
+			return &mock_interceptor.Interceptor{
+				BindLocalStreamFn: func(_ *interceptor.StreamInfo, writer interceptor.RTPWriter) interceptor.RTPWriter {
+					packets := 0
+					return interceptor.RTPWriterFunc(func(header *rtp.Header, payload []byte, attributes interceptor.Attributes) (int, error) {
+						if header.PayloadType != redPayloadType {
+							return writer.Write(header, payload, attributes)
+						}
+
+						if packets++; packets%3 != 0 {
+							return len(payload), nil
+						}
+						return writer.Write(header, payload, attributes)
+					})
+				},
+			}, nil
+		},
+	})
+
+	pcOffer, err := NewAPI(WithMediaEngine(m), WithInterceptorRegistry(ir)).NewPeerConnection(Configuration{})
+	assert.NoError(t, err)
+
+	pcAnswer, err := NewPeerConnection(Configuration{})
+	assert.NoError(t, err)
+
+	track, err := NewTrackLocalStaticSample(RTPCodecCapability{MimeType: MimeTypeOpus}, "audio", "pion", WithOpusRED(2))
+	assert.NoError(t, err)
+
+	_, err = pcOffer.AddTrack(track)
+	assert.NoError(t, err)
+
+	recovered, recoveredCancel := context.WithCancel(context.Background())
+	pcAnswer.OnTrack(func(trackRemote *TrackRemote, _ *RTPReceiver) {
+		assert.Equal(t, PayloadType(opusPayloadType), trackRemote.PayloadType())
+
+		var last *rtp.Packet
+		run := 0
+		for {
+			pkt, _, readErr := trackRemote.ReadRTP()
+			if readErr != nil {
+				return
+			}
+			assert.Equal(t, uint8(opusPayloadType), pkt.PayloadType)
+
+			if last != nil {
+				assert.Equal(t, last.SequenceNumber+1, pkt.SequenceNumber)
+				assert.Equal(t, last.Timestamp+960, pkt.Timestamp)
+				assert.Equal(t, last.Payload[0]+1, pkt.Payload[0])
+			}
+			last = pkt
+
+			if run++; run == recoveredRun {
+				recoveredCancel()
+				return
+			}
+		}
+	})
+
+	assert.NoError(t, signalPair(pcOffer, pcAnswer))
+
+	func() {
+		ticker := time.NewTicker(time.Millisecond * 20)
+		defer ticker.Stop()
+		for index := byte(0); ; index++ {
+			select {
+			case <-recovered.Done():
+				return
+			case <-ticker.C:
+				assert.NoError(t, track.WriteSample(media.Sample{Data: []byte{index, 0x00, 0x00}, Duration: time.Millisecond * 20}))
+			}
+		}
+	}()
+
+	closePairNow(t, pcOffer, pcAnswer)
+}
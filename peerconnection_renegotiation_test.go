@@ -1233,3 +1233,69 @@ func TestPeerConnection_Renegotiation_MidConflict(t *testing.T) {
 	assert.NoError(t, offerPC.Close())
 	assert.NoError(t, answerPC.Close())
 }
+
+// Assert that rolling back a remote offer removes the transceivers it created,
+// so a glare can be resolved by the other side and the session renegotiated
+func TestPeerConnection_Renegotiation_RemoteRollback(t *testing.T) {
+	lim := test.TimeOut(time.Second * 30)
+	defer lim.Stop()
+
+	report := test.CheckRoutines(t)
+	defer report()
+
+	pcA, pcB, err := newPair()
+	assert.NoError(t, err)
+
+	connected := untilConnectionState(PeerConnectionStateConnected, pcA, pcB)
+	assert.NoError(t, signalPair(pcA, pcB))
+	connected.Wait()
+
+	onTrackA, onTrackACancel := context.WithCancel(context.Background())
+	pcA.OnTrack(func(track *TrackRemote, r *RTPReceiver) {
+		onTrackACancel()
+	})
+	onTrackB, onTrackBCancel := context.WithCancel(context.Background())
+	pcB.OnTrack(func(track *TrackRemote, r *RTPReceiver) {
+		onTrackBCancel()
+	})
+
+	videoTrack, err := NewTrackLocalStaticSample(RTPCodecCapability{MimeType: MimeTypeVP8}, "video", "pionA")
+	assert.NoError(t, err)
+	_, err = pcA.AddTrack(videoTrack)
+	assert.NoError(t, err)
+
+	audioTrack, err := NewTrackLocalStaticSample(RTPCodecCapability{MimeType: MimeTypeOpus}, "audio", "pionB")
+	assert.NoError(t, err)
+	_, err = pcB.AddTrack(audioTrack)
+	assert.NoError(t, err)
+
+	offerA, err := pcA.CreateOffer(nil)
+	assert.NoError(t, err)
+	assert.NoError(t, pcA.SetLocalDescription(offerA))
+
+	assert.NoError(t, pcB.SetRemoteDescription(offerA))
+	assert.Len(t, pcB.GetTransceivers(), 2)
+
+	assert.NoError(t, pcB.SetRemoteDescription(SessionDescription{Type: SDPTypeRollback}))
+	assert.Equal(t, SignalingStateStable, pcB.SignalingState())
+	assert.Len(t, pcB.GetTransceivers(), 1)
+	assert.Equal(t, RTPCodecTypeAudio, pcB.GetTransceivers()[0].Kind())
+	assert.Equal(t, "", pcB.GetTransceivers()[0].Mid())
+
+	// Glare is resolved in favour of B, A rolls its offer back and answers
+	assert.NoError(t, pcA.SetLocalDescription(SessionDescription{Type: SDPTypeRollback}))
+	assert.NoError(t, signalPair(pcB, pcA))
+	assert.Equal(t, 1, strings.Count(pcB.CurrentLocalDescription().SDP, "m=audio"))
+	assert.Equal(t, 0, strings.Count(pcB.CurrentLocalDescription().SDP, "m=video"))
+
+	// A renegotiates to send its video
+	assert.NoError(t, signalPair(pcA, pcB))
+	assert.Len(t, pcB.GetTransceivers(), 2)
+	assert.Equal(t, 1, strings.Count(pcA.CurrentLocalDescription().SDP, "m=audio"))
+	assert.Equal(t, 1, strings.Count(pcA.CurrentLocalDescription().SDP, "m=video"))
+
+	sendVideoUntilDone(onTrackA.Done(), t, []*TrackLocalStaticSample{audioTrack})
+	sendVideoUntilDone(onTrackB.Done(), t, []*TrackLocalStaticSample{videoTrack})
+
+	closePairNow(t, pcA, pcB)
+}
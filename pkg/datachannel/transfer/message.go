@@ -0,0 +1,113 @@
+package transfer
+
+import (
+	"encoding/binary"
+	"errors"
+	"hash/crc32"
+	"math"
+)
+
+var errMessageTooShort = errors.New("transfer message is too short")
+
+// messageType identifies a message of the transfer protocol. Every message starts
+// with its type and the ID of the transfer it belongs to.
+type messageType byte
+
+const (
+	// messageTypeOffer announces a transfer: size and name
+	messageTypeOffer messageType = iota + 1
+	// messageTypeAccept accepts an offer: offset the receiver already has
+	messageTypeAccept
+	// messageTypeChunk carries data: offset, CRC-32 of the data and the data
+	messageTypeChunk
+	// messageTypeAck acknowledges data: offset up to which all data was written
+	messageTypeAck
+	// messageTypeDone tells the receiver that all data was sent
+	messageTypeDone
+	// messageTypeComplete tells the sender that all data was written
+	messageTypeComplete
+	// messageTypeError aborts a transfer: the reason
+	messageTypeError
+)
+
+const (
+	messageHeaderLength = 3
+	chunkHeaderLength   = 12
+
+	// maxIDLength is the longest ID the 16 bit length of the message header can describe
+	maxIDLength = math.MaxUint16
+)
+
+type message struct {
+	typ  messageType
+	id   string
+	body []byte
+}
+
+func marshalMessage(typ messageType, id string, body []byte) []byte {
+	out := make([]byte, messageHeaderLength, messageHeaderLength+len(id)+len(body))
+	out[0] = byte(typ)
+	binary.BigEndian.PutUint16(out[1:], uint16(len(id)))
+	out = append(out, id...)
+	return append(out, body...)
+}
+
+func unmarshalMessage(raw []byte) (message, error) {
+	if len(raw) < messageHeaderLength {
+		return message{}, errMessageTooShort
+	}
+
+	idLength := int(binary.BigEndian.Uint16(raw[1:]))
+	if len(raw) < messageHeaderLength+idLength {
+		return message{}, errMessageTooShort
+	}
+
+	return message{
+		typ:  messageType(raw[0]),
+		id:   string(raw[messageHeaderLength : messageHeaderLength+idLength]),
+		body: raw[messageHeaderLength+idLength:],
+	}, nil
+}
+
+func marshalUint64(v uint64) []byte {
+	out := make([]byte, 8)
+	binary.BigEndian.PutUint64(out, v)
+	return out
+}
+
+func unmarshalUint64(body []byte) (uint64, error) {
+	if len(body) < 8 {
+		return 0, errMessageTooShort
+	}
+	return binary.BigEndian.Uint64(body), nil
+}
+
+func marshalOffer(size uint64, name string) []byte {
+	return append(marshalUint64(size), name...)
+}
+
+func unmarshalOffer(body []byte) (size uint64, name string, err error) {
+	if size, err = unmarshalUint64(body); err != nil {
+		return 0, "", err
+	}
+	return size, string(body[8:]), nil
+}
+
+func marshalChunk(offset uint64, data []byte) []byte {
+	out := make([]byte, chunkHeaderLength, chunkHeaderLength+len(data))
+	binary.BigEndian.PutUint64(out, offset)
+	binary.BigEndian.PutUint32(out[8:], crc32.ChecksumIEEE(data))
+	return append(out, data...)
+}
+
+func unmarshalChunk(body []byte) (offset uint64, data []byte, err error) {
+	if len(body) < chunkHeaderLength {
+		return 0, nil, errMessageTooShort
+	}
+
+	data = body[chunkHeaderLength:]
+	if crc32.ChecksumIEEE(data) != binary.BigEndian.Uint32(body[8:]) {
+		return 0, nil, errChecksumMismatch
+	}
+	return binary.BigEndian.Uint64(body), data, nil
+}
@@ -0,0 +1,469 @@
+// Package transfer implements reliable file transfer over a DataChannel
+//
+// Both peers create a Session on the same DataChannel. A transfer is identified by an
+// ID chosen by the sender. If a transfer is interrupted, e.g. because the PeerConnection
+// failed, it is resumed by sending it again with the same ID, on a new Session if needed.
+// The receiver tells the sender how much of the data it already has and only the rest is sent.
+package transfer
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sync"
+
+	"github.com/pion/webrtc/v3"
+)
+
+var (
+	// ErrAborted indicates that the remote peer aborted the transfer
+	ErrAborted = errors.New("transfer aborted by the remote peer")
+
+	// ErrClosed indicates that the DataChannel closed before the transfer finished
+	ErrClosed = errors.New("DataChannel closed before the transfer finished")
+
+	errIDTooLong          = errors.New("transfer ID is longer than 65535 bytes")
+	errTransferInProgress = errors.New("a transfer with this ID is already in progress")
+	errOfferRejected      = errors.New("offer rejected, OnOffer is not set")
+	errOffsetInvalid      = errors.New("offset is beyond the size of the transfer")
+	errOffsetMismatch     = errors.New("chunk does not continue the received data")
+	errChecksumMismatch   = errors.New("chunk checksum mismatch")
+	errIncomplete         = errors.New("transfer is done before all data was received")
+)
+
+const (
+	defaultChunkSize          = 16384
+	defaultBufferedAmountHigh = 1 << 20
+	defaultBufferedAmountLow  = 1 << 18
+)
+
+// Channel is the part of a webrtc.DataChannel a Session uses
+type Channel interface {
+	Send(data []byte) error
+	OnMessage(f func(msg webrtc.DataChannelMessage))
+	BufferedAmount() uint64
+	SetBufferedAmountLowThreshold(th uint64)
+	OnBufferedAmountLow(f func())
+	OnClose(f func())
+}
+
+// Metadata describes a transfer
+type Metadata struct {
+	ID   string
+	Name string
+	Size uint64
+}
+
+// Progress is the state of a transfer. For a sent transfer Transferred is the amount
+// of data the receiver acknowledged, for a received transfer the amount of data written.
+type Progress struct {
+	Metadata
+	Transferred uint64
+}
+
+// Config configures a Session
+type Config struct {
+	// OnOffer is called when the remote peer offers a transfer. It returns where the
+	// data is written, and how much of it is already there from an earlier attempt
+	// of the transfer with the same ID. The offer is rejected if OnOffer is nil or
+	// returns an error.
+	OnOffer func(Metadata) (w io.WriterAt, offset uint64, err error)
+
+	// OnReceived is called when a transfer offered by the remote peer completed or failed
+	OnReceived func(Metadata, error)
+
+	// OnProgress is called whenever a sent transfer was acknowledged or a received
+	// transfer was written
+	OnProgress func(Progress)
+
+	// ChunkSize is the size of the data sent per message, 16 KiB by default
+	ChunkSize int
+
+	// BufferedAmountHigh and BufferedAmountLow control the flow of the sent data.
+	// Sending pauses when the BufferedAmount of the DataChannel exceeds
+	// BufferedAmountHigh and continues when it falls to BufferedAmountLow.
+	// They are 1 MiB and 256 KiB by default.
+	BufferedAmountHigh uint64
+	BufferedAmountLow  uint64
+}
+
+type outgoing struct {
+	metadata Metadata
+	accepted chan uint64
+	result   chan error
+}
+
+type incoming struct {
+	metadata Metadata
+	writer   io.WriterAt
+	offset   uint64
+}
+
+// Session sends and receives transfers over a DataChannel
+type Session struct {
+	channel Channel
+	config  Config
+
+	mu        sync.Mutex
+	sending   map[string]*outgoing
+	receiving map[string]*incoming
+	closed    bool
+
+	bufferedAmountLow chan struct{}
+}
+
+// NewSession creates a Session on channel. It takes over the OnMessage, OnBufferedAmountLow
+// and OnClose handlers of channel, they must not be set by the caller. When channel closes
+// all transfers in progress fail with ErrClosed.
+func NewSession(channel Channel, config Config) *Session {
+	if config.ChunkSize <= 0 {
+		config.ChunkSize = defaultChunkSize
+	}
+	if config.BufferedAmountHigh == 0 {
+		config.BufferedAmountHigh = defaultBufferedAmountHigh
+	}
+	if config.BufferedAmountLow == 0 || config.BufferedAmountLow > config.BufferedAmountHigh {
+		config.BufferedAmountLow = defaultBufferedAmountLow
+	}
+
+	s := &Session{
+		channel:           channel,
+		config:            config,
+		sending:           map[string]*outgoing{},
+		receiving:         map[string]*incoming{},
+		bufferedAmountLow: make(chan struct{}, 1),
+	}
+
+	channel.SetBufferedAmountLowThreshold(config.BufferedAmountLow)
+	channel.OnBufferedAmountLow(func() {
+		select {
+		case s.bufferedAmountLow <- struct{}{}:
+		default:
+		}
+	})
+	channel.OnMessage(s.handleMessage)
+	channel.OnClose(s.handleClose)
+	return s
+}
+
+// SendFile sends the file at path, the name of the file is used as name and ID of the transfer
+func (s *Session) SendFile(ctx context.Context, path string) error {
+	f, err := os.Open(path) //nolint:gosec
+	if err != nil {
+		return err
+	}
+	defer func() {
+		_ = f.Close()
+	}()
+
+	info, err := f.Stat()
+	if err != nil {
+		return err
+	}
+
+	name := filepath.Base(path)
+	return s.Send(ctx, name, name, f, uint64(info.Size()))
+}
+
+// Send sends size bytes read from r as the transfer id. It returns once the remote peer
+// has received all of them. r is read from the offset the remote peer already has.
+func (s *Session) Send(ctx context.Context, id, name string, r io.ReadSeeker, size uint64) error {
+	if len(id) > maxIDLength {
+		return errIDTooLong
+	}
+
+	t := &outgoing{
+		metadata: Metadata{ID: id, Name: name, Size: size},
+		accepted: make(chan uint64, 1),
+		result:   make(chan error, 1),
+	}
+
+	s.mu.Lock()
+	if s.closed {
+		s.mu.Unlock()
+		return ErrClosed
+	} else if _, ok := s.sending[id]; ok {
+		s.mu.Unlock()
+		return errTransferInProgress
+	}
+	s.sending[id] = t
+	s.mu.Unlock()
+
+	defer func() {
+		s.mu.Lock()
+		delete(s.sending, id)
+		s.mu.Unlock()
+	}()
+
+	err := s.sendData(ctx, t, r)
+	if err == nil {
+		select {
+		case err = <-t.result:
+		case <-ctx.Done():
+			err = ctx.Err()
+		}
+	}
+
+	if err != nil && !errors.Is(err, ErrAborted) && !errors.Is(err, ErrClosed) {
+		s.sendError(id, err)
+	}
+	return err
+}
+
+func (s *Session) sendData(ctx context.Context, t *outgoing, r io.ReadSeeker) error {
+	if err := s.send(messageTypeOffer, t.metadata.ID, marshalOffer(t.metadata.Size, t.metadata.Name)); err != nil {
+		return err
+	}
+
+	var offset uint64
+	select {
+	case offset = <-t.accepted:
+	case err := <-t.result:
+		return err
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+
+	if offset > t.metadata.Size {
+		return errOffsetInvalid
+	}
+	if _, err := r.Seek(int64(offset), io.SeekStart); err != nil {
+		return err
+	}
+
+	buf := make([]byte, s.config.ChunkSize)
+	for offset < t.metadata.Size {
+		if err := s.waitBufferedAmount(ctx, t); err != nil {
+			return err
+		}
+
+		n := uint64(len(buf))
+		if remaining := t.metadata.Size - offset; remaining < n {
+			n = remaining
+		}
+		if _, err := io.ReadFull(r, buf[:n]); err != nil {
+			return err
+		}
+		if err := s.send(messageTypeChunk, t.metadata.ID, marshalChunk(offset, buf[:n])); err != nil {
+			return err
+		}
+		offset += n
+	}
+
+	return s.send(messageTypeDone, t.metadata.ID, nil)
+}
+
+// waitBufferedAmount blocks while the DataChannel has more than BufferedAmountHigh buffered
+func (s *Session) waitBufferedAmount(ctx context.Context, t *outgoing) error {
+	for s.channel.BufferedAmount() > s.config.BufferedAmountHigh {
+		select {
+		case <-s.bufferedAmountLow:
+		case err := <-t.result:
+			return err
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+	return nil
+}
+
+func (s *Session) send(typ messageType, id string, body []byte) error {
+	return s.channel.Send(marshalMessage(typ, id, body))
+}
+
+func (s *Session) sendError(id string, err error) {
+	_ = s.send(messageTypeError, id, []byte(err.Error()))
+}
+
+func (s *Session) handleMessage(msg webrtc.DataChannelMessage) {
+	m, err := unmarshalMessage(msg.Data)
+	if err != nil {
+		return
+	}
+
+	switch m.typ {
+	case messageTypeOffer:
+		s.handleOffer(m)
+	case messageTypeChunk:
+		s.handleChunk(m)
+	case messageTypeDone:
+		s.handleDone(m)
+	case messageTypeAccept, messageTypeAck, messageTypeComplete:
+		s.handleReply(m)
+	case messageTypeError:
+		s.handleError(m)
+	}
+}
+
+func (s *Session) handleOffer(m message) {
+	size, name, err := unmarshalOffer(m.body)
+	if err != nil {
+		return
+	}
+	metadata := Metadata{ID: m.id, Name: name, Size: size}
+
+	if s.config.OnOffer == nil {
+		s.sendError(m.id, errOfferRejected)
+		return
+	}
+	writer, offset, err := s.config.OnOffer(metadata)
+	if err == nil && offset > size {
+		err = errOffsetInvalid
+	}
+	if err != nil {
+		s.sendError(m.id, err)
+		return
+	}
+
+	s.mu.Lock()
+	s.receiving[m.id] = &incoming{metadata: metadata, writer: writer, offset: offset}
+	s.mu.Unlock()
+
+	_ = s.send(messageTypeAccept, m.id, marshalUint64(offset))
+}
+
+func (s *Session) getIncoming(id string) *incoming {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.receiving[id]
+}
+
+// failIncoming aborts a received transfer, and tells the remote peer if err is local
+func (s *Session) failIncoming(t *incoming, err error) {
+	s.mu.Lock()
+	delete(s.receiving, t.metadata.ID)
+	s.mu.Unlock()
+
+	if !errors.Is(err, ErrAborted) {
+		s.sendError(t.metadata.ID, err)
+	}
+	if s.config.OnReceived != nil {
+		s.config.OnReceived(t.metadata, err)
+	}
+}
+
+func (s *Session) handleChunk(m message) {
+	t := s.getIncoming(m.id)
+	if t == nil {
+		return
+	}
+
+	offset, data, err := unmarshalChunk(m.body)
+	if err == nil && offset != t.offset {
+		err = errOffsetMismatch
+	}
+	if err == nil && offset+uint64(len(data)) > t.metadata.Size {
+		err = errOffsetInvalid
+	}
+	if err == nil {
+		_, err = t.writer.WriteAt(data, int64(offset))
+	}
+	if err != nil {
+		s.failIncoming(t, err)
+		return
+	}
+
+	t.offset += uint64(len(data))
+	_ = s.send(messageTypeAck, m.id, marshalUint64(t.offset))
+
+	if s.config.OnProgress != nil {
+		s.config.OnProgress(Progress{Metadata: t.metadata, Transferred: t.offset})
+	}
+}
+
+func (s *Session) handleDone(m message) {
+	t := s.getIncoming(m.id)
+	if t == nil {
+		return
+	}
+
+	if t.offset != t.metadata.Size {
+		s.failIncoming(t, errIncomplete)
+		return
+	}
+
+	s.mu.Lock()
+	delete(s.receiving, m.id)
+	s.mu.Unlock()
+
+	_ = s.send(messageTypeComplete, m.id, nil)
+	if s.config.OnReceived != nil {
+		s.config.OnReceived(t.metadata, nil)
+	}
+}
+
+// handleReply handles the replies of the receiver of a sent transfer
+func (s *Session) handleReply(m message) {
+	s.mu.Lock()
+	t := s.sending[m.id]
+	s.mu.Unlock()
+	if t == nil {
+		return
+	}
+
+	switch m.typ {
+	case messageTypeAccept:
+		if offset, err := unmarshalUint64(m.body); err == nil {
+			select {
+			case t.accepted <- offset:
+			default:
+			}
+		}
+	case messageTypeAck:
+		if offset, err := unmarshalUint64(m.body); err == nil && s.config.OnProgress != nil {
+			s.config.OnProgress(Progress{Metadata: t.metadata, Transferred: offset})
+		}
+	default:
+		t.finish(nil)
+	}
+}
+
+func (s *Session) handleError(m message) {
+	err := fmt.Errorf("%w: %s", ErrAborted, m.body)
+
+	s.mu.Lock()
+	sent := s.sending[m.id]
+	s.mu.Unlock()
+	if sent != nil {
+		sent.finish(err)
+	}
+
+	if received := s.getIncoming(m.id); received != nil {
+		s.failIncoming(received, err)
+	}
+}
+
+// handleClose fails the transfers in progress when the DataChannel closes
+func (s *Session) handleClose() {
+	s.mu.Lock()
+	s.closed = true
+	sending := make([]*outgoing, 0, len(s.sending))
+	for _, t := range s.sending {
+		sending = append(sending, t)
+	}
+	receiving := make([]*incoming, 0, len(s.receiving))
+	for _, t := range s.receiving {
+		receiving = append(receiving, t)
+	}
+	s.receiving = map[string]*incoming{}
+	s.mu.Unlock()
+
+	for _, t := range sending {
+		t.finish(ErrClosed)
+	}
+	if s.config.OnReceived != nil {
+		for _, t := range receiving {
+			s.config.OnReceived(t.metadata, ErrClosed)
+		}
+	}
+}
+
+func (t *outgoing) finish(err error) {
+	select {
+	case t.result <- err:
+	default:
+	}
+}
@@ -0,0 +1,270 @@
+//go:build !js
+// +build !js
+
+package transfer
+
+import (
+	"bytes"
+	"context"
+	"crypto/rand"
+	"io"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/pion/logging"
+	"github.com/pion/transport/test"
+	"github.com/pion/transport/vnet"
+	"github.com/pion/webrtc/v3"
+	"github.com/stretchr/testify/assert"
+)
+
+// memoryFile is an io.WriterAt that keeps the received data across attempts
+type memoryFile struct {
+	mu   sync.Mutex
+	data []byte
+}
+
+func (f *memoryFile) WriteAt(p []byte, off int64) (int, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if end := int(off) + len(p); end > len(f.data) {
+		f.data = append(f.data, make([]byte, end-len(f.data))...)
+	}
+	return copy(f.data[off:], p), nil
+}
+
+func (f *memoryFile) size() uint64 {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return uint64(len(f.data))
+}
+
+func newVNetPair(t *testing.T) (*webrtc.PeerConnection, *webrtc.PeerConnection, *vnet.Router) {
+	wan, err := vnet.NewRouter(&vnet.RouterConfig{
+		CIDR:          "1.2.3.0/24",
+		LoggerFactory: logging.NewDefaultLoggerFactory(),
+	})
+	assert.NoError(t, err)
+
+	var peers []*webrtc.PeerConnection
+	for _, ip := range []string{"1.2.3.4", "1.2.3.5"} {
+		net := vnet.NewNet(&vnet.NetConfig{StaticIPs: []string{ip}})
+		assert.NoError(t, wan.AddNet(net))
+
+		settingEngine := webrtc.SettingEngine{}
+		settingEngine.SetVNet(net)
+
+		pc, pcErr := webrtc.NewAPI(webrtc.WithSettingEngine(settingEngine)).NewPeerConnection(webrtc.Configuration{})
+		assert.NoError(t, pcErr)
+		peers = append(peers, pc)
+	}
+	assert.NoError(t, wan.Start())
+
+	return peers[0], peers[1], wan
+}
+
+// connect negotiates a DataChannel between the peers and waits until it is open on both
+func connect(t *testing.T, offerPC, answerPC *webrtc.PeerConnection) (*webrtc.DataChannel, *webrtc.DataChannel) {
+	var opened sync.WaitGroup
+	opened.Add(2)
+
+	offerDC, err := offerPC.CreateDataChannel("transfer", nil)
+	assert.NoError(t, err)
+	offerDC.OnOpen(opened.Done)
+
+	answerDCs := make(chan *webrtc.DataChannel, 1)
+	answerPC.OnDataChannel(func(d *webrtc.DataChannel) {
+		d.OnOpen(opened.Done)
+		answerDCs <- d
+	})
+
+	offer, err := offerPC.CreateOffer(nil)
+	assert.NoError(t, err)
+	offerGatheringComplete := webrtc.GatheringCompletePromise(offerPC)
+	assert.NoError(t, offerPC.SetLocalDescription(offer))
+	<-offerGatheringComplete
+	assert.NoError(t, answerPC.SetRemoteDescription(*offerPC.LocalDescription()))
+
+	answer, err := answerPC.CreateAnswer(nil)
+	assert.NoError(t, err)
+	answerGatheringComplete := webrtc.GatheringCompletePromise(answerPC)
+	assert.NoError(t, answerPC.SetLocalDescription(answer))
+	<-answerGatheringComplete
+	assert.NoError(t, offerPC.SetRemoteDescription(*answerPC.LocalDescription()))
+
+	answerDC := <-answerDCs
+	opened.Wait()
+	return offerDC, answerDC
+}
+
+func closePair(t *testing.T, offerPC, answerPC *webrtc.PeerConnection, wan *vnet.Router) {
+	assert.NoError(t, offerPC.Close())
+	assert.NoError(t, answerPC.Close())
+	assert.NoError(t, wan.Stop())
+}
+
+func TestSession_Resume(t *testing.T) {
+	lim := test.TimeOut(time.Second * 60)
+	defer lim.Stop()
+
+	report := test.CheckRoutines(t)
+	defer report()
+
+	data := make([]byte, 4<<20)
+	_, err := rand.Read(data)
+	assert.NoError(t, err)
+	received := &memoryFile{}
+
+	var offsets []uint64
+	onOffer := func(m Metadata) (io.WriterAt, uint64, error) {
+		assert.Equal(t, Metadata{ID: "id", Name: "name", Size: uint64(len(data))}, m)
+		offsets = append(offsets, received.size())
+		return received, received.size(), nil
+	}
+
+	// The first attempt is interrupted after 1 MiB by closing the PeerConnections,
+	// the receiver doesn't handle any more data until they are closed
+	offerPC, answerPC, wan := newVNetPair(t)
+	offerDC, answerDC := connect(t, offerPC, answerPC)
+
+	interrupted, closed := make(chan struct{}), make(chan struct{})
+	var interruptOnce sync.Once
+	NewSession(answerDC, Config{
+		OnOffer: onOffer,
+		OnProgress: func(p Progress) {
+			if p.Transferred >= 1<<20 {
+				interruptOnce.Do(func() {
+					close(interrupted)
+					<-closed
+				})
+			}
+		},
+	})
+	sender := NewSession(offerDC, Config{})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	sendErr := make(chan error)
+	go func() {
+		sendErr <- sender.Send(ctx, "id", "name", bytes.NewReader(data), uint64(len(data)))
+	}()
+
+	<-interrupted
+	cancel()
+	closePair(t, offerPC, answerPC, wan)
+	close(closed)
+	assert.Error(t, <-sendErr)
+
+	// The second attempt on new PeerConnections only sends the rest
+	offerPC, answerPC, wan = newVNetPair(t)
+	offerDC, answerDC = connect(t, offerPC, answerPC)
+
+	receivedResult := make(chan error, 1)
+	NewSession(answerDC, Config{
+		OnOffer: onOffer,
+		OnReceived: func(m Metadata, err error) {
+			receivedResult <- err
+		},
+	})
+
+	var acknowledged uint64
+	sender = NewSession(offerDC, Config{
+		OnProgress: func(p Progress) {
+			acknowledged = p.Transferred
+		},
+	})
+	assert.NoError(t, sender.Send(context.Background(), "id", "name", bytes.NewReader(data), uint64(len(data))))
+	assert.NoError(t, <-receivedResult)
+
+	assert.Equal(t, uint64(len(data)), acknowledged)
+	assert.Len(t, offsets, 2)
+	assert.Equal(t, uint64(0), offsets[0])
+	assert.GreaterOrEqual(t, offsets[1], uint64(1<<20))
+	assert.Equal(t, data, received.data)
+
+	closePair(t, offerPC, answerPC, wan)
+}
+
+func TestSession_Rejected(t *testing.T) {
+	lim := test.TimeOut(time.Second * 30)
+	defer lim.Stop()
+
+	report := test.CheckRoutines(t)
+	defer report()
+
+	offerPC, answerPC, wan := newVNetPair(t)
+	offerDC, answerDC := connect(t, offerPC, answerPC)
+
+	NewSession(answerDC, Config{})
+	sender := NewSession(offerDC, Config{})
+
+	err := sender.Send(context.Background(), "id", "name", bytes.NewReader([]byte{1, 2, 3}), 3)
+	assert.ErrorIs(t, err, ErrAborted)
+
+	closePair(t, offerPC, answerPC, wan)
+}
+
+func TestSession_Closed(t *testing.T) {
+	lim := test.TimeOut(time.Second * 30)
+	defer lim.Stop()
+
+	report := test.CheckRoutines(t)
+	defer report()
+
+	data := make([]byte, 4<<20)
+	_, err := rand.Read(data)
+	assert.NoError(t, err)
+
+	offerPC, answerPC, wan := newVNetPair(t)
+	offerDC, answerDC := connect(t, offerPC, answerPC)
+
+	// The receiver closes the DataChannel after 1 MiB
+	progressed := make(chan struct{})
+	var progressOnce sync.Once
+	receivedResult := make(chan error, 1)
+	receiver := NewSession(answerDC, Config{
+		OnOffer: func(Metadata) (io.WriterAt, uint64, error) {
+			return &memoryFile{}, 0, nil
+		},
+		OnReceived: func(m Metadata, err error) {
+			receivedResult <- err
+		},
+		OnProgress: func(p Progress) {
+			if p.Transferred >= 1<<20 {
+				progressOnce.Do(func() {
+					close(progressed)
+				})
+			}
+		},
+	})
+	sender := NewSession(offerDC, Config{})
+
+	sendErr := make(chan error)
+	go func() {
+		sendErr <- sender.Send(context.Background(), "id", "name", bytes.NewReader(data), uint64(len(data)))
+	}()
+
+	<-progressed
+	assert.NoError(t, answerDC.Close())
+	assert.ErrorIs(t, <-receivedResult, ErrClosed)
+	assert.Error(t, <-sendErr)
+
+	assert.ErrorIs(t, receiver.Send(context.Background(), "id", "name", bytes.NewReader(data), uint64(len(data))), ErrClosed)
+
+	closePair(t, offerPC, answerPC, wan)
+}
+
+func TestSession_IDTooLong(t *testing.T) {
+	pc, err := webrtc.NewPeerConnection(webrtc.Configuration{})
+	assert.NoError(t, err)
+
+	dc, err := pc.CreateDataChannel("transfer", nil)
+	assert.NoError(t, err)
+
+	id := string(make([]byte, maxIDLength+1))
+	err = NewSession(dc, Config{}).Send(context.Background(), id, "name", bytes.NewReader([]byte{1}), 1)
+	assert.ErrorIs(t, err, errIDTooLong)
+
+	assert.NoError(t, pc.Close())
+}
@@ -0,0 +1,262 @@
+// Package h265packet implements the RTP payload format for H265, RFC7798
+package h265packet
+
+import (
+	"bytes"
+	"errors"
+
+	"github.com/pion/rtp/codecs"
+)
+
+const (
+	// NAL unit types of RFC7798 Section 4.4
+	naluTypeAP   = 48
+	naluTypeFU   = 49
+	naluTypePACI = 50
+
+	// NAL unit types of ITU-T H.265 Table 7-1 which are not sent
+	naluTypeAUD = 35
+	naluTypeFD  = 38
+
+	naluHeaderSize = 2
+	fuHeaderSize   = 1
+	apNaluSize     = 2
+
+	fuStartBitmask = 0x80
+	fuEndBitmask   = 0x40
+)
+
+var errUnhandledPacketType = errors.New("unhandled H265 packet type")
+
+func naluType(header byte) uint8 {
+	return (header >> 1) & 0x3F
+}
+
+// withNaluType returns the first byte of a NAL unit header with its type replaced
+func withNaluType(header byte, typ uint8) byte {
+	return header&0x81 | typ<<1
+}
+
+// Payloader payloads H265 packets. Single NAL units are sent as they are, NAL units
+// that fit together are aggregated in Aggregation Packets, and NAL units larger than
+// the MTU are sent in Fragmentation Units.
+type Payloader struct{}
+
+// Payload fragments the Annex-B H265 access unit in payload across one or more byte arrays
+func (p *Payloader) Payload(mtu uint16, payload []byte) [][]byte {
+	payloads := [][]byte{}
+	if len(payload) == 0 || mtu <= naluHeaderSize+fuHeaderSize {
+		return payloads
+	}
+
+	aggregated := [][]byte{}
+	aggregatedSize := naluHeaderSize
+	flush := func() {
+		switch len(aggregated) {
+		case 0:
+		case 1:
+			payloads = append(payloads, aggregated[0])
+		default:
+			payloads = append(payloads, aggregationPacket(aggregated))
+		}
+		aggregated = aggregated[:0]
+		aggregatedSize = naluHeaderSize
+	}
+
+	for _, nalu := range splitNalus(payload) {
+		if len(nalu) <= naluHeaderSize {
+			continue
+		} else if typ := naluType(nalu[0]); typ == naluTypeAUD || typ == naluTypeFD {
+			continue
+		}
+
+		if aggregatedSize+apNaluSize+len(nalu) > int(mtu) {
+			flush()
+		}
+
+		if len(nalu) > int(mtu) {
+			payloads = append(payloads, fragmentationUnits(mtu, nalu)...)
+			continue
+		}
+
+		aggregated = append(aggregated, nalu)
+		aggregatedSize += apNaluSize + len(nalu)
+	}
+	flush()
+
+	return payloads
+}
+
+// aggregationPacket builds an AP, RFC7798 Section 4.4.2. The F bit is set if any of
+// the NAL units has it, LayerID and TID are the lowest of the NAL units.
+func aggregationPacket(nalus [][]byte) []byte {
+	forbidden, layerID, tid := byte(0), byte(0x3F), byte(0x07)
+	for _, nalu := range nalus {
+		forbidden |= nalu[0] & 0x80
+		if l := (nalu[0]&0x01)<<5 | nalu[1]>>3; l < layerID {
+			layerID = l
+		}
+		if t := nalu[1] & 0x07; t < tid {
+			tid = t
+		}
+	}
+
+	out := []byte{forbidden | naluTypeAP<<1 | layerID>>5, layerID<<3 | tid}
+	for _, nalu := range nalus {
+		out = append(out, byte(len(nalu)>>8), byte(len(nalu)))
+		out = append(out, nalu...)
+	}
+	return out
+}
+
+// fragmentationUnits splits a NAL unit in FUs, RFC7798 Section 4.4.3
+func fragmentationUnits(mtu uint16, nalu []byte) [][]byte {
+	maxFragmentSize := int(mtu) - naluHeaderSize - fuHeaderSize
+	typ := naluType(nalu[0])
+
+	payloads := [][]byte{}
+	for data := nalu[naluHeaderSize:]; len(data) != 0; {
+		fragmentSize := maxFragmentSize
+		if fragmentSize > len(data) {
+			fragmentSize = len(data)
+		}
+
+		fuHeader := typ
+		if len(payloads) == 0 {
+			fuHeader |= fuStartBitmask
+		}
+		if fragmentSize == len(data) {
+			fuHeader |= fuEndBitmask
+		}
+
+		out := make([]byte, 0, naluHeaderSize+fuHeaderSize+fragmentSize)
+		out = append(out, withNaluType(nalu[0], naluTypeFU), nalu[1], fuHeader)
+		payloads = append(payloads, append(out, data[:fragmentSize]...))
+		data = data[fragmentSize:]
+	}
+	return payloads
+}
+
+// splitNalus returns the NAL units of an Annex-B byte stream, the whole stream is a
+// single NAL unit if it has no start code
+func splitNalus(payload []byte) [][]byte {
+	startCode := []byte{0x00, 0x00, 0x01}
+
+	start := bytes.Index(payload, startCode)
+	if start == -1 {
+		return [][]byte{payload}
+	}
+
+	nalus := [][]byte{}
+	for start != -1 {
+		payload = payload[start+len(startCode):]
+		end := bytes.Index(payload, startCode)
+		if end == -1 {
+			nalus = append(nalus, payload)
+			break
+		}
+
+		// Leading zero of a 4 byte start code, or trailing_zero_8bits
+		nalus = append(nalus, bytes.TrimRight(payload[:end], "\x00"))
+		start = end
+	}
+	return nalus
+}
+
+// Packet depacketizes H265 RTP packets into an Annex-B byte stream. The NAL units of
+// Fragmentation Units are returned once their last fragment is unmarshaled.
+type Packet struct {
+	fuBuffer      []byte
+	mightNeedDONL bool
+}
+
+// WithDONL can be called to specify whether or not DONL might be parsed.
+// DONL may need to be parsed if `sprop-max-don-diff` is greater than 0 on the RTP stream.
+func (p *Packet) WithDONL(value bool) {
+	p.mightNeedDONL = value
+}
+
+// Unmarshal parses the passed byte slice and returns its NAL units in Annex-B format
+func (p *Packet) Unmarshal(payload []byte) ([]byte, error) {
+	packet := &codecs.H265Packet{}
+	packet.WithDONL(p.mightNeedDONL)
+	if _, err := packet.Unmarshal(payload); err != nil {
+		return nil, err
+	}
+
+	switch decoded := packet.Packet().(type) {
+	case *codecs.H265SingleNALUnitPacket:
+		header := decoded.PayloadHeader()
+		return annexB([]byte{byte(header >> 8), byte(header)}, decoded.Payload()), nil
+	case *codecs.H265AggregationPacket:
+		out := annexB(decoded.FirstUnit().NalUnit())
+		for _, unit := range decoded.OtherUnits() {
+			out = append(out, annexB(unit.NalUnit())...)
+		}
+		return out, nil
+	case *codecs.H265FragmentationUnitPacket:
+		return p.unmarshalFragment(decoded), nil
+	case *codecs.H265PACIPacket:
+		// The PACI payload is a NAL unit, an AP or a FU without its header,
+		// the header is rebuilt from the PACI header fields
+		header := decoded.PayloadHeader()
+		highByte := withNaluType(byte(header>>8), decoded.CType()) &^ 0x80
+		if decoded.A() {
+			highByte |= 0x80
+		}
+		return p.Unmarshal(append([]byte{highByte, byte(header)}, decoded.Payload()...))
+	default:
+		return nil, errUnhandledPacketType
+	}
+}
+
+func (p *Packet) unmarshalFragment(fu *codecs.H265FragmentationUnitPacket) []byte {
+	fuHeader := fu.FuHeader()
+	if fuHeader.S() {
+		header := fu.PayloadHeader()
+		p.fuBuffer = []byte{withNaluType(byte(header>>8), fuHeader.FuType()), byte(header)}
+	} else if p.fuBuffer == nil {
+		// The first fragment was lost, the NAL unit is dropped
+		return []byte{}
+	}
+
+	p.fuBuffer = append(p.fuBuffer, fu.Payload()...)
+	if !fuHeader.E() {
+		return []byte{}
+	}
+
+	out := annexB(p.fuBuffer)
+	p.fuBuffer = nil
+	return out
+}
+
+// IsPartitionHead checks if this is the head of a packetized NAL unit, it is
+// false for the fragments that follow the first one of a Fragmentation Unit.
+func (p *Packet) IsPartitionHead(payload []byte) bool {
+	if len(payload) < naluHeaderSize+fuHeaderSize {
+		return false
+	}
+
+	switch naluType(payload[0]) {
+	case naluTypeFU:
+		return payload[2]&fuStartBitmask != 0
+	case naluTypePACI:
+		if len(payload) < 4 || naluType(payload[2]) != naluTypeFU {
+			return true
+		}
+		phsSize := int(payload[2]&0x01)<<4 | int(payload[3]>>4)
+		return len(payload) > 4+phsSize && payload[4+phsSize]&fuStartBitmask != 0
+	default:
+		return true
+	}
+}
+
+// IsPartitionTail checks if this is the tail of an access unit, the marker bit
+// is set on the last packet of an access unit
+func (p *Packet) IsPartitionTail(marker bool, payload []byte) bool {
+	return marker
+}
+
+func annexB(nalu ...[]byte) []byte {
+	return append([]byte{0x00, 0x00, 0x00, 0x01}, bytes.Join(nalu, nil)...)
+}
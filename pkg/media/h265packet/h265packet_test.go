@@ -0,0 +1,178 @@
+package h265packet
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+var (
+	testVPS   = []byte{0x40, 0x01, 0x0C, 0x01}
+	testSPS   = []byte{0x42, 0x01, 0x01, 0x01, 0x60}
+	testPPS   = []byte{0x44, 0x01, 0xC1, 0x73}
+	testSlice = []byte{0x26, 0x01, 0xAF, 0x06, 0xB8, 0x63, 0xEF, 0x3A, 0x7F, 0x3E}
+)
+
+func annexBStream(startCode []byte, nalus ...[]byte) []byte {
+	out := []byte{}
+	for _, nalu := range nalus {
+		out = append(append(out, startCode...), nalu...)
+	}
+	return out
+}
+
+func TestPayloader(t *testing.T) {
+	p := &Payloader{}
+
+	assert.Equal(t, [][]byte{}, p.Payload(100, nil))
+	assert.Equal(t, [][]byte{}, p.Payload(3, testSlice))
+
+	// A NAL unit without start code is sent as it is
+	assert.Equal(t, [][]byte{testSlice}, p.Payload(100, testSlice))
+
+	// The parameter sets are aggregated, the AUD is dropped
+	aud := []byte{0x46, 0x01, 0x10}
+	stream := annexBStream([]byte{0x00, 0x00, 0x00, 0x01}, aud, testVPS, testSPS, testPPS)
+	stream = append(stream, annexBStream([]byte{0x00, 0x00, 0x01}, testSlice)...)
+	assert.Equal(t, [][]byte{
+		{
+			0x60, 0x01,
+			0x00, 0x04, 0x40, 0x01, 0x0C, 0x01,
+			0x00, 0x05, 0x42, 0x01, 0x01, 0x01, 0x60,
+			0x00, 0x04, 0x44, 0x01, 0xC1, 0x73,
+		},
+		testSlice,
+	}, p.Payload(21, stream))
+
+	// A NAL unit larger than the MTU is fragmented
+	assert.Equal(t, [][]byte{
+		{0x62, 0x01, 0x93, 0xAF, 0x06, 0xB8, 0x63},
+		{0x62, 0x01, 0x53, 0xEF, 0x3A, 0x7F, 0x3E},
+	}, p.Payload(7, annexBStream([]byte{0x00, 0x00, 0x01}, testSlice)))
+}
+
+func TestPayloader_AggregationHeader(t *testing.T) {
+	// LayerID and TID of an AP are the lowest of its NAL units
+	out := aggregationPacket([][]byte{{0x40, 0x0A, 0x01}, {0x41, 0x13, 0x02}})
+	assert.Equal(t, []byte{0x60, 0x0A}, out[:2])
+}
+
+func TestPacket_Unmarshal(t *testing.T) {
+	startCode := []byte{0x00, 0x00, 0x00, 0x01}
+
+	for _, test := range []struct {
+		name     string
+		payloads [][]byte
+		expected [][]byte
+		err      bool
+	}{
+		{
+			name:     "SingleNALUnit",
+			payloads: [][]byte{testSlice},
+			expected: [][]byte{annexBStream(startCode, testSlice)},
+		},
+		{
+			name: "AggregationPacket",
+			payloads: [][]byte{{
+				0x60, 0x01,
+				0x00, 0x04, 0x40, 0x01, 0x0C, 0x01,
+				0x00, 0x05, 0x42, 0x01, 0x01, 0x01, 0x60,
+			}},
+			expected: [][]byte{annexBStream(startCode, testVPS, testSPS)},
+		},
+		{
+			name: "FragmentationUnits",
+			payloads: [][]byte{
+				{0x62, 0x01, 0x93, 0xAF, 0x06, 0xB8, 0x63},
+				{0x62, 0x01, 0x13, 0xEF, 0x3A},
+				{0x62, 0x01, 0x53, 0x7F, 0x3E},
+			},
+			expected: [][]byte{{}, {}, annexBStream(startCode, testSlice)},
+		},
+		{
+			name: "FragmentationUnitsWithoutStart",
+			payloads: [][]byte{
+				{0x62, 0x01, 0x13, 0xEF, 0x3A},
+				{0x62, 0x01, 0x53, 0x7F, 0x3E},
+			},
+			expected: [][]byte{{}, {}},
+		},
+		{
+			name: "PACIPacket",
+			payloads: [][]byte{
+				// PACI with a PHES of 1 byte, carrying a slice
+				{0x64, 0x01, 0x26, 0x10, 0xFF, 0xAF, 0x06},
+				// PACI carrying the first fragment of a FU
+				{0x64, 0x01, 0x62, 0x00, 0x93, 0xAF},
+				{0x62, 0x01, 0x53, 0x06},
+			},
+			expected: [][]byte{
+				annexBStream(startCode, []byte{0x26, 0x01, 0xAF, 0x06}),
+				{},
+				annexBStream(startCode, []byte{0x26, 0x01, 0xAF, 0x06}),
+			},
+		},
+		{
+			name:     "Short",
+			payloads: [][]byte{{0x26, 0x01}},
+			err:      true,
+		},
+		{
+			name:     "ForbiddenBit",
+			payloads: [][]byte{{0xA6, 0x01, 0xAF}},
+			err:      true,
+		},
+	} {
+		test := test
+		t.Run(test.name, func(t *testing.T) {
+			p := &Packet{}
+			for i, payload := range test.payloads {
+				out, err := p.Unmarshal(payload)
+				if test.err {
+					assert.Error(t, err)
+					continue
+				}
+				assert.NoError(t, err)
+				assert.Equal(t, test.expected[i], out)
+			}
+		})
+	}
+}
+
+func TestPacket_RoundTrip(t *testing.T) {
+	stream := annexBStream([]byte{0x00, 0x00, 0x00, 0x01}, testVPS, testSPS, testPPS, testSlice, bytes.Repeat([]byte{0x02, 0x01, 0xFF}, 100))
+
+	for _, mtu := range []uint16{4, 10, 20, 100, 1200} {
+		payloads := (&Payloader{}).Payload(mtu, stream)
+
+		p := &Packet{}
+		out := []byte{}
+		for _, payload := range payloads {
+			assert.LessOrEqual(t, len(payload), int(mtu))
+
+			data, err := p.Unmarshal(payload)
+			assert.NoError(t, err)
+			out = append(out, data...)
+		}
+		assert.Equal(t, stream, out, mtu)
+	}
+}
+
+func TestPacket_IsPartitionHead(t *testing.T) {
+	p := &Packet{}
+
+	assert.False(t, p.IsPartitionHead(nil))
+	assert.False(t, p.IsPartitionHead([]byte{0x26, 0x01}))
+	assert.True(t, p.IsPartitionHead(testSlice))
+	assert.True(t, p.IsPartitionHead([]byte{0x60, 0x01, 0x00, 0x04}))
+	assert.True(t, p.IsPartitionHead([]byte{0x62, 0x01, 0x93, 0xAF}))
+	assert.False(t, p.IsPartitionHead([]byte{0x62, 0x01, 0x13, 0xEF}))
+	assert.False(t, p.IsPartitionHead([]byte{0x62, 0x01, 0x53, 0xEF}))
+	assert.True(t, p.IsPartitionHead([]byte{0x64, 0x01, 0x62, 0x00, 0x93, 0xAF}))
+	assert.False(t, p.IsPartitionHead([]byte{0x64, 0x01, 0x62, 0x10, 0xFF, 0x13, 0xAF}))
+	assert.True(t, p.IsPartitionHead([]byte{0x64, 0x01, 0x26, 0x10, 0xFF, 0xAF, 0x06}))
+
+	assert.True(t, p.IsPartitionTail(true, testSlice))
+	assert.False(t, p.IsPartitionTail(false, testSlice))
+}
@@ -0,0 +1,153 @@
+package h265reader
+
+import (
+	"bytes"
+	"io"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func CreateReader(h265 []byte, require *require.Assertions) *H265Reader {
+	reader, err := NewReader(bytes.NewReader(h265))
+
+	require.Nil(err)
+	require.NotNil(reader)
+
+	return reader
+}
+
+func TestDataDoesNotStartWithH265Header(t *testing.T) {
+	require := require.New(t)
+
+	testFunction := func(input []byte, expectedErr error) {
+		reader := CreateReader(input, require)
+		nal, err := reader.NextNAL()
+		require.ErrorIs(err, expectedErr)
+		require.Nil(nal)
+	}
+
+	h265Bytes1 := []byte{2}
+	testFunction(h265Bytes1, io.EOF)
+
+	h265Bytes2 := []byte{0, 2}
+	testFunction(h265Bytes2, io.EOF)
+
+	h265Bytes3 := []byte{0, 0, 2}
+	testFunction(h265Bytes3, io.EOF)
+
+	h265Bytes4 := []byte{0, 0, 2, 0}
+	testFunction(h265Bytes4, errDataIsNotH265Stream)
+
+	h265Bytes5 := []byte{0, 0, 0, 2}
+	testFunction(h265Bytes5, errDataIsNotH265Stream)
+}
+
+func TestParseHeader(t *testing.T) {
+	require := require.New(t)
+	h265Bytes := []byte{0x0, 0x0, 0x1, 0xC3, 0x0B}
+
+	reader := CreateReader(h265Bytes, require)
+
+	nal, err := reader.NextNAL()
+	require.Nil(err)
+
+	require.Equal(2, len(nal.Data))
+	require.True(nal.ForbiddenZeroBit)
+	require.Equal(NalUnitTypeSPS, nal.UnitType)
+	require.Equal(uint8(33), nal.LayerID)
+	require.Equal(uint8(3), nal.TemporalIDPlus1)
+}
+
+func TestEOF(t *testing.T) {
+	require := require.New(t)
+
+	testFunction := func(input []byte) {
+		reader := CreateReader(input, require)
+
+		nal, err := reader.NextNAL()
+		require.Equal(io.EOF, err)
+		require.Nil(nal)
+	}
+
+	h265Bytes1 := []byte{0, 0, 0, 1}
+	testFunction(h265Bytes1)
+
+	h265Bytes2 := []byte{0, 0, 1}
+	testFunction(h265Bytes2)
+
+	h265Bytes3 := []byte{}
+	testFunction(h265Bytes3)
+}
+
+func TestNextNAL(t *testing.T) {
+	require := require.New(t)
+	h265Bytes := []byte{
+		0x0, 0x0, 0x0, 0x1, 0x40, 0x01, 0x0C, // VPS
+		0x0, 0x0, 0x1, 0x4E, 0x01, 0x05, // SEI
+		0x0, 0x0, 0x0, 0x1, 0x26, 0x01, 0xAF,
+	}
+
+	reader := CreateReader(h265Bytes, require)
+
+	for _, expected := range []struct {
+		unitType NalUnitType
+		data     []byte
+	}{
+		{NalUnitTypeVPS, []byte{0x40, 0x01, 0x0C}},
+		{NalUnitTypePrefixSEI, []byte{0x4E, 0x01, 0x05}},
+		{NalUnitTypeIdrWRadl, []byte{0x26, 0x01, 0xAF}},
+	} {
+		nal, err := reader.NextNAL()
+		require.Nil(err)
+		require.Equal(expected.unitType, nal.UnitType)
+		require.Equal(expected.data, nal.Data)
+		require.Equal(uint8(1), nal.TemporalIDPlus1)
+	}
+
+	nal, err := reader.NextNAL()
+	require.Equal(io.EOF, err)
+	require.Nil(nal)
+}
+
+func TestIsIRAP(t *testing.T) {
+	require.True(t, NalUnitTypeIdrWRadl.IsIRAP())
+	require.True(t, NalUnitTypeCraNut.IsIRAP())
+	require.False(t, NalUnitTypeTrailR.IsIRAP())
+	require.False(t, NalUnitTypeVPS.IsIRAP())
+}
+
+func TestIssue1734_NextNal(t *testing.T) {
+	tt := [...][]byte{
+		[]byte("\x00\x00\x010\x00\x00\x01\x00\x00\x01"),
+		[]byte("\x00\x00\x00\x01\x00\x00\x01"),
+	}
+
+	for _, cur := range tt {
+		r, err := NewReader(bytes.NewReader(cur))
+		require.NoError(t, err)
+
+		// Just make sure it doesn't crash
+		for {
+			nal, err := r.NextNAL()
+
+			if err != nil || nal == nil {
+				break
+			}
+		}
+	}
+}
+
+func TestTrailing01AfterStartCode(t *testing.T) {
+	r, err := NewReader(bytes.NewReader([]byte{
+		0x0, 0x0, 0x0, 0x1, 0x01,
+		0x0, 0x0, 0x0, 0x1, 0x01,
+	}))
+	require.NoError(t, err)
+
+	for i := 0; i <= 1; i++ {
+		nal, err := r.NextNAL()
+		require.NoError(t, err)
+		require.NotNil(t, nal)
+	}
+}
@@ -0,0 +1,100 @@
+// Package h265writer implements H265 media container writer
+package h265writer
+
+import (
+	"io"
+	"os"
+
+	"github.com/pion/rtp"
+	"github.com/pion/webrtc/v3/pkg/media/h265packet"
+)
+
+type (
+	// H265Writer is used to take RTP packets, parse them and
+	// write the data to an io.Writer as an Annex-B byte stream.
+	// Single NAL unit packets, Aggregation Packets, Fragmentation Units
+	// and PACI packets are supported, without DONL.
+	// https://tools.ietf.org/html/rfc7798#section-4.4
+	H265Writer struct {
+		writer       io.Writer
+		hasKeyFrame  bool
+		cachedPacket *h265packet.Packet
+	}
+)
+
+// New builds a new H265 writer
+func New(filename string) (*H265Writer, error) {
+	f, err := os.Create(filename) //nolint:gosec
+	if err != nil {
+		return nil, err
+	}
+
+	return NewWith(f), nil
+}
+
+// NewWith initializes a new H265 writer with an io.Writer output
+func NewWith(w io.Writer) *H265Writer {
+	return &H265Writer{
+		writer: w,
+	}
+}
+
+// WriteRTP adds a new packet and writes the appropriate headers for it
+func (h *H265Writer) WriteRTP(packet *rtp.Packet) error {
+	if len(packet.Payload) == 0 {
+		return nil
+	}
+
+	if !h.hasKeyFrame {
+		if h.hasKeyFrame = isKeyFrame(packet.Payload); !h.hasKeyFrame {
+			// key frame not defined yet. discarding packet
+			return nil
+		}
+	}
+
+	if h.cachedPacket == nil {
+		h.cachedPacket = &h265packet.Packet{}
+	}
+
+	data, err := h.cachedPacket.Unmarshal(packet.Payload)
+	if err != nil {
+		return err
+	}
+
+	_, err = h.writer.Write(data)
+
+	return err
+}
+
+// Close closes the underlying writer
+func (h *H265Writer) Close() error {
+	h.cachedPacket = nil
+	if h.writer != nil {
+		if closer, ok := h.writer.(io.Closer); ok {
+			return closer.Close()
+		}
+	}
+
+	return nil
+}
+
+// isKeyFrame returns true if the packet starts with a VPS, the parameter sets
+// are sent before the IRAP pictures
+func isKeyFrame(data []byte) bool {
+	const (
+		typeAP  = 48
+		typeVPS = 32
+	)
+
+	if len(data) < 2 {
+		return false
+	}
+
+	naluType := (data[0] >> 1) & 0x3F
+	if naluType == typeAP && len(data) >= 5 {
+		// The first NAL unit of the AP follows its 2 bytes size
+		return (data[4]>>1)&0x3F == typeVPS
+	}
+
+	return naluType == typeVPS
+}
@@ -0,0 +1,171 @@
+package h265writer
+
+import (
+	"bytes"
+	"errors"
+	"testing"
+
+	"github.com/pion/rtp"
+	"github.com/stretchr/testify/assert"
+)
+
+type writerCloser struct {
+	bytes.Buffer
+}
+
+var errClose = errors.New("close error")
+
+func (w *writerCloser) Close() error {
+	return errClose
+}
+
+func TestNewWith(t *testing.T) {
+	writer := &writerCloser{}
+	h265Writer := NewWith(writer)
+	assert.NotNil(t, h265Writer.Close())
+}
+
+func TestIsKeyFrame(t *testing.T) {
+	tests := []struct {
+		name    string
+		payload []byte
+		want    bool
+	}{
+		{
+			"When given a non-keyframe; it should return false",
+			[]byte{0x02, 0x01, 0x90},
+			false,
+		},
+		{
+			"When given a short payload; it should return false",
+			[]byte{0x40},
+			false,
+		},
+		{
+			"When given a VPS packetized with AP; it should return true",
+			[]byte{0x60, 0x01, 0x00, 0x03, 0x40, 0x01, 0x90, 0x00, 0x03, 0x42, 0x01, 0x90},
+			true,
+		},
+		{
+			"When given a SEI packetized with AP; it should return false",
+			[]byte{0x60, 0x01, 0x00, 0x03, 0x4E, 0x01, 0x90, 0x00, 0x03, 0x40, 0x01, 0x90},
+			false,
+		},
+		{
+			"When given a VPS with no packetization; it should return true",
+			[]byte{0x40, 0x01, 0x90, 0x90},
+			true,
+		},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			got := isKeyFrame(tt.payload)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestWriteRTP(t *testing.T) {
+	tests := []struct {
+		name        string
+		payload     []byte
+		hasKeyFrame bool
+		wantBytes   []byte
+		wantErr     bool
+		reuseWriter bool
+	}{
+		{
+			"When given an empty payload; it should return nil",
+			[]byte{},
+			false,
+			[]byte{},
+			false,
+			false,
+		},
+		{
+			"When no keyframe is defined; it should discard the packet",
+			[]byte{0x02, 0x01, 0x90},
+			false,
+			[]byte{},
+			false,
+			false,
+		},
+		{
+			"When a valid Single NAL Unit packet is given; it should unpack it without error",
+			[]byte{0x02, 0x01, 0x90},
+			true,
+			[]byte{0x00, 0x00, 0x00, 0x01, 0x02, 0x01, 0x90},
+			false,
+			false,
+		},
+		{
+			"When a valid AP packet is given; it should unpack it without error",
+			[]byte{0x60, 0x01, 0x00, 0x03, 0x40, 0x01, 0x90, 0x00, 0x04, 0x42, 0x01, 0x90, 0x90},
+			false,
+			[]byte{0x00, 0x00, 0x00, 0x01, 0x40, 0x01, 0x90, 0x00, 0x00, 0x00, 0x01, 0x42, 0x01, 0x90, 0x90},
+			false,
+			false,
+		},
+		{
+			"When a valid FU start packet is given; it should unpack it without error",
+			[]byte{0x62, 0x01, 0x93, 0x90, 0x90},
+			true,
+			[]byte{},
+			false,
+			true,
+		},
+		{
+			"When a valid FU end packet is given; it should unpack it without error",
+			[]byte{0x62, 0x01, 0x53, 0x91, 0x91},
+			true,
+			[]byte{0x00, 0x00, 0x00, 0x01, 0x26, 0x01, 0x90, 0x90, 0x91, 0x91},
+			false,
+			false,
+		},
+		{
+			"When an invalid packet is given; it should return an error",
+			[]byte{0xC0, 0x01, 0x90},
+			true,
+			[]byte{},
+			true,
+			false,
+		},
+	}
+
+	var reuseWriter *bytes.Buffer
+	var reuseH265Writer *H265Writer
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			writer := &bytes.Buffer{}
+			h265Writer := &H265Writer{
+				hasKeyFrame: tt.hasKeyFrame,
+				writer:      writer,
+			}
+			if reuseWriter != nil {
+				writer = reuseWriter
+			}
+			if reuseH265Writer != nil {
+				h265Writer = reuseH265Writer
+			}
+
+			err := h265Writer.WriteRTP(&rtp.Packet{
+				Payload: tt.payload,
+			})
+			assert.Equal(t, tt.wantErr, err != nil)
+			assert.True(t, bytes.Equal(tt.wantBytes, writer.Bytes()))
+
+			if !tt.reuseWriter {
+				assert.Nil(t, h265Writer.Close())
+				reuseWriter = nil
+				reuseH265Writer = nil
+			} else {
+				reuseWriter = writer
+				reuseH265Writer = h265Writer
+			}
+		})
+	}
+}
@@ -12,6 +12,10 @@ const (
 	ivfFileHeaderSignature = "DKIF"
 	ivfFileHeaderSize      = 32
 	ivfFrameHeaderSize     = 12
+
+	mimeTypeVP8 = "video/VP8"
+	mimeTypeVP9 = "video/VP9"
+	mimeTypeAV1 = "video/AV1"
 )
 
 var (
@@ -38,6 +42,21 @@ type IVFFileHeader struct {
 	unused              uint32 // 28-31
 }
 
+// MimeType returns the MimeType of the codec of the file, from its FourCC.
+// It is empty if the codec isn't VP8, VP9 or AV1.
+func (h *IVFFileHeader) MimeType() string {
+	switch h.FourCC {
+	case "VP80":
+		return mimeTypeVP8
+	case "VP90":
+		return mimeTypeVP9
+	case "AV01":
+		return mimeTypeAV1
+	default:
+		return ""
+	}
+}
+
 // IVFFrameHeader 12-byte header for IVF frames
 // https://wiki.multimedia.cx/index.php/IVF
 type IVFFrameHeader struct {
@@ -50,6 +50,19 @@ func TestIVFReader_ParseValidFileHeader(t *testing.T) {
 	assert.Equal(uint32(1000), header.TimebaseNumerator, "timebase numerator should be 1000")
 	assert.Equal(uint32(29), header.NumFrames, "number of frames should be 29")
 	assert.Equal(uint32(0), header.unused, "bytes should be unused")
+	assert.Equal("video/VP8", header.MimeType(), "MimeType should be 'video/VP8'")
+}
+
+func TestIVFFileHeader_MimeType(t *testing.T) {
+	for fourCC, mimeType := range map[string]string{
+		"VP80": "video/VP8",
+		"VP90": "video/VP9",
+		"AV01": "video/AV1",
+		"H264": "",
+	} {
+		header := &IVFFileHeader{FourCC: fourCC}
+		assert.Equal(t, mimeType, header.MimeType(), fourCC)
+	}
 }
 
 func TestIVFReader_ParseValidFrames(t *testing.T) {
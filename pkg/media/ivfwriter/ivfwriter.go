@@ -10,6 +10,7 @@ import (
 	"github.com/pion/rtp"
 	"github.com/pion/rtp/codecs"
 	"github.com/pion/rtp/pkg/frame"
+	"github.com/pion/webrtc/v3/internal/vp9"
 )
 
 var (
@@ -21,6 +22,7 @@ var (
 
 const (
 	mimeTypeVP8 = "video/VP8"
+	mimeTypeVP9 = "video/VP9"
 	mimeTypeAV1 = "video/AV1"
 
 	ivfFileHeaderSignature = "DKIF"
@@ -32,11 +34,16 @@ type IVFWriter struct {
 	count        uint64
 	seenKeyFrame bool
 
-	isVP8, isAV1 bool
+	isVP8, isVP9, isAV1 bool
 
-	// VP8
+	// VP8, VP9
 	currentFrame []byte
 
+	// VP9, the frames of the spatial layers of the current picture
+	vp9Frames    [][]byte
+	vp9Timestamp uint32
+	vp9PictureID uint16
+
 	// AV1
 	av1Frame frame.AV1
 }
@@ -72,7 +79,7 @@ func NewWith(out io.Writer, opts ...Option) (*IVFWriter, error) {
 		}
 	}
 
-	if !writer.isAV1 && !writer.isVP8 {
+	if !writer.isAV1 && !writer.isVP8 && !writer.isVP9 {
 		writer.isVP8 = true
 	}
 
@@ -91,6 +98,8 @@ func (i *IVFWriter) writeHeader() error {
 	// FOURCC
 	if i.isVP8 {
 		copy(header[8:], "VP80")
+	} else if i.isVP9 {
+		copy(header[8:], "VP90")
 	} else if i.isAV1 {
 		copy(header[8:], "AV01")
 	}
@@ -154,6 +163,8 @@ func (i *IVFWriter) WriteRTP(packet *rtp.Packet) error {
 			return err
 		}
 		i.currentFrame = nil
+	} else if i.isVP9 {
+		return i.writeVP9(packet)
 	} else if i.isAV1 {
 		av1Packet := &codecs.AV1Packet{}
 		if _, err := av1Packet.Unmarshal(packet.Payload); err != nil {
@@ -175,6 +186,61 @@ func (i *IVFWriter) WriteRTP(packet *rtp.Packet) error {
 	return nil
 }
 
+// writeVP9 reassembles the frames of the spatial layers of a picture, they are
+// written as a single superframe once the picture is complete
+func (i *IVFWriter) writeVP9(packet *rtp.Packet) error {
+	vp9Packet := codecs.VP9Packet{}
+	if _, err := vp9Packet.Unmarshal(packet.Payload); err != nil {
+		return err
+	}
+
+	// The recording starts with a key frame of the base spatial layer
+	if !i.seenKeyFrame && (vp9Packet.P || !vp9Packet.B || vp9Packet.SID != 0) {
+		return nil
+	}
+	i.seenKeyFrame = true
+
+	// The spatial layers of a picture share its timestamp and picture ID, the
+	// previous picture is written if its last packet was lost
+	if (len(i.vp9Frames) != 0 || i.currentFrame != nil) &&
+		(packet.Timestamp != i.vp9Timestamp || vp9Packet.PictureID != i.vp9PictureID) {
+		if err := i.writeVP9Picture(); err != nil {
+			return err
+		}
+	}
+	i.vp9Timestamp, i.vp9PictureID = packet.Timestamp, vp9Packet.PictureID
+
+	switch {
+	case vp9Packet.B:
+		i.currentFrame = append([]byte{}, vp9Packet.Payload...)
+	case i.currentFrame == nil:
+		return nil
+	default:
+		i.currentFrame = append(i.currentFrame, vp9Packet.Payload...)
+	}
+
+	if vp9Packet.E {
+		i.vp9Frames = append(i.vp9Frames, i.currentFrame)
+		i.currentFrame = nil
+	}
+
+	if !packet.Marker {
+		return nil
+	}
+	return i.writeVP9Picture()
+}
+
+func (i *IVFWriter) writeVP9Picture() error {
+	frames := i.vp9Frames
+	i.vp9Frames = nil
+	i.currentFrame = nil
+
+	if len(frames) == 0 {
+		return nil
+	}
+	return i.writeFrame(vp9.Superframe(frames))
+}
+
 // Close stops the recording
 func (i *IVFWriter) Close() error {
 	if i.ioWriter == nil {
@@ -209,16 +275,18 @@ func (i *IVFWriter) Close() error {
 // An Option configures a SampleBuilder.
 type Option func(i *IVFWriter) error
 
-// WithCodec configures if IVFWriter is writing AV1 or VP8 packets to disk
+// WithCodec configures if IVFWriter is writing AV1, VP8 or VP9 packets to disk
 func WithCodec(mimeType string) Option {
 	return func(i *IVFWriter) error {
-		if i.isVP8 || i.isAV1 {
+		if i.isVP8 || i.isVP9 || i.isAV1 {
 			return errCodecAlreadySet
 		}
 
 		switch mimeType {
 		case mimeTypeVP8:
 			i.isVP8 = true
+		case mimeTypeVP9:
+			i.isVP9 = true
 		case mimeTypeAV1:
 			i.isAV1 = true
 		default:
@@ -2,6 +2,7 @@ package ivfwriter
 
 import (
 	"bytes"
+	"encoding/binary"
 	"io"
 	"testing"
 
@@ -245,6 +246,9 @@ func TestIVFWriter_Errors(t *testing.T) {
 	_, err := NewWith(&bytes.Buffer{}, WithCodec(mimeTypeAV1), WithCodec(mimeTypeAV1))
 	assert.ErrorIs(t, err, errCodecAlreadySet)
 
+	_, err = NewWith(&bytes.Buffer{}, WithCodec(mimeTypeVP9), WithCodec(mimeTypeVP8))
+	assert.ErrorIs(t, err, errCodecAlreadySet)
+
 	// Creating a Writer with Invalid Codec
 	_, err = NewWith(&bytes.Buffer{}, WithCodec(""))
 	assert.ErrorIs(t, err, errNoSuchCodec)
@@ -296,3 +300,48 @@ func TestIVFWriter_AV1(t *testing.T) {
 		assert.NoError(t, writer.Close())
 	})
 }
+
+func TestIVFWriter_VP9(t *testing.T) {
+	buffer := &bytes.Buffer{}
+
+	writer, err := NewWith(buffer, WithCodec(mimeTypeVP9))
+	assert.NoError(t, err)
+
+	for _, p := range []*rtp.Packet{
+		// Inter frame before the first key frame, dropped
+		{Header: rtp.Header{Timestamp: 0, Marker: true}, Payload: []byte{0xFC, 0x80, 0x00, 0x00, 0x02, 0xAA}},
+
+		// Key frame in flexible mode, with two spatial layers
+		{Header: rtp.Header{Timestamp: 3000}, Payload: []byte{0xB8, 0x80, 0x01, 0x00, 0x01, 0x02}},
+		{Header: rtp.Header{Timestamp: 3000}, Payload: []byte{0xB4, 0x80, 0x01, 0x00, 0x03}},
+		{Header: rtp.Header{Timestamp: 3000, Marker: true}, Payload: []byte{0xBC, 0x80, 0x01, 0x03, 0x04, 0x05}},
+
+		// Inter frame in non-flexible mode
+		{Header: rtp.Header{Timestamp: 6000, Marker: true}, Payload: []byte{0xEC, 0x80, 0x02, 0x00, 0x01, 0x06}},
+
+		// The first packet of the frame is lost, dropped
+		{Header: rtp.Header{Timestamp: 9000, Marker: true}, Payload: []byte{0xE4, 0x80, 0x03, 0x00, 0x01, 0x07}},
+
+		// The last packet of the picture is lost, it is written with the next picture
+		{Header: rtp.Header{Timestamp: 12000}, Payload: []byte{0xEC, 0x80, 0x04, 0x00, 0x01, 0x08}},
+		{Header: rtp.Header{Timestamp: 15000, Marker: true}, Payload: []byte{0xEC, 0x80, 0x05, 0x00, 0x01, 0x09}},
+	} {
+		assert.NoError(t, writer.WriteRTP(p))
+	}
+	assert.NoError(t, writer.Close())
+
+	assert.Equal(t, []byte("VP90"), buffer.Bytes()[8:12])
+
+	frames := [][]byte{}
+	for data := buffer.Bytes()[32:]; len(data) >= 12; {
+		size := int(binary.LittleEndian.Uint32(data))
+		frames = append(frames, data[12:12+size])
+		data = data[12+size:]
+	}
+	assert.Equal(t, [][]byte{
+		{0x01, 0x02, 0x03, 0x04, 0x05, 0xC1, 0x03, 0x02, 0xC1},
+		{0x06},
+		{0x08},
+		{0x09},
+	}, frames)
+}
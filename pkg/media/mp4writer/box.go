@@ -0,0 +1,53 @@
+package mp4writer
+
+import (
+	"encoding/binary"
+)
+
+// box builds an ISO BMFF box of the given type with the payload
+func box(boxType string, payload ...[]byte) []byte {
+	size := 8
+	for _, p := range payload {
+		size += len(p)
+	}
+
+	out := make([]byte, 8, size)
+	binary.BigEndian.PutUint32(out, uint32(size))
+	copy(out[4:], boxType)
+	for _, p := range payload {
+		out = append(out, p...)
+	}
+	return out
+}
+
+// fullBox builds a box that starts with a version and flags
+func fullBox(boxType string, version uint8, flags uint32, payload ...[]byte) []byte {
+	return box(boxType, append([][]byte{be32(uint32(version)<<24 | flags&0xFFFFFF)}, payload...)...)
+}
+
+func be16(v uint16) []byte {
+	out := make([]byte, 2)
+	binary.BigEndian.PutUint16(out, v)
+	return out
+}
+
+func be32(v uint32) []byte {
+	out := make([]byte, 4)
+	binary.BigEndian.PutUint32(out, v)
+	return out
+}
+
+func be64(v uint64) []byte {
+	out := make([]byte, 8)
+	binary.BigEndian.PutUint64(out, v)
+	return out
+}
+
+// unityMatrix is the transformation matrix of the movie and track headers
+func unityMatrix() []byte {
+	matrix := []byte{}
+	for _, v := range []uint32{0x00010000, 0, 0, 0, 0x00010000, 0, 0, 0, 0x40000000} {
+		matrix = append(matrix, be32(v)...)
+	}
+	return matrix
+}
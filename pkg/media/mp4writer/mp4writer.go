@@ -0,0 +1,381 @@
+// Package mp4writer implements a fragmented MP4 media container writer for H264 and Opus tracks
+package mp4writer
+
+import (
+	"errors"
+	"io"
+	"os"
+	"sync"
+	"time"
+)
+
+var (
+	errFileNotOpened    = errors.New("file not opened")
+	errInvalidNilPacket = errors.New("invalid nil packet")
+	errNoSuchCodec      = errors.New("no codec for this MimeType")
+	errTracksWritten    = errors.New("tracks can't be added after the first packet was written")
+	errShortSPS         = errors.New("SPS is too short")
+)
+
+const (
+	// audioFragmentDuration is the duration of the fragments of a file without video
+	audioFragmentDuration = 2 * time.Second
+
+	// Frame rates for the duration of the last sample of a track that has a single one
+	defaultVideoFrameRate = 30
+	defaultOpusFrameRate  = 50
+
+	movieTimescale       = 1000
+	languageUndetermined = 0x55C4 // ISO 639-2 "und", packed
+
+	// Flags of the boxes, ISO/IEC 14496-12
+	trackFlagsEnabledInMovie = 0x000003
+	tfhdDefaultBaseIsMoof    = 0x020000
+	trunDataOffsetPresent    = 0x000001
+	trunSampleDuration       = 0x000100
+	trunSampleSize           = 0x000200
+	trunSampleFlags          = 0x000400
+	sampleFlagsSync          = 0x02000000 // sample_depends_on 2
+	sampleFlagsNonSync       = 0x01010000 // sample_depends_on 1, sample_is_non_sync_sample
+)
+
+// MP4Writer is used to take RTP packets of several tracks and write them to a fragmented
+// MP4 file on disk, that is suitable to be split into HLS or DASH segments. The tracks are
+// aligned with the arrival time of their first packet.
+//
+// The file starts with the initialization segment, which is written with the first
+// fragment as the sample entry of a H264 track needs its SPS and PPS. A H264 track that
+// has no key frame by then is left out. Each key frame of the first H264 track starts a
+// fragment, a file without video has fragments of 2 seconds. A file that isn't closed
+// misses the last fragment but is playable.
+type MP4Writer struct {
+	mu       sync.Mutex
+	ioWriter io.Writer
+
+	tracks         []*Track
+	initWritten    bool
+	sequenceNumber uint32
+
+	hasStart bool
+	start    time.Time
+
+	now func() time.Time
+}
+
+// New builds a new fragmented MP4 writer
+func New(fileName string) (*MP4Writer, error) {
+	f, err := os.Create(fileName) //nolint:gosec
+	if err != nil {
+		return nil, err
+	}
+	writer, err := NewWith(f)
+	if err != nil {
+		_ = f.Close()
+		return nil, err
+	}
+	return writer, nil
+}
+
+// NewWith initialize a new fragmented MP4 writer with an io.Writer output
+func NewWith(out io.Writer) (*MP4Writer, error) {
+	if out == nil {
+		return nil, errFileNotOpened
+	}
+
+	return &MP4Writer{
+		ioWriter: out,
+		now:      time.Now,
+	}, nil
+}
+
+// AddTrack adds a track to the file, all tracks must be added before the first packet is written
+func (w *MP4Writer) AddTrack(config TrackConfig) (*Track, error) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	if w.ioWriter == nil {
+		return nil, errFileNotOpened
+	} else if w.hasStart {
+		return nil, errTracksWritten
+	}
+
+	track, err := newTrack(w, uint32(len(w.tracks)+1), config)
+	if err != nil {
+		return nil, err
+	}
+	w.tracks = append(w.tracks, track)
+	return track, nil
+}
+
+// addSample adds a complete sample of a track. The previous sample of the track gets its
+// duration, a sample that isn't after it is dropped.
+func (w *MP4Writer) addSample(t *Track, s *mp4Sample) error {
+	if w.initWritten && !t.inInit || s.decodeTime < 0 {
+		return nil
+	}
+
+	if n := len(t.samples); n != 0 {
+		previous := t.samples[n-1]
+		if s.decodeTime <= previous.decodeTime {
+			return nil
+		}
+		previous.duration = uint32(s.decodeTime - previous.decodeTime)
+		t.lastDuration = previous.duration
+	}
+
+	if w.startsFragment(t, s) {
+		if err := w.writeFragment(false); err != nil {
+			return err
+		}
+	}
+	t.samples = append(t.samples, s)
+	return nil
+}
+
+// startsFragment tells if a sample starts a fragment
+func (w *MP4Writer) startsFragment(t *Track, s *mp4Sample) bool {
+	for _, track := range w.tracks {
+		if track.isVideo {
+			return track == t && s.isKey
+		}
+	}
+
+	return t == w.tracks[0] && len(t.samples) != 0 &&
+		time.Duration(s.decodeTime-t.samples[0].decodeTime)*time.Second/time.Duration(t.config.ClockRate) >= audioFragmentDuration
+}
+
+func (w *MP4Writer) write(data []byte) error {
+	_, err := w.ioWriter.Write(data)
+	return err
+}
+
+// writeInit writes the initialization segment, the ftyp and moov boxes
+func (w *MP4Writer) writeInit() error {
+	w.initWritten = true
+
+	ftyp := box("ftyp", []byte("iso5"), be32(512), []byte("iso5"), []byte("iso6"), []byte("cmfc"), []byte("mp41"))
+
+	traks := [][]byte{}
+	trexs := [][]byte{}
+	for _, t := range w.tracks {
+		if t.isVideo && (t.sps == nil || t.pps == nil) {
+			t.samples = nil
+			continue
+		}
+
+		t.inInit = true
+		traks = append(traks, w.trak(t))
+		trexs = append(trexs, fullBox("trex", 0, 0, be32(t.id), be32(1), be32(0), be32(0), be32(0)))
+	}
+
+	mvhd := fullBox("mvhd", 0, 0,
+		be32(0), be32(0), // creation_time, modification_time
+		be32(movieTimescale),
+		be32(0),                        // duration
+		be32(0x00010000), be16(0x0100), // rate, volume
+		make([]byte, 10),
+		unityMatrix(),
+		make([]byte, 24),
+		be32(uint32(len(w.tracks)+1)), // next_track_ID
+	)
+
+	moov := box("moov", append(append([][]byte{mvhd}, traks...), box("mvex", trexs...))...)
+	return w.write(append(ftyp, moov...))
+}
+
+func (w *MP4Writer) trak(t *Track) []byte {
+	var width, height uint16
+	handler, handlerName := "soun", "SoundHandler"
+	mediaHeader := fullBox("smhd", 0, 0, be16(0), be16(0))
+	volume := uint16(0x0100)
+	if t.isVideo {
+		width, height = t.videoSize()
+		handler, handlerName = "vide", "VideoHandler"
+		mediaHeader = fullBox("vmhd", 0, 1, be16(0), make([]byte, 6))
+		volume = 0
+	}
+
+	tkhd := fullBox("tkhd", 0, trackFlagsEnabledInMovie,
+		be32(0), be32(0), // creation_time, modification_time
+		be32(t.id),
+		be32(0), // reserved
+		be32(0), // duration
+		make([]byte, 8),
+		be16(0), be16(0), // layer, alternate_group
+		be16(volume), be16(0),
+		unityMatrix(),
+		be32(uint32(width)<<16), be32(uint32(height)<<16),
+	)
+
+	mdhd := fullBox("mdhd", 0, 0,
+		be32(0), be32(0), // creation_time, modification_time
+		be32(t.config.ClockRate),
+		be32(0), // duration
+		be16(languageUndetermined), be16(0),
+	)
+	hdlr := fullBox("hdlr", 0, 0, be32(0), []byte(handler), make([]byte, 12), []byte(handlerName), []byte{0})
+
+	dinf := box("dinf", fullBox("dref", 0, 0, be32(1), fullBox("url ", 0, 1)))
+	stbl := box("stbl",
+		fullBox("stsd", 0, 0, be32(1), w.sampleEntry(t, width, height)),
+		fullBox("stts", 0, 0, be32(0)),
+		fullBox("stsc", 0, 0, be32(0)),
+		fullBox("stsz", 0, 0, be32(0), be32(0)),
+		fullBox("stco", 0, 0, be32(0)),
+	)
+
+	return box("trak", tkhd, box("mdia", mdhd, hdlr, box("minf", mediaHeader, dinf, stbl)))
+}
+
+// sampleEntry describes the codec of a track, ISO/IEC 14496-15 Section 5.4.2 for H264 and
+// Encapsulation of Opus in ISO Base Media File Format Section 4.3 for Opus
+func (w *MP4Writer) sampleEntry(t *Track, width, height uint16) []byte {
+	if t.isVideo {
+		avcC := []byte{
+			1,                            // configurationVersion
+			t.sps[1], t.sps[2], t.sps[3], // profile, compatibility and level
+			0xFF, // lengthSizeMinusOne 3
+			0xE1, // numOfSequenceParameterSets 1
+		}
+		avcC = append(append(append(avcC, be16(uint16(len(t.sps)))...), t.sps...), 1)
+		avcC = append(append(avcC, be16(uint16(len(t.pps)))...), t.pps...)
+
+		return box("avc1",
+			make([]byte, 6), be16(1), // reserved, data_reference_index
+			make([]byte, 16),
+			be16(width), be16(height),
+			be32(0x00480000), be32(0x00480000), // 72 dpi
+			be32(0),
+			be16(1), // frame_count
+			make([]byte, 32),
+			be16(0x0018), be16(0xFFFF), // depth, pre_defined
+			box("avcC", avcC),
+		)
+	}
+
+	// The RTP stream has no information about the encoder delay, no samples are skipped
+	dOps := []byte{0, uint8(t.config.Channels)}
+	dOps = append(append(append(dOps, be16(0)...), be32(t.config.ClockRate)...), 0, 0, 0)
+
+	return box("Opus",
+		make([]byte, 6), be16(1), // reserved, data_reference_index
+		make([]byte, 8),
+		be16(t.config.Channels), be16(16), // channelcount, samplesize
+		be32(0),
+		be32(t.config.ClockRate<<16),
+		box("dOps", dOps),
+	)
+}
+
+// defaultDuration is the duration of the last sample of a track that has no previous one
+func (t *Track) defaultDuration() uint32 {
+	if t.isVideo {
+		return t.config.ClockRate / defaultVideoFrameRate
+	}
+	return t.config.ClockRate / defaultOpusFrameRate
+}
+
+// writeFragment writes the samples whose duration is known as a moof and mdat box, or all
+// samples if final. The initialization segment is written before the first fragment.
+func (w *MP4Writer) writeFragment(final bool) error {
+	fragment := make([][]*mp4Sample, len(w.tracks))
+	count := 0
+	for i, t := range w.tracks {
+		n := len(t.samples)
+		if !final && n != 0 && t.samples[n-1].duration == 0 {
+			n--
+		}
+		fragment[i] = t.samples[:n]
+		count += n
+	}
+	if count == 0 {
+		return nil
+	}
+
+	if !w.initWritten {
+		if err := w.writeInit(); err != nil {
+			return err
+		}
+	}
+
+	mdat := []byte{}
+	dataOffsets := make([]uint32, len(w.tracks))
+	for i, t := range w.tracks {
+		if !t.inInit {
+			fragment[i] = nil
+			continue
+		}
+
+		dataOffsets[i] = uint32(len(mdat))
+		for _, s := range fragment[i] {
+			if s.duration == 0 {
+				s.duration = t.lastDuration
+				if s.duration == 0 {
+					s.duration = t.defaultDuration()
+				}
+			}
+			mdat = append(mdat, s.data...)
+		}
+		t.samples = append([]*mp4Sample{}, t.samples[len(fragment[i]):]...)
+	}
+
+	// The data offsets are relative to the moof box, which has the same size with any offsets
+	w.sequenceNumber++
+	moofSize := uint32(len(w.moof(fragment, dataOffsets, 0)))
+	moof := w.moof(fragment, dataOffsets, moofSize+8)
+
+	return w.write(append(moof, box("mdat", mdat)...))
+}
+
+func (w *MP4Writer) moof(fragment [][]*mp4Sample, dataOffsets []uint32, mdatOffset uint32) []byte {
+	boxes := [][]byte{fullBox("mfhd", 0, 0, be32(w.sequenceNumber))}
+	for i, t := range w.tracks {
+		samples := fragment[i]
+		if len(samples) == 0 {
+			continue
+		}
+
+		trun := [][]byte{be32(uint32(len(samples))), be32(mdatOffset + dataOffsets[i])}
+		for _, s := range samples {
+			flags := uint32(sampleFlagsSync)
+			if !s.isKey {
+				flags = sampleFlagsNonSync
+			}
+			trun = append(trun, be32(s.duration), be32(uint32(len(s.data))), be32(flags))
+		}
+
+		boxes = append(boxes, box("traf",
+			fullBox("tfhd", 0, tfhdDefaultBaseIsMoof, be32(t.id)),
+			fullBox("tfdt", 1, 0, be64(uint64(samples[0].decodeTime))),
+			fullBox("trun", 0, trunDataOffsetPresent|trunSampleDuration|trunSampleSize|trunSampleFlags, trun...),
+		))
+	}
+	return box("moof", boxes...)
+}
+
+// Close writes the last fragment and closes the output
+func (w *MP4Writer) Close() error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	if w.ioWriter == nil {
+		// Returns no error as it may be convenient to call
+		// Close() multiple times
+		return nil
+	}
+
+	defer func() {
+		w.ioWriter = nil
+	}()
+
+	err := w.writeFragment(true)
+	if err == nil && !w.initWritten {
+		err = w.writeInit()
+	}
+	if closer, ok := w.ioWriter.(io.Closer); ok {
+		if closeErr := closer.Close(); err == nil {
+			err = closeErr
+		}
+	}
+	return err
+}
@@ -0,0 +1,284 @@
+package mp4writer
+
+import (
+	"bytes"
+	"encoding/binary"
+	"encoding/hex"
+	"testing"
+	"time"
+
+	"github.com/pion/rtp"
+	"github.com/stretchr/testify/assert"
+)
+
+type mp4Box struct {
+	boxType string
+	offset  int
+	data    []byte
+}
+
+// parseBoxes reads the boxes of data, offset is the position of data in the file
+func parseBoxes(t *testing.T, data []byte, offset int) []mp4Box {
+	boxes := []mp4Box{}
+	for i := 0; i < len(data); {
+		if !assert.GreaterOrEqual(t, len(data)-i, 8) {
+			return boxes
+		}
+
+		size := int(binary.BigEndian.Uint32(data[i:]))
+		if !assert.GreaterOrEqual(t, size, 8) || !assert.LessOrEqual(t, i+size, len(data)) {
+			return boxes
+		}
+
+		boxes = append(boxes, mp4Box{boxType: string(data[i+4 : i+8]), offset: offset + i, data: data[i+8 : i+size]})
+		i += size
+	}
+	return boxes
+}
+
+// findBox follows a path of box types, the payload of the first match is returned
+func findBox(t *testing.T, data []byte, path ...string) []byte {
+	for _, boxType := range path {
+		found := false
+		for _, b := range parseBoxes(t, data, 0) {
+			if b.boxType == boxType {
+				data, found = b.data, true
+				break
+			}
+		}
+		if !assert.True(t, found, boxType) {
+			return nil
+		}
+	}
+	return data
+}
+
+type fragmentSample struct {
+	decodeTime uint64
+	duration   uint32
+	isKey      bool
+	data       []byte
+}
+
+// readFragments returns the samples of each track of each fragment of the file
+func readFragments(t *testing.T, file []byte) []map[uint32][]fragmentSample {
+	fragments := []map[uint32][]fragmentSample{}
+	for _, moof := range parseBoxes(t, file, 0) {
+		if moof.boxType != "moof" {
+			continue
+		}
+
+		fragment := map[uint32][]fragmentSample{}
+		for _, traf := range parseBoxes(t, moof.data, 0) {
+			if traf.boxType != "traf" {
+				continue
+			}
+
+			trackID := binary.BigEndian.Uint32(findBox(t, traf.data, "tfhd")[4:])
+			decodeTime := binary.BigEndian.Uint64(findBox(t, traf.data, "tfdt")[4:])
+			trun := findBox(t, traf.data, "trun")
+			count := binary.BigEndian.Uint32(trun[4:])
+			dataOffset := moof.offset + int(binary.BigEndian.Uint32(trun[8:]))
+
+			for i := 0; i < int(count); i++ {
+				entry := trun[12+i*12:]
+				duration := binary.BigEndian.Uint32(entry)
+				size := int(binary.BigEndian.Uint32(entry[4:]))
+				fragment[trackID] = append(fragment[trackID], fragmentSample{
+					decodeTime: decodeTime,
+					duration:   duration,
+					isKey:      binary.BigEndian.Uint32(entry[8:]) == sampleFlagsSync,
+					data:       file[dataOffset : dataOffset+size],
+				})
+				decodeTime += uint64(duration)
+				dataOffset += size
+			}
+		}
+		fragments = append(fragments, fragment)
+	}
+	return fragments
+}
+
+func avcc(nals ...[]byte) []byte {
+	out := []byte{}
+	for _, nal := range nals {
+		out = append(out, be32(uint32(len(nal)))...)
+		out = append(out, nal...)
+	}
+	return out
+}
+
+var (
+	baselineSPS, _ = hex.DecodeString("6742c01fda014016e4") // 1280x720
+	highSPS, _     = hex.DecodeString("67640028ad84406ca03c0113f2a0")
+	testPPS        = []byte{0x68, 0xCE, 0x38, 0x80}
+)
+
+func TestMP4Writer_H264Opus(t *testing.T) {
+	now := time.Now()
+	out := &bytes.Buffer{}
+	writer, err := NewWith(out)
+	assert.NoError(t, err)
+	writer.now = func() time.Time { return now }
+
+	video, err := writer.AddTrack(TrackConfig{MimeType: mimeTypeH264})
+	assert.NoError(t, err)
+	audio, err := writer.AddTrack(TrackConfig{MimeType: mimeTypeOpus})
+	assert.NoError(t, err)
+
+	_, err = writer.AddTrack(TrackConfig{MimeType: "video/VP8"})
+	assert.ErrorIs(t, err, errNoSuchCodec)
+
+	stapA := []byte{0x18}
+	stapA = append(append(stapA, be16(uint16(len(baselineSPS)))...), baselineSPS...)
+	stapA = append(append(stapA, be16(uint16(len(testPPS)))...), testPPS...)
+
+	videoPacket := func(sequenceNumber uint16, timestamp uint32, marker bool, payload ...byte) func() {
+		return func() {
+			assert.NoError(t, video.WriteRTP(&rtp.Packet{Header: rtp.Header{SequenceNumber: sequenceNumber, Timestamp: timestamp, Marker: marker}, Payload: payload}))
+		}
+	}
+	audioPacket := func(sequenceNumber uint16, timestamp uint32, payload ...byte) func() {
+		return func() {
+			assert.NoError(t, audio.WriteRTP(&rtp.Packet{Header: rtp.Header{SequenceNumber: sequenceNumber, Timestamp: timestamp}, Payload: payload}))
+		}
+	}
+
+	for _, write := range []func(){
+		// Parameter sets in a STAP-A, followed by an IDR picture in FU-As
+		videoPacket(1, 1000, false, stapA...),
+		videoPacket(2, 1000, false, 0x7C, 0x85, 0xAA),
+		videoPacket(3, 1000, true, 0x7C, 0x45, 0xBB),
+		videoPacket(4, 4000, true, 0x41, 0x01, 0x11),
+
+		// The audio arrives 100ms after the video
+		func() { now = now.Add(100 * time.Millisecond) },
+		audioPacket(1, 500, 0x01),
+		audioPacket(2, 1460, 0x02),
+
+		// An IDR picture starts a fragment
+		videoPacket(5, 7000, true, 0x65, 0x02, 0x22),
+		audioPacket(3, 2420, 0x03),
+
+		// An access unit that misses its first packet is dropped
+		videoPacket(7, 10000, false, 0x7C, 0x05, 0xCC),
+		videoPacket(8, 10000, true, 0x7C, 0x45, 0xDD),
+		videoPacket(9, 13000, true, 0x41, 0x04, 0x44),
+	} {
+		write()
+	}
+
+	_, err = writer.AddTrack(TrackConfig{MimeType: mimeTypeOpus})
+	assert.ErrorIs(t, err, errTracksWritten)
+	assert.ErrorIs(t, video.WriteRTP(nil), errInvalidNilPacket)
+
+	assert.NoError(t, writer.Close())
+	assert.NoError(t, writer.Close())
+	assert.ErrorIs(t, audio.WriteRTP(&rtp.Packet{Payload: []byte{0x04}}), errFileNotOpened)
+
+	boxes := parseBoxes(t, out.Bytes(), 0)
+	boxTypes := []string{}
+	for _, b := range boxes {
+		boxTypes = append(boxTypes, b.boxType)
+	}
+	assert.Equal(t, []string{"ftyp", "moov", "moof", "mdat", "moof", "mdat"}, boxTypes)
+
+	traks := []mp4Box{}
+	for _, b := range parseBoxes(t, boxes[1].data, 0) {
+		if b.boxType == "trak" {
+			traks = append(traks, b)
+		}
+	}
+	assert.Len(t, traks, 2)
+	assert.Len(t, parseBoxes(t, findBox(t, boxes[1].data, "mvex"), 0), 2)
+
+	tkhd := findBox(t, traks[0].data, "tkhd")
+	assert.Equal(t, uint32(1), binary.BigEndian.Uint32(tkhd[12:]))
+	assert.Equal(t, uint32(1280<<16), binary.BigEndian.Uint32(tkhd[76:]))
+	assert.Equal(t, uint32(720<<16), binary.BigEndian.Uint32(tkhd[80:]))
+	assert.Equal(t, uint32(90000), binary.BigEndian.Uint32(findBox(t, traks[0].data, "mdia", "mdhd")[12:]))
+
+	avc1 := findBox(t, traks[0].data, "mdia", "minf", "stbl", "stsd")[8:]
+	avcC := findBox(t, findBox(t, avc1, "avc1")[78:], "avcC")
+	expectedAvcC := append([]byte{1, 0x42, 0xC0, 0x1F, 0xFF, 0xE1, 0x00, byte(len(baselineSPS))}, baselineSPS...)
+	expectedAvcC = append(append(expectedAvcC, 1, 0x00, byte(len(testPPS))), testPPS...)
+	assert.Equal(t, expectedAvcC, avcC)
+
+	assert.Equal(t, uint32(48000), binary.BigEndian.Uint32(findBox(t, traks[1].data, "mdia", "mdhd")[12:]))
+	opus := findBox(t, findBox(t, traks[1].data, "mdia", "minf", "stbl", "stsd")[8:], "Opus")
+	assert.Equal(t, uint16(2), binary.BigEndian.Uint16(opus[16:]))
+	assert.Equal(t, []byte{0, 2, 0, 0, 0, 0, 0xBB, 0x80, 0, 0, 0}, findBox(t, opus[28:], "dOps"))
+
+	assert.Equal(t, []map[uint32][]fragmentSample{
+		{
+			1: {
+				{decodeTime: 0, duration: 3000, isKey: true, data: avcc(baselineSPS, testPPS, []byte{0x65, 0xAA, 0xBB})},
+				{decodeTime: 3000, duration: 3000, isKey: false, data: avcc([]byte{0x41, 0x01, 0x11})},
+			},
+			2: {
+				{decodeTime: 4800, duration: 960, isKey: true, data: []byte{0x01}},
+			},
+		},
+		{
+			1: {
+				{decodeTime: 6000, duration: 6000, isKey: true, data: avcc([]byte{0x65, 0x02, 0x22})},
+				{decodeTime: 12000, duration: 6000, isKey: false, data: avcc([]byte{0x41, 0x04, 0x44})},
+			},
+			2: {
+				{decodeTime: 5760, duration: 960, isKey: true, data: []byte{0x02}},
+				{decodeTime: 6720, duration: 960, isKey: true, data: []byte{0x03}},
+			},
+		},
+	}, readFragments(t, out.Bytes()))
+}
+
+func TestMP4Writer_Opus(t *testing.T) {
+	out := &bytes.Buffer{}
+	writer, err := NewWith(out)
+	assert.NoError(t, err)
+
+	audio, err := writer.AddTrack(TrackConfig{MimeType: mimeTypeOpus, Channels: 1})
+	assert.NoError(t, err)
+
+	// Fragments of 2 seconds, the RTP timestamps wrap around
+	for i, timestamp := range []uint32{4294919296, 0, 48000, 96000} {
+		assert.NoError(t, audio.WriteRTP(&rtp.Packet{Header: rtp.Header{SequenceNumber: uint16(i), Timestamp: timestamp}, Payload: []byte{byte(i)}}))
+	}
+	assert.NoError(t, writer.Close())
+
+	opus := findBox(t, findBox(t, out.Bytes(), "moov", "trak", "mdia", "minf", "stbl", "stsd")[8:], "Opus")
+	assert.Equal(t, uint16(1), binary.BigEndian.Uint16(opus[16:]))
+
+	assert.Equal(t, []map[uint32][]fragmentSample{
+		{
+			1: {
+				{decodeTime: 0, duration: 48000, isKey: true, data: []byte{0x00}},
+				{decodeTime: 48000, duration: 48000, isKey: true, data: []byte{0x01}},
+			},
+		},
+		{
+			1: {
+				{decodeTime: 96000, duration: 48000, isKey: true, data: []byte{0x02}},
+				{decodeTime: 144000, duration: 48000, isKey: true, data: []byte{0x03}},
+			},
+		},
+	}, readFragments(t, out.Bytes()))
+}
+
+func TestSPSSize(t *testing.T) {
+	for _, test := range []struct {
+		sps           []byte
+		width, height uint16
+		err           error
+	}{
+		{sps: baselineSPS, width: 1280, height: 720},
+		{sps: highSPS, width: 1920, height: 1080},
+		{sps: baselineSPS[:5], err: errShortSPS},
+		{sps: []byte{0x67, 0x42}, err: errShortSPS},
+	} {
+		width, height, err := spsSize(test.sps)
+		assert.ErrorIs(t, err, test.err)
+		assert.Equal(t, test.width, width)
+		assert.Equal(t, test.height, height)
+	}
+}
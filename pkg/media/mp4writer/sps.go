@@ -0,0 +1,243 @@
+package mp4writer
+
+// bitReader reads the fields of a H264 RBSP
+type bitReader struct {
+	data   []byte
+	offset int
+}
+
+func (r *bitReader) readBits(n int) (uint32, error) {
+	v := uint32(0)
+	for i := 0; i < n; i++ {
+		if r.offset >= len(r.data)*8 {
+			return 0, errShortSPS
+		}
+
+		v = v<<1 | uint32(r.data[r.offset/8]>>(7-r.offset%8)&0x01)
+		r.offset++
+	}
+	return v, nil
+}
+
+// readUE reads an unsigned Exp-Golomb code
+func (r *bitReader) readUE() (uint32, error) {
+	leadingZeros := 0
+	for {
+		b, err := r.readBits(1)
+		if err != nil {
+			return 0, err
+		} else if b == 1 {
+			break
+		}
+
+		if leadingZeros++; leadingZeros > 31 {
+			return 0, errShortSPS
+		}
+	}
+
+	v, err := r.readBits(leadingZeros)
+	return 1<<leadingZeros - 1 + v, err
+}
+
+// readUEs reads n Exp-Golomb codes and returns the last one. Signed codes have the same
+// length, so they are skipped with it too.
+func (r *bitReader) readUEs(n int) (v uint32, err error) {
+	for i := 0; i < n && err == nil; i++ {
+		v, err = r.readUE()
+	}
+	return v, err
+}
+
+// readSE reads a signed Exp-Golomb code
+func (r *bitReader) readSE() (int32, error) {
+	v, err := r.readUE()
+	if v%2 == 0 {
+		return -int32(v / 2), err
+	}
+	return int32(v/2) + 1, err
+}
+
+// skipScalingList skips a scaling_list() of the SPS, H.264 Section 7.3.2.1.1.1
+func (r *bitReader) skipScalingList(size int) error {
+	lastScale, nextScale := int32(8), int32(8)
+	for i := 0; i < size; i++ {
+		if nextScale != 0 {
+			delta, err := r.readSE()
+			if err != nil {
+				return err
+			}
+			nextScale = (lastScale + delta + 256) % 256
+		}
+		if nextScale != 0 {
+			lastScale = nextScale
+		}
+	}
+	return nil
+}
+
+// rbsp removes the emulation prevention bytes of a NAL
+func rbsp(nal []byte) []byte {
+	out := make([]byte, 0, len(nal))
+	zeros := 0
+	for _, b := range nal {
+		if zeros >= 2 && b == 0x03 {
+			zeros = 0
+			continue
+		}
+
+		if b == 0x00 {
+			zeros++
+		} else {
+			zeros = 0
+		}
+		out = append(out, b)
+	}
+	return out
+}
+
+// spsSize returns the size of the pictures described by a SPS, H.264 Section 7.3.2.1.1
+func spsSize(sps []byte) (width uint16, height uint16, err error) { //nolint:gocognit
+	if len(sps) < 4 {
+		return 0, 0, errShortSPS
+	}
+
+	profileIdc := sps[1]
+	r := &bitReader{data: rbsp(sps[4:])}
+
+	// seq_parameter_set_id
+	if _, err = r.readUE(); err != nil {
+		return 0, 0, err
+	}
+
+	chromaFormatIdc, separateColourPlane := uint32(1), uint32(0)
+	switch profileIdc {
+	case 100, 110, 122, 244, 44, 83, 86, 118, 128, 138, 139, 134, 135:
+		if chromaFormatIdc, err = r.readUE(); err != nil {
+			return 0, 0, err
+		}
+		if chromaFormatIdc == 3 {
+			if separateColourPlane, err = r.readBits(1); err != nil {
+				return 0, 0, err
+			}
+		}
+
+		// bit_depth_luma_minus8, bit_depth_chroma_minus8
+		if _, err = r.readUEs(2); err != nil {
+			return 0, 0, err
+		}
+
+		// qpprime_y_zero_transform_bypass_flag, seq_scaling_matrix_present_flag
+		flags, flagsErr := r.readBits(2)
+		if flagsErr != nil {
+			return 0, 0, flagsErr
+		}
+		if flags&0x01 != 0 {
+			scalingLists := 8
+			if chromaFormatIdc == 3 {
+				scalingLists = 12
+			}
+			for i := 0; i < scalingLists; i++ {
+				present, presentErr := r.readBits(1)
+				if presentErr != nil {
+					return 0, 0, presentErr
+				}
+
+				size := 64
+				if i < 6 {
+					size = 16
+				}
+				if present == 1 {
+					if err = r.skipScalingList(size); err != nil {
+						return 0, 0, err
+					}
+				}
+			}
+		}
+	}
+
+	// log2_max_frame_num_minus4, pic_order_cnt_type
+	picOrderCntType, err := r.readUEs(2)
+	if err != nil {
+		return 0, 0, err
+	}
+	switch picOrderCntType {
+	case 0:
+		// log2_max_pic_order_cnt_lsb_minus4
+		if _, err = r.readUE(); err != nil {
+			return 0, 0, err
+		}
+	case 1:
+		// delta_pic_order_always_zero_flag, offset_for_non_ref_pic, offset_for_top_to_bottom_field
+		if _, err = r.readBits(1); err != nil {
+			return 0, 0, err
+		}
+		if _, err = r.readUEs(2); err != nil {
+			return 0, 0, err
+		}
+
+		numRefFrames, numErr := r.readUE()
+		if numErr != nil {
+			return 0, 0, numErr
+		}
+		if _, err = r.readUEs(int(numRefFrames)); err != nil {
+			return 0, 0, err
+		}
+	}
+
+	// max_num_ref_frames, gaps_in_frame_num_value_allowed_flag
+	if _, err = r.readUE(); err != nil {
+		return 0, 0, err
+	}
+	if _, err = r.readBits(1); err != nil {
+		return 0, 0, err
+	}
+
+	widthInMbs, err := r.readUE()
+	if err != nil {
+		return 0, 0, err
+	}
+	heightInMapUnits, err := r.readUE()
+	if err != nil {
+		return 0, 0, err
+	}
+	frameMbsOnly, err := r.readBits(1)
+	if err != nil {
+		return 0, 0, err
+	}
+	if frameMbsOnly == 0 {
+		// mb_adaptive_frame_field_flag
+		if _, err = r.readBits(1); err != nil {
+			return 0, 0, err
+		}
+	}
+
+	// direct_8x8_inference_flag, frame_cropping_flag
+	flags, err := r.readBits(2)
+	if err != nil {
+		return 0, 0, err
+	}
+
+	crop := [4]uint32{}
+	if flags&0x01 != 0 {
+		for i := range crop {
+			if crop[i], err = r.readUE(); err != nil {
+				return 0, 0, err
+			}
+		}
+	}
+
+	// The cropping is in chroma samples, H.264 Section 7.4.2.1.1
+	cropUnitX, cropUnitY := uint32(1), 2-frameMbsOnly
+	if separateColourPlane == 0 && chromaFormatIdc != 0 {
+		if chromaFormatIdc != 3 {
+			cropUnitX = 2
+		}
+		if chromaFormatIdc == 1 {
+			cropUnitY *= 2
+		}
+	}
+
+	width = uint16((widthInMbs+1)*16 - cropUnitX*(crop[0]+crop[1]))
+	height = uint16((2-frameMbsOnly)*(heightInMapUnits+1)*16 - cropUnitY*(crop[2]+crop[3]))
+	return width, height, nil
+}
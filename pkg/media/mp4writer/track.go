@@ -0,0 +1,284 @@
+package mp4writer
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"io"
+	"strings"
+	"time"
+
+	"github.com/pion/rtp"
+	"github.com/pion/rtp/codecs"
+	"github.com/pion/webrtc/v3/pkg/media/h264reader"
+)
+
+const (
+	mimeTypeH264 = "video/H264"
+	mimeTypeOpus = "audio/opus"
+
+	defaultVideoClockRate = 90000
+	defaultOpusClockRate  = 48000
+	defaultWidth          = 640
+	defaultHeight         = 480
+	defaultChannels       = 2
+
+	h264TypeFUA = 28
+)
+
+// TrackConfig describes a track of the MP4 file
+type TrackConfig struct {
+	// MimeType is the codec of the track: video/H264 or audio/opus
+	MimeType string
+
+	// ClockRate of the RTP timestamps, 90000 for video and 48000 for Opus by default.
+	// It is the timescale of the track.
+	ClockRate uint32
+
+	// Width and Height of a video track. They are taken from the SPS by default.
+	Width, Height uint16
+
+	// Channels of an audio track, 2 by default
+	Channels uint16
+}
+
+type mp4Sample struct {
+	decodeTime int64
+	duration   uint32
+	data       []byte
+	isKey      bool
+}
+
+// Track writes the RTP packets of a single track to the MP4 file, it implements media.Writer
+type Track struct {
+	writer  *MP4Writer
+	id      uint32
+	config  TrackConfig
+	isVideo bool
+
+	// Depacketization
+	hasSequenceNumber  bool
+	lastSequenceNumber uint16
+	h264Packet         *codecs.H264Packet
+	inFrame            bool
+	frameTimestamp     int64
+	frameNALs          []*h264reader.NAL
+	skipping           bool
+	skipTimestamp      int64
+	sps, pps           []byte
+	seenKeyFrame       bool
+
+	// Timing, RTP timestamps are unwrapped to int64
+	hasTimestamp  bool
+	lastTimestamp uint32
+	unwrapped     int64
+	hasBase       bool
+	baseTimestamp int64
+	startOffset   int64
+
+	// Samples that aren't written yet, the last one until its duration is known
+	samples      []*mp4Sample
+	lastDuration uint32
+	inInit       bool
+}
+
+func newTrack(w *MP4Writer, id uint32, config TrackConfig) (*Track, error) {
+	t := &Track{writer: w, id: id, config: config}
+
+	switch {
+	case strings.EqualFold(config.MimeType, mimeTypeH264):
+		t.isVideo = true
+		t.h264Packet = &codecs.H264Packet{}
+	case strings.EqualFold(config.MimeType, mimeTypeOpus):
+	default:
+		return nil, errNoSuchCodec
+	}
+
+	if t.config.ClockRate == 0 {
+		t.config.ClockRate = defaultOpusClockRate
+		if t.isVideo {
+			t.config.ClockRate = defaultVideoClockRate
+		}
+	}
+	if t.config.Channels == 0 {
+		t.config.Channels = defaultChannels
+	}
+
+	return t, nil
+}
+
+// WriteRTP adds the content of an RTP packet of the track to the file
+func (t *Track) WriteRTP(packet *rtp.Packet) error {
+	if packet == nil {
+		return errInvalidNilPacket
+	}
+
+	t.writer.mu.Lock()
+	defer t.writer.mu.Unlock()
+
+	if t.writer.ioWriter == nil {
+		return errFileNotOpened
+	} else if len(packet.Payload) == 0 {
+		return nil
+	}
+
+	timestamp := t.unwrap(packet.Timestamp)
+	if !t.writer.hasStart {
+		t.writer.hasStart = true
+		t.writer.start = t.writer.now()
+	}
+	if !t.hasBase {
+		// The tracks are aligned with the arrival time of their first packet
+		t.hasBase = true
+		t.baseTimestamp = timestamp
+		t.startOffset = int64(t.writer.now().Sub(t.writer.start) * time.Duration(t.config.ClockRate) / time.Second)
+	}
+
+	isGap := t.hasSequenceNumber && packet.SequenceNumber != t.lastSequenceNumber+1
+	t.hasSequenceNumber = true
+	t.lastSequenceNumber = packet.SequenceNumber
+
+	if !t.isVideo {
+		return t.writer.addSample(t, &mp4Sample{
+			decodeTime: timestamp - t.baseTimestamp + t.startOffset,
+			data:       append([]byte{}, packet.Payload...),
+			isKey:      true,
+		})
+	}
+	return t.depacketizeH264(packet, timestamp, isGap)
+}
+
+// Close ends the track. The frame that is written when Close is called is dropped,
+// the file stays open until MP4Writer.Close is called.
+func (t *Track) Close() error {
+	t.writer.mu.Lock()
+	defer t.writer.mu.Unlock()
+
+	t.inFrame = false
+	return nil
+}
+
+// unwrap extends an RTP timestamp to 64 bits, relative to the previous one
+func (t *Track) unwrap(timestamp uint32) int64 {
+	if !t.hasTimestamp {
+		t.hasTimestamp = true
+		t.lastTimestamp = timestamp
+		t.unwrapped = int64(timestamp)
+		return t.unwrapped
+	}
+
+	unwrapped := t.unwrapped + int64(int32(timestamp-t.lastTimestamp))
+	if unwrapped > t.unwrapped {
+		t.lastTimestamp, t.unwrapped = timestamp, unwrapped
+	}
+	return unwrapped
+}
+
+// depacketizeH264 collects the NALs of an access unit, which ends with the marker bit or
+// a packet of the next access unit
+func (t *Track) depacketizeH264(packet *rtp.Packet, timestamp int64, isGap bool) error {
+	if isGap {
+		// The access unit that misses a packet is dropped, and the fragmentation
+		// units that follow it
+		t.inFrame = false
+		t.h264Packet = &codecs.H264Packet{}
+		if timestamp == t.frameTimestamp || packet.Payload[0]&0x1F == h264TypeFUA && len(packet.Payload) > 1 && packet.Payload[1]&0x80 == 0 {
+			t.skipping, t.skipTimestamp = true, timestamp
+		}
+	}
+	if t.skipping && timestamp == t.skipTimestamp {
+		return nil
+	}
+	t.skipping = false
+
+	if t.inFrame && timestamp != t.frameTimestamp {
+		if err := t.finishH264Frame(); err != nil {
+			return err
+		}
+	}
+	if !t.inFrame {
+		t.inFrame = true
+		t.frameTimestamp = timestamp
+		t.frameNALs = nil
+	}
+
+	data, err := t.h264Packet.Unmarshal(packet.Payload)
+	if err != nil {
+		t.inFrame = false
+		return err
+	}
+
+	if len(data) != 0 {
+		reader, readerErr := h264reader.NewReader(bytes.NewReader(data))
+		if readerErr != nil {
+			return readerErr
+		}
+		for {
+			nal, nalErr := reader.NextNAL()
+			if errors.Is(nalErr, io.EOF) {
+				break
+			} else if nalErr != nil {
+				return nalErr
+			}
+			t.frameNALs = append(t.frameNALs, nal)
+		}
+	}
+
+	if packet.Marker {
+		return t.finishH264Frame()
+	}
+	return nil
+}
+
+// finishH264Frame converts the access unit to a sample, with the NALs prefixed by their length.
+// The parameter sets are kept for the sample entry, samples before the first IDR picture
+// that follows them are dropped.
+func (t *Track) finishH264Frame() error {
+	t.inFrame = false
+
+	data := []byte{}
+	isKey := false
+	for _, nal := range t.frameNALs {
+		switch nal.UnitType { //nolint:exhaustive
+		case h264reader.NalUnitTypeAUD:
+			continue
+		case h264reader.NalUnitTypeSPS:
+			if len(nal.Data) >= 4 {
+				t.sps = append([]byte{}, nal.Data...)
+			}
+		case h264reader.NalUnitTypePPS:
+			t.pps = append([]byte{}, nal.Data...)
+		case h264reader.NalUnitTypeCodedSliceIdr:
+			isKey = true
+		}
+
+		length := make([]byte, 4)
+		binary.BigEndian.PutUint32(length, uint32(len(nal.Data)))
+		data = append(data, length...)
+		data = append(data, nal.Data...)
+	}
+	t.frameNALs = nil
+
+	if len(data) == 0 || !t.seenKeyFrame && (!isKey || t.sps == nil || t.pps == nil) {
+		return nil
+	}
+	t.seenKeyFrame = true
+
+	return t.writer.addSample(t, &mp4Sample{
+		decodeTime: t.frameTimestamp - t.baseTimestamp + t.startOffset,
+		data:       data,
+		isKey:      isKey,
+	})
+}
+
+// videoSize returns the size of a video track from the config, its SPS or the default
+func (t *Track) videoSize() (uint16, uint16) {
+	if t.config.Width != 0 && t.config.Height != 0 {
+		return t.config.Width, t.config.Height
+	}
+
+	if width, height, err := spsSize(t.sps); err == nil && width != 0 && height != 0 {
+		return width, height
+	}
+	return defaultWidth, defaultHeight
+}
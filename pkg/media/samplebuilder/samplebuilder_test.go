@@ -7,6 +7,7 @@ import (
 
 	"github.com/pion/rtp"
 	"github.com/pion/webrtc/v3/pkg/media"
+	"github.com/pion/webrtc/v3/pkg/media/h265packet"
 	"github.com/stretchr/testify/assert"
 )
 
@@ -430,6 +431,57 @@ func TestSampleBuilderData(t *testing.T) {
 	assert.Equal(t, j, 0x1FFFF)
 }
 
+func TestSampleBuilderH265(t *testing.T) {
+	annexB := func(nalus ...[]byte) []byte {
+		out := []byte{}
+		for _, nalu := range nalus {
+			out = append(append(out, 0x00, 0x00, 0x00, 0x01), nalu...)
+		}
+		return out
+	}
+	keyFrame := annexB(
+		[]byte{0x40, 0x01, 0x0C, 0x01},                         // VPS
+		[]byte{0x42, 0x01, 0x01, 0x01, 0x60},                   // SPS
+		[]byte{0x44, 0x01, 0xC1, 0x73},                         // PPS
+		[]byte{0x26, 0x01, 0xAF, 0x06, 0xB8, 0x63, 0xEF, 0x3A}, // IDR
+	)
+	frame := annexB([]byte{0x02, 0x01, 0xD0, 0x09, 0x7E, 0x10, 0xC2, 0x34, 0x8A, 0x4F})
+
+	s := New(10, &h265packet.Packet{}, 90000)
+	payloader := &h265packet.Payloader{}
+	sequenceNumber := uint16(0)
+	samples := []*media.Sample{}
+	push := func(timestamp uint32, data []byte, skipFirst bool) {
+		payloads := payloader.Payload(8, data)
+		for i, payload := range payloads {
+			sequenceNumber++
+			if i == 0 && skipFirst {
+				continue
+			}
+			s.Push(&rtp.Packet{
+				Header:  rtp.Header{SequenceNumber: sequenceNumber, Timestamp: timestamp, Marker: i == len(payloads)-1},
+				Payload: payload,
+			})
+			for sample := s.Pop(); sample != nil; sample = s.Pop() {
+				samples = append(samples, sample)
+			}
+		}
+	}
+
+	push(0, keyFrame, false)
+	push(3000, frame, true) // The first fragment is lost
+	for timestamp := uint32(6000); timestamp <= 30000; timestamp += 3000 {
+		push(timestamp, frame, false)
+	}
+
+	// The frame without its first fragment is dropped
+	assert.Len(t, samples, 9)
+	assert.Equal(t, []*media.Sample{
+		{Data: keyFrame, Duration: time.Second / 30, PacketTimestamp: 0},
+		{Data: frame, Duration: time.Second / 30, PacketTimestamp: 6000, PrevDroppedPackets: 2},
+	}, samples[:2])
+}
+
 func BenchmarkSampleBuilderSequential(b *testing.B) {
 	s := New(100, &fakeDepacketizer{}, 1)
 	b.ResetTimer()
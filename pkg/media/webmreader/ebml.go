@@ -0,0 +1,153 @@
+package webmreader
+
+import (
+	"encoding/binary"
+	"errors"
+	"io"
+	"math"
+	"math/bits"
+)
+
+// Matroska element IDs, see https://www.matroska.org/technical/elements.html
+const (
+	idEBML    = 0x1A45DFA3
+	idDocType = 0x4282
+
+	idSegment = 0x18538067
+
+	idInfo          = 0x1549A966
+	idTimecodeScale = 0x2AD7B1
+	idDuration      = 0x4489
+
+	idTracks            = 0x1654AE6B
+	idTrackEntry        = 0xAE
+	idTrackNumber       = 0xD7
+	idTrackType         = 0x83
+	idCodecID           = 0x86
+	idCodecPrivate      = 0x63A2
+	idCodecDelay        = 0x56AA
+	idVideo             = 0xE0
+	idPixelWidth        = 0xB0
+	idPixelHeight       = 0xBA
+	idAudio             = 0xE1
+	idSamplingFrequency = 0xB5
+	idChannels          = 0x9F
+
+	idCluster        = 0x1F43B675
+	idTimecode       = 0xE7
+	idSimpleBlock    = 0xA3
+	idBlockGroup     = 0xA0
+	idBlock          = 0xA1
+	idReferenceBlock = 0xFB
+)
+
+// ebmlUnknownSize is the size of an element that is written before its size is known
+const ebmlUnknownSize = math.MaxUint64
+
+type ebmlElement struct {
+	id   uint32
+	data []byte
+}
+
+// readVint reads a variable length integer from data, the marker bit is kept for IDs
+func readVint(data []byte, keepMarker bool) (uint64, int, error) {
+	if len(data) == 0 || data[0] == 0 {
+		return 0, 0, errInvalidElement
+	}
+
+	length := bits.LeadingZeros8(data[0]) + 1
+	if len(data) < length {
+		return 0, 0, errInvalidElement
+	}
+
+	value := uint64(data[0])
+	if !keepMarker {
+		value &= 0xFF >> length
+	}
+	allOnes := value == 0xFF>>length
+	for _, b := range data[1:length] {
+		value = value<<8 | uint64(b)
+		allOnes = allOnes && b == 0xFF
+	}
+
+	if allOnes && !keepMarker {
+		return ebmlUnknownSize, length, nil
+	}
+	return value, length, nil
+}
+
+// readStreamVint reads a variable length integer from a stream, it returns io.EOF if
+// the stream ends before it
+func readStreamVint(stream io.Reader, keepMarker bool) (uint64, int, error) {
+	data := make([]byte, 1, 8)
+	if _, err := io.ReadFull(stream, data); err != nil {
+		return 0, 0, err
+	} else if data[0] == 0 {
+		return 0, 0, errInvalidElement
+	}
+
+	data = data[:bits.LeadingZeros8(data[0])+1]
+	if _, err := io.ReadFull(stream, data[1:]); err != nil {
+		return 0, 0, errIncompleteElementHeader
+	}
+	return readVint(data, keepMarker)
+}
+
+// readElementHeader reads the ID and size of an element from a stream, it returns
+// io.EOF if the stream ends before the element
+func readElementHeader(stream io.Reader) (uint32, uint64, int64, error) {
+	id, idLength, err := readStreamVint(stream, true)
+	if err != nil {
+		return 0, 0, 0, err
+	} else if idLength > 4 {
+		return 0, 0, 0, errInvalidElement
+	}
+
+	size, sizeLength, err := readStreamVint(stream, false)
+	if errors.Is(err, io.EOF) {
+		return 0, 0, 0, errIncompleteElementHeader
+	} else if err != nil {
+		return 0, 0, 0, err
+	}
+
+	return uint32(id), size, int64(idLength + sizeLength), nil
+}
+
+// readElements reads the child elements of a master element
+func readElements(data []byte) ([]ebmlElement, error) {
+	elements := []ebmlElement{}
+	for len(data) != 0 {
+		id, idLength, err := readVint(data, true)
+		if err != nil || idLength > 4 {
+			return nil, errInvalidElement
+		}
+		size, sizeLength, err := readVint(data[idLength:], false)
+		if err != nil || size > uint64(len(data)-idLength-sizeLength) {
+			return nil, errInvalidElement
+		}
+
+		start := idLength + sizeLength
+		elements = append(elements, ebmlElement{id: uint32(id), data: data[start : start+int(size)]})
+		data = data[start+int(size):]
+	}
+	return elements, nil
+}
+
+func readUint(data []byte) uint64 {
+	v := uint64(0)
+	for _, b := range data {
+		v = v<<8 | uint64(b)
+	}
+	return v
+}
+
+func readFloat(data []byte) float64 {
+	switch len(data) {
+	case 4:
+		return float64(math.Float32frombits(binary.BigEndian.Uint32(data)))
+	case 8:
+		return math.Float64frombits(binary.BigEndian.Uint64(data))
+	default:
+		return 0
+	}
+}
@@ -0,0 +1,363 @@
+// Package webmreader implements a WebM media container reader
+package webmreader
+
+import (
+	"errors"
+	"io"
+	"time"
+)
+
+const (
+	defaultTimecodeScale = 1000000
+
+	// maxElementSize bounds the elements that are read into memory
+	maxElementSize = 1 << 28
+
+	// Flags of a SimpleBlock, Matroska Section 10.2
+	blockFlagKeyFrame = 0x80
+	blockFlagsLacing  = 0x06
+)
+
+var (
+	errNilStream               = errors.New("stream is nil")
+	errNotWebM                 = errors.New("stream is not a WebM or Matroska file")
+	errIncompleteElement       = errors.New("incomplete element")
+	errIncompleteFileHeader    = errors.New("incomplete file header, the tracks are missing")
+	errUnknownSizeElement      = errors.New("element of unknown size isn't a Segment or Cluster")
+	errInvalidBlock            = errors.New("invalid block")
+	errLacingNotSupported      = errors.New("laced blocks are not supported")
+	errInvalidElement          = errors.New("invalid EBML element")
+	errIncompleteElementHeader = errors.New("incomplete element header")
+)
+
+// WebMTrack describes a track of a WebM file
+type WebMTrack struct {
+	Number  uint64
+	CodecID string
+	// MimeType of the codec, empty if it isn't VP8, VP9, AV1 or Opus
+	MimeType     string
+	CodecPrivate []byte
+
+	// Video tracks
+	Width, Height uint64
+
+	// Audio tracks
+	SamplingFrequency float64
+	Channels          uint64
+	CodecDelay        time.Duration
+}
+
+// WebMHeader is the metadata of a WebM file, from the elements before the first Cluster
+type WebMHeader struct {
+	// Duration is zero if the file doesn't have one, while it is written for example
+	Duration time.Duration
+	Tracks   []*WebMTrack
+}
+
+// WebMFrame is a frame of a track of a WebM file
+type WebMFrame struct {
+	TrackNumber uint64
+	// Timestamp is the presentation timestamp of the frame
+	Timestamp  time.Duration
+	IsKeyFrame bool
+	Data       []byte
+}
+
+// WebMReader is used to read WebM files and return the frames of their tracks
+// in the order of the file
+type WebMReader struct {
+	stream               io.Reader
+	bytesReadSuccesfully int64
+	timecodeScale        uint64
+	clusterTimecode      int64
+}
+
+// NewWith returns a new WebM reader and the WebM header with an io.Reader input.
+// The file is read up to the Tracks element.
+func NewWith(in io.Reader) (*WebMReader, *WebMHeader, error) {
+	if in == nil {
+		return nil, nil, errNilStream
+	}
+
+	reader := &WebMReader{
+		stream:        in,
+		timecodeScale: defaultTimecodeScale,
+	}
+
+	header, err := reader.parseFileHeader()
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return reader, header, nil
+}
+
+// ResetReader resets the internal stream of WebMReader. This is useful
+// for live streams, where the end of the file might be read without the
+// data being finished.
+func (r *WebMReader) ResetReader(reset func(bytesRead int64) io.Reader) {
+	r.stream = reset(r.bytesReadSuccesfully)
+}
+
+// ParseNextFrame reads from stream and returns the next frame of any track, and
+// an error if there is incomplete data. Returns io.EOF when no more frames are
+// available.
+func (r *WebMReader) ParseNextFrame() (*WebMFrame, error) {
+	for {
+		id, data, length, err := r.readElement()
+		if err != nil {
+			return nil, err
+		}
+
+		var frame *WebMFrame
+		switch id {
+		case idTimecode:
+			r.clusterTimecode = int64(readUint(data))
+		case idSimpleBlock:
+			frame, err = r.parseBlock(data, false)
+		case idBlockGroup:
+			frame, err = r.parseBlockGroup(data)
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		r.bytesReadSuccesfully += length
+		if frame != nil {
+			return frame, nil
+		}
+	}
+}
+
+// readElement reads the next element from stream and returns its ID, data and length. The
+// Segment and Clusters are entered, their children are returned one by one so they can be
+// read while they are written. The caller counts an element as read once it is processed.
+func (r *WebMReader) readElement() (uint32, []byte, int64, error) {
+	for {
+		id, size, headerLength, err := readElementHeader(r.stream)
+		if err != nil {
+			return 0, nil, 0, err
+		}
+
+		if id == idSegment || id == idCluster {
+			r.bytesReadSuccesfully += headerLength
+			continue
+		} else if size == ebmlUnknownSize {
+			return 0, nil, 0, errUnknownSizeElement
+		} else if size > maxElementSize {
+			return 0, nil, 0, errInvalidElement
+		}
+
+		data := make([]byte, size)
+		if _, err = io.ReadFull(r.stream, data); err != nil {
+			return 0, nil, 0, errIncompleteElement
+		}
+
+		return id, data, headerLength + int64(size), nil
+	}
+}
+
+// parseFileHeader reads the EBML header, and the Info and Tracks of the Segment.
+// This is always called before ParseNextFrame()
+func (r *WebMReader) parseFileHeader() (*WebMHeader, error) {
+	id, size, headerLength, err := readElementHeader(r.stream)
+	if errors.Is(err, io.EOF) || errors.Is(err, errIncompleteElementHeader) {
+		return nil, errIncompleteFileHeader
+	} else if err != nil || id != idEBML || size > maxElementSize {
+		return nil, errNotWebM
+	}
+
+	data := make([]byte, size)
+	if _, err = io.ReadFull(r.stream, data); err != nil {
+		return nil, errIncompleteFileHeader
+	}
+
+	ebmlHeader, err := readElements(data)
+	if err != nil {
+		return nil, err
+	}
+	docType := ""
+	for _, e := range ebmlHeader {
+		if e.id == idDocType {
+			docType = string(e.data)
+		}
+	}
+	if docType != "webm" && docType != "matroska" {
+		return nil, errNotWebM
+	}
+	r.bytesReadSuccesfully += headerLength + int64(size)
+
+	header := &WebMHeader{}
+	for header.Tracks == nil {
+		var length int64
+		id, data, length, err = r.readElement()
+		if errors.Is(err, io.EOF) {
+			return nil, errIncompleteFileHeader
+		} else if err != nil {
+			return nil, err
+		}
+
+		switch id {
+		case idInfo:
+			err = r.parseInfo(header, data)
+		case idTracks:
+			header.Tracks, err = parseTracks(data)
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		r.bytesReadSuccesfully += length
+	}
+
+	return header, nil
+}
+
+func (r *WebMReader) parseInfo(header *WebMHeader, data []byte) error {
+	elements, err := readElements(data)
+	if err != nil {
+		return err
+	}
+
+	duration := 0.0
+	for _, e := range elements {
+		switch e.id {
+		case idTimecodeScale:
+			if r.timecodeScale = readUint(e.data); r.timecodeScale == 0 {
+				r.timecodeScale = defaultTimecodeScale
+			}
+		case idDuration:
+			duration = readFloat(e.data)
+		}
+	}
+
+	header.Duration = time.Duration(duration * float64(r.timecodeScale))
+	return nil
+}
+
+func parseTracks(data []byte) ([]*WebMTrack, error) {
+	entries, err := readElements(data)
+	if err != nil {
+		return nil, err
+	}
+
+	tracks := []*WebMTrack{}
+	for _, entry := range entries {
+		if entry.id != idTrackEntry {
+			continue
+		}
+
+		elements, entryErr := readElements(entry.data)
+		if entryErr != nil {
+			return nil, entryErr
+		}
+
+		track := &WebMTrack{}
+		for _, e := range elements {
+			switch e.id {
+			case idTrackNumber:
+				track.Number = readUint(e.data)
+			case idCodecID:
+				track.CodecID = string(e.data)
+				track.MimeType = codecMimeType(track.CodecID)
+			case idCodecPrivate:
+				track.CodecPrivate = e.data
+			case idCodecDelay:
+				track.CodecDelay = time.Duration(readUint(e.data))
+			case idVideo, idAudio:
+				if err = parseTrackSettings(track, e.data); err != nil {
+					return nil, err
+				}
+			}
+		}
+		tracks = append(tracks, track)
+	}
+
+	return tracks, nil
+}
+
+func codecMimeType(codecID string) string {
+	switch codecID {
+	case "V_VP8":
+		return "video/VP8"
+	case "V_VP9":
+		return "video/VP9"
+	case "V_AV1":
+		return "video/AV1"
+	case "A_OPUS":
+		return "audio/opus"
+	default:
+		return ""
+	}
+}
+
+func parseTrackSettings(track *WebMTrack, data []byte) error {
+	elements, err := readElements(data)
+	if err != nil {
+		return err
+	}
+
+	for _, e := range elements {
+		switch e.id {
+		case idPixelWidth:
+			track.Width = readUint(e.data)
+		case idPixelHeight:
+			track.Height = readUint(e.data)
+		case idSamplingFrequency:
+			track.SamplingFrequency = readFloat(e.data)
+		case idChannels:
+			track.Channels = readUint(e.data)
+		}
+	}
+	return nil
+}
+
+// parseBlockGroup reads the Block of a BlockGroup, it is a key frame if it doesn't
+// reference other frames
+func (r *WebMReader) parseBlockGroup(data []byte) (*WebMFrame, error) {
+	elements, err := readElements(data)
+	if err != nil {
+		return nil, err
+	}
+
+	var block []byte
+	isKeyFrame := true
+	for _, e := range elements {
+		switch e.id {
+		case idBlock:
+			block = e.data
+		case idReferenceBlock:
+			isKeyFrame = false
+		}
+	}
+	if block == nil {
+		return nil, errInvalidBlock
+	}
+
+	frame, err := r.parseBlock(block, true)
+	if frame != nil {
+		frame.IsKeyFrame = isKeyFrame
+	}
+	return frame, err
+}
+
+// parseBlock reads a SimpleBlock or the Block of a BlockGroup, Matroska Section 10
+func (r *WebMReader) parseBlock(data []byte, inBlockGroup bool) (*WebMFrame, error) {
+	trackNumber, length, err := readVint(data, false)
+	if err != nil || len(data) < length+3 {
+		return nil, errInvalidBlock
+	}
+
+	flags := data[length+2]
+	if flags&blockFlagsLacing != 0 {
+		return nil, errLacingNotSupported
+	}
+
+	timecode := r.clusterTimecode + int64(int16(uint16(data[length])<<8|uint16(data[length+1])))
+	return &WebMFrame{
+		TrackNumber: trackNumber,
+		Timestamp:   time.Duration(timecode * int64(r.timecodeScale)),
+		IsKeyFrame:  !inBlockGroup && flags&blockFlagKeyFrame != 0,
+		Data:        data[length+3:],
+	}, nil
+}
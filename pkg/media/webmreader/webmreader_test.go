@@ -0,0 +1,178 @@
+package webmreader
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"io"
+	"math"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+const unknownSize = -1
+
+// element builds an EBML element with an 8 byte size, or an unknown size
+func element(id uint32, size int, payload ...[]byte) []byte {
+	out := []byte{}
+	for shift := 24; shift >= 0; shift -= 8 {
+		if b := byte(id >> shift); b != 0 || len(out) != 0 {
+			out = append(out, b)
+		}
+	}
+
+	data := bytes.Join(payload, nil)
+	sizeBytes := make([]byte, 8)
+	if size == unknownSize {
+		binary.BigEndian.PutUint64(sizeBytes, 0x01FFFFFFFFFFFFFF)
+	} else {
+		binary.BigEndian.PutUint64(sizeBytes, 0x0100000000000000|uint64(len(data)))
+	}
+	return append(append(out, sizeBytes...), data...)
+}
+
+func uintElement(id uint32, v uint64) []byte {
+	data := make([]byte, 8)
+	binary.BigEndian.PutUint64(data, v)
+	return element(id, 0, data)
+}
+
+func block(id uint32, track byte, timecode int16, flags byte, data ...byte) []byte {
+	return element(id, 0, []byte{0x80 | track, byte(uint16(timecode) >> 8), byte(timecode), flags}, data)
+}
+
+func buildWebM(docType string, clusters ...[]byte) []byte {
+	duration := make([]byte, 8)
+	binary.BigEndian.PutUint64(duration, math.Float64bits(2500))
+
+	samplingFrequency := make([]byte, 4)
+	binary.BigEndian.PutUint32(samplingFrequency, math.Float32bits(48000))
+
+	file := element(idEBML, 0, element(idDocType, 0, []byte(docType)))
+	return append(file, element(idSegment, unknownSize,
+		element(0x114D9B74, 0, []byte{0x00}), // SeekHead, skipped
+		element(idInfo, 0, uintElement(idTimecodeScale, 1000000), element(idDuration, 0, duration)),
+		element(idTracks, 0,
+			element(idTrackEntry, 0,
+				uintElement(idTrackNumber, 1),
+				element(idCodecID, 0, []byte("V_VP8")),
+				element(idVideo, 0, uintElement(idPixelWidth, 640), uintElement(idPixelHeight, 480)),
+			),
+			element(idTrackEntry, 0,
+				uintElement(idTrackNumber, 2),
+				element(idCodecID, 0, []byte("A_OPUS")),
+				element(idCodecPrivate, 0, []byte("OpusHead")),
+				uintElement(idCodecDelay, 6500000),
+				element(idAudio, 0, element(idSamplingFrequency, 0, samplingFrequency), uintElement(idChannels, 2)),
+			),
+		),
+		bytes.Join(clusters, nil),
+	)...)
+}
+
+func buildTestFile() ([]byte, []*WebMFrame) {
+	file := buildWebM("webm",
+		element(idCluster, unknownSize,
+			uintElement(idTimecode, 1000),
+			block(idSimpleBlock, 1, 0, 0x80, 0xAA),
+			block(idSimpleBlock, 2, 5, 0x00, 0xBB),
+			element(idBlockGroup, 0, block(idBlock, 1, 33, 0x00, 0xCC), element(idReferenceBlock, 0, []byte{0xDF})),
+			element(0x1C53BB6B, 0, []byte{0x00}), // Cues, skipped
+		),
+		element(idCluster, 0,
+			uintElement(idTimecode, 2000),
+			block(idSimpleBlock, 2, -2, 0x80, 0xDD, 0xEE),
+			element(idBlockGroup, 0, block(idBlock, 1, 0, 0x00, 0xFF)),
+		),
+	)
+
+	return file, []*WebMFrame{
+		{TrackNumber: 1, Timestamp: 1000 * time.Millisecond, IsKeyFrame: true, Data: []byte{0xAA}},
+		{TrackNumber: 2, Timestamp: 1005 * time.Millisecond, IsKeyFrame: false, Data: []byte{0xBB}},
+		{TrackNumber: 1, Timestamp: 1033 * time.Millisecond, IsKeyFrame: false, Data: []byte{0xCC}},
+		{TrackNumber: 2, Timestamp: 1998 * time.Millisecond, IsKeyFrame: true, Data: []byte{0xDD, 0xEE}},
+		{TrackNumber: 1, Timestamp: 2000 * time.Millisecond, IsKeyFrame: true, Data: []byte{0xFF}},
+	}
+}
+
+func TestWebMReader_ParseFile(t *testing.T) {
+	file, expectedFrames := buildTestFile()
+
+	reader, header, err := NewWith(bytes.NewReader(file))
+	assert.NoError(t, err)
+
+	assert.Equal(t, 2500*time.Millisecond, header.Duration)
+	assert.Equal(t, []*WebMTrack{
+		{Number: 1, CodecID: "V_VP8", MimeType: "video/VP8", Width: 640, Height: 480},
+		{
+			Number: 2, CodecID: "A_OPUS", MimeType: "audio/opus", CodecPrivate: []byte("OpusHead"),
+			SamplingFrequency: 48000, Channels: 2, CodecDelay: 6500 * time.Microsecond,
+		},
+	}, header.Tracks)
+
+	frames := []*WebMFrame{}
+	for {
+		frame, err := reader.ParseNextFrame()
+		if errors.Is(err, io.EOF) {
+			break
+		} else if !assert.NoError(t, err) {
+			return
+		}
+		frames = append(frames, frame)
+	}
+	assert.Equal(t, expectedFrames, frames)
+	assert.Equal(t, int64(len(file)), reader.bytesReadSuccesfully)
+}
+
+func TestWebMReader_ResetReader(t *testing.T) {
+	file, expectedFrames := buildTestFile()
+
+	// The file is read while it is written, up to any length
+	for length := 0; length <= len(file); length++ {
+		reader, _, err := NewWith(bytes.NewReader(file[:length]))
+		if err != nil {
+			continue
+		}
+
+		frames := []*WebMFrame{}
+		for i := 0; i < 2*len(expectedFrames); i++ {
+			frame, err := reader.ParseNextFrame()
+			if err != nil {
+				reader.ResetReader(func(bytesRead int64) io.Reader {
+					return bytes.NewReader(file[bytesRead:])
+				})
+				continue
+			}
+			frames = append(frames, frame)
+		}
+		assert.Equal(t, expectedFrames, frames, length)
+	}
+}
+
+func TestWebMReader_Errors(t *testing.T) {
+	_, _, err := NewWith(nil)
+	assert.ErrorIs(t, err, errNilStream)
+
+	_, _, err = NewWith(bytes.NewReader([]byte("DKIF")))
+	assert.ErrorIs(t, err, errNotWebM)
+
+	_, _, err = NewWith(bytes.NewReader(buildWebM("mkv")))
+	assert.ErrorIs(t, err, errNotWebM)
+
+	_, _, err = NewWith(bytes.NewReader(element(idEBML, 0, element(idDocType, 0, []byte("webm")))))
+	assert.ErrorIs(t, err, errIncompleteFileHeader)
+
+	file := buildWebM("matroska", element(idCluster, 0, uintElement(idTimecode, 0), block(idSimpleBlock, 1, 0, 0x82, 0x01, 0xAA, 0xBB)))
+	reader, _, err := NewWith(bytes.NewReader(file))
+	assert.NoError(t, err)
+	_, err = reader.ParseNextFrame()
+	assert.ErrorIs(t, err, errLacingNotSupported)
+
+	file = buildWebM("webm", element(idCluster, 0, element(idBlockGroup, 0, element(idReferenceBlock, 0, []byte{0x01}))))
+	reader, _, err = NewWith(bytes.NewReader(file))
+	assert.NoError(t, err)
+	_, err = reader.ParseNextFrame()
+	assert.ErrorIs(t, err, errInvalidBlock)
+}
@@ -0,0 +1,155 @@
+package webmwriter
+
+import (
+	"encoding/binary"
+	"math"
+)
+
+// Matroska element IDs, see https://www.matroska.org/technical/elements.html
+const (
+	idEBML               = 0x1A45DFA3
+	idEBMLVersion        = 0x4286
+	idEBMLReadVersion    = 0x42F7
+	idEBMLMaxIDLength    = 0x42F2
+	idEBMLMaxSizeLength  = 0x42F3
+	idDocType            = 0x4282
+	idDocTypeVersion     = 0x4287
+	idDocTypeReadVersion = 0x4285
+
+	idSegment      = 0x18538067
+	idSeekHead     = 0x114D9B74
+	idSeek         = 0x4DBB
+	idSeekID       = 0x53AB
+	idSeekPosition = 0x53AC
+	idVoid         = 0xEC
+
+	idInfo          = 0x1549A966
+	idTimecodeScale = 0x2AD7B1
+	idMuxingApp     = 0x4D80
+	idWritingApp    = 0x5741
+	idDuration      = 0x4489
+
+	idTracks            = 0x1654AE6B
+	idTrackEntry        = 0xAE
+	idTrackNumber       = 0xD7
+	idTrackUID          = 0x73C5
+	idTrackType         = 0x83
+	idCodecID           = 0x86
+	idCodecPrivate      = 0x63A2
+	idCodecDelay        = 0x56AA
+	idSeekPreRoll       = 0x56BB
+	idVideo             = 0xE0
+	idPixelWidth        = 0xB0
+	idPixelHeight       = 0xBA
+	idAudio             = 0xE1
+	idSamplingFrequency = 0xB5
+	idChannels          = 0x9F
+
+	idCluster     = 0x1F43B675
+	idTimecode    = 0xE7
+	idSimpleBlock = 0xA3
+
+	idCues               = 0x1C53BB6B
+	idCuePoint           = 0xBB
+	idCueTime            = 0xB3
+	idCueTrackPositions  = 0xB7
+	idCueTrack           = 0xF7
+	idCueClusterPosition = 0xF1
+)
+
+const (
+	// ebmlUnknownSize is the size of an element whose size is not known yet, encoded in 8 bytes
+	ebmlUnknownSize = 0x01FFFFFFFFFFFFFF
+
+	// ebmlSizeLength8 is the marker of a size that is encoded in 8 bytes
+	ebmlSizeLength8 = 0x0100000000000000
+)
+
+// ebmlID encodes an element ID, the IDs above already contain their length marker
+func ebmlID(id uint32) []byte {
+	switch {
+	case id > 0xFFFFFF:
+		return []byte{byte(id >> 24), byte(id >> 16), byte(id >> 8), byte(id)}
+	case id > 0xFFFF:
+		return []byte{byte(id >> 16), byte(id >> 8), byte(id)}
+	case id > 0xFF:
+		return []byte{byte(id >> 8), byte(id)}
+	default:
+		return []byte{byte(id)}
+	}
+}
+
+// ebmlSize encodes the size of an element as a variable size integer of the minimal length
+func ebmlSize(size uint64) []byte {
+	length := 1
+	for ; length < 8; length++ {
+		// All ones are reserved for the unknown size
+		if size < 1<<(7*length)-1 {
+			break
+		}
+	}
+
+	out := make([]byte, length)
+	value := size | 1<<(7*length)
+	for i := length - 1; i >= 0; i-- {
+		out[i] = byte(value)
+		value >>= 8
+	}
+	return out
+}
+
+// ebmlSize8 encodes the size of an element in 8 bytes, so that it can be replaced later
+func ebmlSize8(size uint64) []byte {
+	out := make([]byte, 8)
+	binary.BigEndian.PutUint64(out, ebmlSizeLength8|size)
+	return out
+}
+
+func ebmlElement(id uint32, data ...[]byte) []byte {
+	size := 0
+	for _, d := range data {
+		size += len(d)
+	}
+
+	out := append(ebmlID(id), ebmlSize(uint64(size))...)
+	for _, d := range data {
+		out = append(out, d...)
+	}
+	return out
+}
+
+func ebmlUint(id uint32, v uint64) []byte {
+	length := 1
+	for length < 8 && v>>(8*length) != 0 {
+		length++
+	}
+
+	data := make([]byte, length)
+	for i := length - 1; i >= 0; i-- {
+		data[i] = byte(v)
+		v >>= 8
+	}
+	return ebmlElement(id, data)
+}
+
+// ebmlUint8 encodes an unsigned integer in 8 bytes, so that it can be replaced later
+func ebmlUint8(id uint32, v uint64) []byte {
+	data := make([]byte, 8)
+	binary.BigEndian.PutUint64(data, v)
+	return ebmlElement(id, data)
+}
+
+func ebmlFloat(id uint32, v float64) []byte {
+	data := make([]byte, 8)
+	binary.BigEndian.PutUint64(data, math.Float64bits(v))
+	return ebmlElement(id, data)
+}
+
+func ebmlString(id uint32, s string) []byte {
+	return ebmlElement(id, []byte(s))
+}
+
+// ebmlVoid returns a Void element of length bytes in total, length is between 2 and 128
+func ebmlVoid(length int) []byte {
+	return ebmlElement(idVoid, make([]byte, length-2))
+}
@@ -0,0 +1,374 @@
+package webmwriter
+
+import (
+	"strings"
+	"time"
+
+	"github.com/pion/rtcp"
+	"github.com/pion/rtp"
+	"github.com/pion/rtp/codecs"
+	"github.com/pion/rtp/pkg/frame"
+	"github.com/pion/webrtc/v3/internal/vp9"
+)
+
+const (
+	mimeTypeVP8  = "video/VP8"
+	mimeTypeVP9  = "video/VP9"
+	mimeTypeAV1  = "video/AV1"
+	mimeTypeOpus = "audio/opus"
+
+	defaultVideoClockRate = 90000
+	defaultOpusClockRate  = 48000
+	defaultWidth          = 640
+	defaultHeight         = 480
+	defaultChannels       = 2
+
+	av1OBUTypeSequenceHeader    = 1
+	av1OBUTypeTemporalDelimiter = 2
+)
+
+// TrackConfig describes a track of the WebM file
+type TrackConfig struct {
+	// MimeType is the codec of the track: video/VP8, video/VP9, video/AV1 or audio/opus
+	MimeType string
+
+	// ClockRate of the RTP timestamps, 90000 for video and 48000 for Opus by default
+	ClockRate uint32
+
+	// Width and Height of a video track. They are taken from the first key frame
+	// for VP8 and are 640x480 otherwise by default.
+	Width, Height uint16
+
+	// Channels of an audio track, 2 by default
+	Channels uint16
+
+	// SSRC of the RTP stream. When it is zero the SSRC is taken from the first RTP
+	// packet, and sender reports written before it are ignored.
+	SSRC uint32
+}
+
+// Track writes the RTP packets of a single track to the WebM file
+type Track struct {
+	writer  *WebMWriter
+	number  uint64
+	config  TrackConfig
+	codecID string
+	isVideo bool
+
+	// Depacketization
+	hasSequenceNumber  bool
+	lastSequenceNumber uint16
+	inFrame            bool
+	frameTimestamp     int64
+	frameData          []byte
+	frameIsKey         bool
+	seenKeyFrame       bool
+	vp9Frames          [][]byte
+	av1Frame           frame.AV1
+
+	// Timing, RTP timestamps are unwrapped to int64
+	ssrc          uint32
+	hasSSRC       bool
+	hasTimestamp  bool
+	lastTimestamp uint32
+	unwrapped     int64
+
+	hasBase       bool
+	baseTime      time.Time
+	baseTimestamp int64
+
+	hasSenderReport       bool
+	senderReportTime      time.Time
+	senderReportTimestamp int64
+}
+
+func newTrack(w *WebMWriter, number uint64, config TrackConfig) (*Track, error) {
+	t := &Track{writer: w, number: number, config: config, ssrc: config.SSRC, hasSSRC: config.SSRC != 0}
+
+	switch {
+	case strings.EqualFold(config.MimeType, mimeTypeVP8):
+		t.codecID, t.isVideo = "V_VP8", true
+	case strings.EqualFold(config.MimeType, mimeTypeVP9):
+		t.codecID, t.isVideo = "V_VP9", true
+	case strings.EqualFold(config.MimeType, mimeTypeAV1):
+		t.codecID, t.isVideo = "V_AV1", true
+	case strings.EqualFold(config.MimeType, mimeTypeOpus):
+		t.codecID = "A_OPUS"
+	default:
+		return nil, errNoSuchCodec
+	}
+
+	if t.config.ClockRate == 0 {
+		t.config.ClockRate = defaultOpusClockRate
+		if t.isVideo {
+			t.config.ClockRate = defaultVideoClockRate
+		}
+	}
+	if t.config.Channels == 0 {
+		t.config.Channels = defaultChannels
+	}
+
+	return t, nil
+}
+
+// WriteRTP adds the content of an RTP packet of the track to the file
+func (t *Track) WriteRTP(packet *rtp.Packet) error {
+	if packet == nil {
+		return errInvalidNilPacket
+	}
+
+	t.writer.mu.Lock()
+	defer t.writer.mu.Unlock()
+
+	if t.writer.ioWriter == nil {
+		return errFileNotOpened
+	} else if len(packet.Payload) == 0 {
+		return nil
+	}
+
+	// A frame that misses a packet is dropped
+	if t.hasSequenceNumber && packet.SequenceNumber != t.lastSequenceNumber+1 {
+		t.inFrame = false
+	}
+	t.hasSequenceNumber = true
+	t.lastSequenceNumber = packet.SequenceNumber
+
+	if !t.hasSSRC {
+		t.hasSSRC = true
+		t.ssrc = packet.SSRC
+	}
+	timestamp := t.unwrap(packet.Timestamp)
+	if !t.hasBase {
+		// Until the tracks are synchronized with sender reports the arrival time is used
+		t.hasBase = true
+		t.baseTime = t.writer.now()
+		t.baseTimestamp = timestamp
+	}
+
+	var err error
+	switch t.codecID {
+	case "V_VP8":
+		err = t.depacketizeVP8(packet, timestamp)
+	case "V_VP9":
+		err = t.depacketizeVP9(packet, timestamp)
+	case "V_AV1":
+		err = t.depacketizeAV1(packet, timestamp)
+	default:
+		t.startFrame(timestamp, true)
+		err = t.appendFrame(packet.Payload, true)
+	}
+	return err
+}
+
+// WriteRTCP passes the RTCP packets of the track to the file, its sender reports are used
+// to synchronize the tracks. Sender reports of other SSRCs, or written before the SSRC of
+// the track is known, and all other packets are ignored.
+func (t *Track) WriteRTCP(packets []rtcp.Packet) error {
+	t.writer.mu.Lock()
+	defer t.writer.mu.Unlock()
+
+	if t.writer.ioWriter == nil {
+		return errFileNotOpened
+	}
+
+	for _, packet := range packets {
+		senderReport, ok := packet.(*rtcp.SenderReport)
+		if !ok || !t.hasSSRC || senderReport.SSRC != t.ssrc {
+			continue
+		}
+
+		t.hasSenderReport = true
+		t.senderReportTime = ntpToTime(senderReport.NTPTime)
+		t.senderReportTimestamp = t.unwrap(senderReport.RTPTime)
+	}
+
+	return nil
+}
+
+// Close ends the track. The frame that is written when Close is called is dropped,
+// the file stays open until WebMWriter.Close is called.
+func (t *Track) Close() error {
+	t.writer.mu.Lock()
+	defer t.writer.mu.Unlock()
+
+	t.inFrame = false
+	return nil
+}
+
+// unwrap extends an RTP timestamp to 64 bits, relative to the previous one
+func (t *Track) unwrap(timestamp uint32) int64 {
+	if !t.hasTimestamp {
+		t.hasTimestamp = true
+		t.lastTimestamp = timestamp
+		t.unwrapped = int64(timestamp)
+		return t.unwrapped
+	}
+
+	unwrapped := t.unwrapped + int64(int32(timestamp-t.lastTimestamp))
+	if unwrapped > t.unwrapped {
+		t.lastTimestamp, t.unwrapped = timestamp, unwrapped
+	}
+	return unwrapped
+}
+
+// time converts an unwrapped RTP timestamp to a time, based on the sender report the
+// tracks are synchronized with or the arrival of the first packet
+func (t *Track) time(timestamp int64) time.Time {
+	return t.baseTime.Add(time.Duration(timestamp-t.baseTimestamp) * time.Second / time.Duration(t.config.ClockRate))
+}
+
+func (t *Track) depacketizeVP8(packet *rtp.Packet, timestamp int64) error {
+	vp8Packet := codecs.VP8Packet{}
+	if _, err := vp8Packet.Unmarshal(packet.Payload); err != nil {
+		return err
+	}
+
+	if vp8Packet.S == 1 && vp8Packet.PID == 0 && len(vp8Packet.Payload) != 0 {
+		t.startFrame(timestamp, vp8Packet.Payload[0]&0x01 == 0)
+	}
+	return t.appendFrame(vp8Packet.Payload, packet.Marker)
+}
+
+func (t *Track) depacketizeVP9(packet *rtp.Packet, timestamp int64) error {
+	vp9Packet := codecs.VP9Packet{}
+	if _, err := vp9Packet.Unmarshal(packet.Payload); err != nil {
+		return err
+	}
+
+	// The frames of all spatial layers of a picture are written as a single block, in a superframe
+	if vp9Packet.B {
+		if !t.inFrame || t.frameTimestamp != timestamp {
+			t.startFrame(timestamp, !vp9Packet.P)
+			t.vp9Frames = nil
+		}
+		t.frameData = nil
+	}
+	if !t.inFrame {
+		return nil
+	}
+
+	t.frameData = append(t.frameData, vp9Packet.Payload...)
+	if vp9Packet.E {
+		t.vp9Frames = append(t.vp9Frames, t.frameData)
+		t.frameData = nil
+	}
+	if !packet.Marker {
+		return nil
+	}
+
+	frames := t.vp9Frames
+	t.vp9Frames = nil
+	if len(frames) == 0 {
+		t.inFrame = false
+		return nil
+	}
+	return t.appendFrame(vp9.Superframe(frames), true)
+}
+
+func (t *Track) depacketizeAV1(packet *rtp.Packet, timestamp int64) error {
+	av1Packet := codecs.AV1Packet{}
+	if _, err := av1Packet.Unmarshal(packet.Payload); err != nil {
+		return err
+	}
+
+	obus, err := t.av1Frame.ReadFrames(&av1Packet)
+	if err != nil {
+		return err
+	}
+
+	if !t.inFrame || t.frameTimestamp != timestamp {
+		t.startFrame(timestamp, false)
+	}
+
+	// A block holds a temporal unit in the low overhead bitstream format, all OBUs
+	// have a size field and there are no temporal delimiters
+	data := []byte{}
+	for _, obu := range obus {
+		if len(obu) == 0 {
+			continue
+		}
+
+		obuType := (obu[0] >> 3) & 0x0F
+		switch obuType {
+		case av1OBUTypeTemporalDelimiter:
+			continue
+		case av1OBUTypeSequenceHeader:
+			t.frameIsKey = true
+		}
+		data = append(data, av1OBUWithSize(obu)...)
+	}
+	return t.appendFrame(data, packet.Marker)
+}
+
+// av1OBUWithSize sets the size field of an OBU if it has none
+func av1OBUWithSize(obu []byte) []byte {
+	if obu[0]&0x02 != 0 {
+		return obu
+	}
+
+	headerLength := 1
+	if obu[0]&0x04 != 0 {
+		headerLength = 2
+	}
+	if len(obu) < headerLength {
+		return obu
+	}
+
+	out := append([]byte{obu[0] | 0x02}, obu[1:headerLength]...)
+	out = append(out, leb128(uint64(len(obu)-headerLength))...)
+	return append(out, obu[headerLength:]...)
+}
+
+func leb128(v uint64) []byte {
+	out := []byte{}
+	for {
+		b := byte(v & 0x7F)
+		v >>= 7
+		if v == 0 {
+			return append(out, b)
+		}
+		out = append(out, b|0x80)
+	}
+}
+
+func (t *Track) startFrame(timestamp int64, isKey bool) {
+	t.inFrame = true
+	t.frameTimestamp = timestamp
+	t.frameData = nil
+	t.frameIsKey = isKey
+}
+
+// appendFrame adds data to the current frame and passes the frame on if it is complete
+func (t *Track) appendFrame(data []byte, isComplete bool) error {
+	if !t.inFrame {
+		return nil
+	}
+	t.frameData = append(t.frameData, data...)
+
+	if !isComplete {
+		return nil
+	}
+	t.inFrame = false
+
+	// Video can't be decoded before the first key frame
+	if t.isVideo && !t.seenKeyFrame && !t.frameIsKey {
+		return nil
+	}
+	t.seenKeyFrame = true
+
+	return t.writer.addFrame(&webmFrame{
+		track:     t,
+		timestamp: t.frameTimestamp,
+		data:      t.frameData,
+		isKey:     t.frameIsKey,
+	})
+}
+
+// ntpToTime converts the 32.32 fixed point NTP time of a sender report
+func ntpToTime(ntp uint64) time.Time {
+	const ntpEpochOffset = 2208988800 // Seconds from 1900 to 1970
+
+	seconds := int64(ntp>>32) - ntpEpochOffset
+	nanoseconds := int64(((ntp&0xFFFFFFFF)*uint64(time.Second) + 1<<31) >> 32)
+	return time.Unix(seconds, nanoseconds)
+}
@@ -0,0 +1,496 @@
+// Package webmwriter implements a WebM media container writer that muxes audio and video tracks
+package webmwriter
+
+import (
+	"encoding/binary"
+	"errors"
+	"io"
+	"os"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/pion/randutil"
+)
+
+var (
+	errFileNotOpened    = errors.New("file not opened")
+	errInvalidNilPacket = errors.New("invalid nil packet")
+	errNoSuchCodec      = errors.New("no codec for this MimeType")
+	errTracksWritten    = errors.New("tracks can't be added after the first frame was written")
+)
+
+const (
+	// defaultSenderReportTimeout is how long frames are held back, waiting for a
+	// sender report of every track to synchronize them
+	defaultSenderReportTimeout = 2 * time.Second
+
+	// maxClusterDuration bounds the data that is lost if the writer isn't closed, and
+	// keeps the block timecodes within their 16 bit range
+	maxClusterDuration = 5000
+
+	// Reserved in the SeekHead for the position of the Cues, that are written by Close
+	cuesSeekLength = 21
+
+	// Reserved in the Info for the Duration, that is written by Close
+	durationLength = 11
+
+	// Opus decoders discard 80ms of audio after seeking, RFC 7845 Section 4.6
+	opusSeekPreRoll = 80 * time.Millisecond
+	opusPreSkip     = 3840
+)
+
+type webmFrame struct {
+	track     *Track
+	timestamp int64
+	data      []byte
+	isKey     bool
+}
+
+// headerPatch replaces a reserved part of the header
+type headerPatch struct {
+	offset uint64
+	data   []byte
+}
+
+type cuePoint struct {
+	time            int64
+	track           uint64
+	clusterPosition uint64
+}
+
+// WebMWriter is used to take RTP packets of several tracks and write them to a WebM file
+// on disk. The RTP timestamps of the tracks are converted to a common timeline with the
+// RTCP sender reports of the tracks, or with the arrival time of their first packet if
+// sender reports aren't passed to the tracks.
+//
+// Frames are written in clusters of at most 5 seconds, a file that isn't closed misses
+// the last cluster but is playable. Close writes the cues for seeking, and the duration
+// if the output is an io.WriteSeeker.
+type WebMWriter struct {
+	mu       sync.Mutex
+	ioWriter io.Writer
+	offset   uint64
+
+	tracks        []*Track
+	headerWritten bool
+
+	// Offsets of the parts of the header that are written by Close
+	segmentSizeOffset uint64
+	segmentDataOffset uint64
+	cuesSeekOffset    uint64
+	durationOffset    uint64
+
+	synchronized        bool
+	senderReportTimeout time.Duration
+	syncDeadline        time.Time
+	pending             []*webmFrame
+	origin              time.Time
+
+	cluster         []byte
+	clusterTimecode int64
+	hasCluster      bool
+	cues            []cuePoint
+	duration        int64
+
+	now func() time.Time
+}
+
+// New builds a new WebM writer
+func New(fileName string, opts ...Option) (*WebMWriter, error) {
+	f, err := os.Create(fileName) //nolint:gosec
+	if err != nil {
+		return nil, err
+	}
+	writer, err := NewWith(f, opts...)
+	if err != nil {
+		_ = f.Close()
+		return nil, err
+	}
+	return writer, nil
+}
+
+// NewWith initialize a new WebM writer with an io.Writer output
+func NewWith(out io.Writer, opts ...Option) (*WebMWriter, error) {
+	if out == nil {
+		return nil, errFileNotOpened
+	}
+
+	writer := &WebMWriter{
+		ioWriter:            out,
+		senderReportTimeout: defaultSenderReportTimeout,
+		now:                 time.Now,
+	}
+
+	for _, o := range opts {
+		if err := o(writer); err != nil {
+			return nil, err
+		}
+	}
+
+	return writer, nil
+}
+
+// AddTrack adds a track to the file, all tracks must be added before the first frame is written
+func (w *WebMWriter) AddTrack(config TrackConfig) (*Track, error) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	if w.ioWriter == nil {
+		return nil, errFileNotOpened
+	} else if w.headerWritten || len(w.pending) != 0 {
+		return nil, errTracksWritten
+	}
+
+	track, err := newTrack(w, uint64(len(w.tracks)+1), config)
+	if err != nil {
+		return nil, err
+	}
+	w.tracks = append(w.tracks, track)
+	return track, nil
+}
+
+// addFrame writes a complete frame of a track, or holds it back until the tracks are synchronized
+func (w *WebMWriter) addFrame(f *webmFrame) error {
+	if w.synchronized {
+		return w.writeFrame(f)
+	}
+
+	if len(w.pending) == 0 {
+		w.syncDeadline = w.now().Add(w.senderReportTimeout)
+	}
+	w.pending = append(w.pending, f)
+
+	return w.synchronize(false)
+}
+
+// synchronize fixes the timeline once every track has a sender report, or after the
+// timeout if force is false. The tracks are synchronized with their sender reports
+// if all have one, otherwise with the arrival time of their first packet.
+func (w *WebMWriter) synchronize(force bool) error {
+	haveSenderReports := true
+	for _, t := range w.tracks {
+		haveSenderReports = haveSenderReports && t.hasSenderReport
+	}
+	if !haveSenderReports && !force && w.now().Before(w.syncDeadline) {
+		return nil
+	}
+
+	if haveSenderReports {
+		for _, t := range w.tracks {
+			t.hasBase = true
+			t.baseTime = t.senderReportTime
+			t.baseTimestamp = t.senderReportTimestamp
+		}
+	}
+
+	w.synchronized = true
+	pending := w.pending
+	w.pending = nil
+	if len(pending) == 0 {
+		return nil
+	}
+
+	sort.SliceStable(pending, func(i, j int) bool {
+		return pending[i].track.time(pending[i].timestamp).Before(pending[j].track.time(pending[j].timestamp))
+	})
+	w.origin = pending[0].track.time(pending[0].timestamp)
+
+	if err := w.writeHeader(pending); err != nil {
+		return err
+	}
+	for _, f := range pending {
+		if err := w.writeFrame(f); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (w *WebMWriter) write(data []byte) error {
+	n, err := w.ioWriter.Write(data)
+	w.offset += uint64(n)
+	return err
+}
+
+func (w *WebMWriter) writeHeader(pending []*webmFrame) error {
+	w.headerWritten = true
+
+	header := ebmlElement(idEBML,
+		ebmlUint(idEBMLVersion, 1),
+		ebmlUint(idEBMLReadVersion, 1),
+		ebmlUint(idEBMLMaxIDLength, 4),
+		ebmlUint(idEBMLMaxSizeLength, 8),
+		ebmlString(idDocType, "webm"),
+		ebmlUint(idDocTypeVersion, 4),
+		ebmlUint(idDocTypeReadVersion, 2),
+	)
+
+	// The size of the Segment is written by Close, a file that isn't closed has a Segment of unknown size
+	header = append(header, ebmlID(idSegment)...)
+	w.segmentSizeOffset = uint64(len(header))
+	unknownSize := make([]byte, 8)
+	binary.BigEndian.PutUint64(unknownSize, ebmlUnknownSize)
+	header = append(header, unknownSize...)
+	w.segmentDataOffset = uint64(len(header))
+
+	info := ebmlElement(idInfo,
+		ebmlUint(idTimecodeScale, uint64(time.Millisecond)),
+		ebmlString(idMuxingApp, "pion"),
+		ebmlString(idWritingApp, "pion"),
+		ebmlVoid(durationLength),
+	)
+	tracks := w.tracksElement(pending)
+
+	// The SeekHead has a fixed length, Info and Tracks follow it
+	seekHeadLength := uint64(len(ebmlElement(idSeekHead, seekEntry(idInfo, 0), seekEntry(idTracks, 0), ebmlVoid(cuesSeekLength))))
+	seekHead := ebmlElement(idSeekHead,
+		seekEntry(idInfo, seekHeadLength),
+		seekEntry(idTracks, seekHeadLength+uint64(len(info))),
+		ebmlVoid(cuesSeekLength),
+	)
+	w.cuesSeekOffset = w.segmentDataOffset + uint64(len(seekHead)-cuesSeekLength)
+	w.durationOffset = w.segmentDataOffset + seekHeadLength + uint64(len(info)-durationLength)
+
+	header = append(header, seekHead...)
+	header = append(header, info...)
+	header = append(header, tracks...)
+	return w.write(header)
+}
+
+func seekEntry(id uint32, position uint64) []byte {
+	return ebmlElement(idSeek,
+		ebmlElement(idSeekID, ebmlID(id)),
+		ebmlUint8(idSeekPosition, position),
+	)
+}
+
+func (w *WebMWriter) tracksElement(pending []*webmFrame) []byte {
+	entries := [][]byte{}
+	random := randutil.NewMathRandomGenerator()
+
+	for _, t := range w.tracks {
+		entry := [][]byte{
+			ebmlUint(idTrackNumber, t.number),
+			ebmlUint(idTrackUID, random.Uint64()),
+			ebmlString(idCodecID, t.codecID),
+		}
+
+		if t.isVideo {
+			width, height := t.config.Width, t.config.Height
+			if width == 0 || height == 0 {
+				width, height = videoSize(t, pending)
+			}
+
+			entry = append(entry,
+				ebmlUint(idTrackType, 1),
+				ebmlElement(idVideo,
+					ebmlUint(idPixelWidth, uint64(width)),
+					ebmlUint(idPixelHeight, uint64(height)),
+				),
+			)
+		} else {
+			opusHead := make([]byte, 19)
+			copy(opusHead, "OpusHead")
+			opusHead[8] = 1 // Version
+			opusHead[9] = uint8(t.config.Channels)
+			binary.LittleEndian.PutUint16(opusHead[10:], opusPreSkip)
+			binary.LittleEndian.PutUint32(opusHead[12:], t.config.ClockRate)
+
+			entry = append(entry,
+				ebmlUint(idTrackType, 2),
+				ebmlElement(idCodecPrivate, opusHead),
+				ebmlUint(idCodecDelay, uint64(opusPreSkip*time.Second/defaultOpusClockRate)),
+				ebmlUint(idSeekPreRoll, uint64(opusSeekPreRoll)),
+				ebmlElement(idAudio,
+					ebmlFloat(idSamplingFrequency, float64(t.config.ClockRate)),
+					ebmlUint(idChannels, uint64(t.config.Channels)),
+				),
+			)
+		}
+
+		entries = append(entries, ebmlElement(idTrackEntry, entry...))
+	}
+
+	return ebmlElement(idTracks, entries...)
+}
+
+// videoSize returns the size of a video track from its first VP8 key frame, or the default
+func videoSize(t *Track, pending []*webmFrame) (uint16, uint16) {
+	if t.codecID == "V_VP8" {
+		for _, f := range pending {
+			// A key frame has a start code after the frame tag, followed by the size
+			if f.track == t && f.isKey && len(f.data) >= 10 && f.data[3] == 0x9D && f.data[4] == 0x01 && f.data[5] == 0x2A {
+				return binary.LittleEndian.Uint16(f.data[6:]) & 0x3FFF, binary.LittleEndian.Uint16(f.data[8:]) & 0x3FFF
+			}
+		}
+	}
+
+	return defaultWidth, defaultHeight
+}
+
+// writeFrame adds a frame to the current cluster. A video key frame starts a new
+// cluster, which is added to the cues.
+func (w *WebMWriter) writeFrame(f *webmFrame) error {
+	timecode := f.track.time(f.timestamp).Sub(w.origin).Milliseconds()
+	if timecode < 0 {
+		timecode = 0
+	}
+
+	isVideoKey := f.isKey && f.track.isVideo
+	if !w.hasCluster || isVideoKey && len(w.cluster) != 0 ||
+		timecode-w.clusterTimecode >= maxClusterDuration || timecode < w.clusterTimecode-maxClusterDuration {
+		if err := w.writeCluster(); err != nil {
+			return err
+		}
+
+		w.hasCluster = true
+		w.clusterTimecode = timecode
+		if isVideoKey || !w.hasVideo() {
+			w.cues = append(w.cues, cuePoint{
+				time:            timecode,
+				track:           f.track.number,
+				clusterPosition: w.offset - w.segmentDataOffset,
+			})
+		}
+	}
+
+	flags := byte(0)
+	if f.isKey {
+		flags = 0x80
+	}
+
+	block := ebmlSize(f.track.number)
+	block = append(block, byte(uint16(timecode-w.clusterTimecode)>>8), byte(timecode-w.clusterTimecode), flags)
+	w.cluster = append(w.cluster, ebmlElement(idSimpleBlock, block, f.data)...)
+
+	if timecode > w.duration {
+		w.duration = timecode
+	}
+	return nil
+}
+
+func (w *WebMWriter) hasVideo() bool {
+	for _, t := range w.tracks {
+		if t.isVideo {
+			return true
+		}
+	}
+	return false
+}
+
+// writeCluster writes the blocks of the current cluster, if any
+func (w *WebMWriter) writeCluster() error {
+	if len(w.cluster) == 0 {
+		return nil
+	}
+
+	cluster := ebmlElement(idCluster, ebmlUint(idTimecode, uint64(w.clusterTimecode)), w.cluster)
+	w.cluster = nil
+	return w.write(cluster)
+}
+
+func (w *WebMWriter) writeCues() error {
+	if len(w.cues) == 0 {
+		return nil
+	}
+
+	cuePoints := [][]byte{}
+	for _, c := range w.cues {
+		cuePoints = append(cuePoints, ebmlElement(idCuePoint,
+			ebmlUint(idCueTime, uint64(c.time)),
+			ebmlElement(idCueTrackPositions,
+				ebmlUint(idCueTrack, c.track),
+				ebmlUint(idCueClusterPosition, c.clusterPosition),
+			),
+		))
+	}
+	return w.write(ebmlElement(idCues, cuePoints...))
+}
+
+// finalize writes the rest of the file. If the output is an io.WriteSeeker the parts
+// of the header that depend on it are written afterwards, the file is complete
+// without them if that fails.
+func (w *WebMWriter) finalize() error {
+	if !w.synchronized {
+		if err := w.synchronize(true); err != nil {
+			return err
+		}
+	}
+	if !w.headerWritten {
+		if err := w.writeHeader(nil); err != nil {
+			return err
+		}
+	}
+
+	if err := w.writeCluster(); err != nil {
+		return err
+	}
+
+	cuesPosition := w.offset - w.segmentDataOffset
+	if err := w.writeCues(); err != nil {
+		return err
+	}
+
+	ws, ok := w.ioWriter.(io.WriteSeeker)
+	if !ok {
+		return nil
+	}
+
+	patches := []headerPatch{
+		{w.durationOffset, ebmlFloat(idDuration, float64(w.duration))},
+		{w.segmentSizeOffset, ebmlSize8(w.offset - w.segmentDataOffset)},
+	}
+	if len(w.cues) != 0 {
+		patches = append(patches, headerPatch{w.cuesSeekOffset, seekEntry(idCues, cuesPosition)})
+	}
+
+	for _, p := range patches {
+		if _, err := ws.Seek(int64(p.offset), io.SeekStart); err != nil {
+			return err
+		}
+		if _, err := ws.Write(p.data); err != nil {
+			return err
+		}
+	}
+
+	_, err := ws.Seek(int64(w.offset), io.SeekStart)
+	return err
+}
+
+// Close writes the frames that are held back, the cues and the duration, and closes the output
+func (w *WebMWriter) Close() error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	if w.ioWriter == nil {
+		// Returns no error as it may be convenient to call
+		// Close() multiple times
+		return nil
+	}
+
+	defer func() {
+		w.ioWriter = nil
+	}()
+
+	err := w.finalize()
+	if closer, ok := w.ioWriter.(io.Closer); ok {
+		if closeErr := closer.Close(); err == nil {
+			err = closeErr
+		}
+	}
+	return err
+}
+
+// An Option configures a WebMWriter.
+type Option func(w *WebMWriter) error
+
+// WithSenderReportTimeout configures how long frames are held back at the start, waiting
+// for a sender report of every track to synchronize them. The tracks are synchronized with
+// the arrival time of their first packet if one is missing. The default is 2 seconds.
+func WithSenderReportTimeout(timeout time.Duration) Option {
+	return func(w *WebMWriter) error {
+		w.senderReportTimeout = timeout
+		return nil
+	}
+}
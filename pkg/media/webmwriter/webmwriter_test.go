@@ -0,0 +1,330 @@
+package webmwriter
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"io"
+	"math"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/pion/rtcp"
+	"github.com/pion/rtp"
+	"github.com/stretchr/testify/assert"
+)
+
+// seekBuffer is an in memory io.WriteSeeker
+type seekBuffer struct {
+	data []byte
+	pos  int
+}
+
+func (b *seekBuffer) Write(p []byte) (int, error) {
+	if end := b.pos + len(p); end > len(b.data) {
+		b.data = append(b.data, make([]byte, end-len(b.data))...)
+	}
+	n := copy(b.data[b.pos:], p)
+	b.pos += n
+	return n, nil
+}
+
+func (b *seekBuffer) Seek(offset int64, whence int) (int64, error) {
+	if whence != io.SeekStart {
+		return 0, io.ErrUnexpectedEOF
+	}
+	b.pos = int(offset)
+	return offset, nil
+}
+
+type element struct {
+	id     uint32
+	offset int
+	data   []byte
+}
+
+func readVint(b []byte) (uint64, int) {
+	length := 1
+	for length < 8 && b[0]&(0x80>>(length-1)) == 0 {
+		length++
+	}
+
+	value := uint64(b[0] & (0xFF >> length))
+	for i := 1; i < length; i++ {
+		value = value<<8 | uint64(b[i])
+	}
+	return value, length
+}
+
+// parseElements reads the elements of data, offset is the position of data in its parent
+func parseElements(t *testing.T, data []byte) []element {
+	elements := []element{}
+	for offset := 0; offset < len(data); {
+		_, idLength := readVint(data[offset:])
+		id := uint32(0)
+		for _, b := range data[offset : offset+idLength] {
+			id = id<<8 | uint32(b)
+		}
+
+		size, sizeLength := readVint(data[offset+idLength:])
+		start := offset + idLength + sizeLength
+		end := start + int(size)
+		if size == 1<<(7*sizeLength)-1 {
+			end = len(data)
+		}
+		if !assert.LessOrEqual(t, end, len(data)) {
+			return elements
+		}
+
+		elements = append(elements, element{id: id, offset: offset, data: data[start:end]})
+		offset = end
+	}
+	return elements
+}
+
+func findElements(t *testing.T, data []byte, id uint32) []element {
+	found := []element{}
+	for _, e := range parseElements(t, data) {
+		if e.id == id {
+			found = append(found, e)
+		}
+	}
+	return found
+}
+
+func findUint(t *testing.T, data []byte, id uint32) uint64 {
+	elements := findElements(t, data, id)
+	if !assert.Len(t, elements, 1) {
+		return 0
+	}
+
+	v := uint64(0)
+	for _, b := range elements[0].data {
+		v = v<<8 | uint64(b)
+	}
+	return v
+}
+
+type block struct {
+	track    uint64
+	timecode int64
+	isKey    bool
+	data     []byte
+}
+
+func readBlocks(t *testing.T, segment []byte) []block {
+	blocks := []block{}
+	for _, cluster := range findElements(t, segment, idCluster) {
+		clusterTimecode := int64(findUint(t, cluster.data, idTimecode))
+		for _, simpleBlock := range findElements(t, cluster.data, idSimpleBlock) {
+			track, n := readVint(simpleBlock.data)
+			blocks = append(blocks, block{
+				track:    track,
+				timecode: clusterTimecode + int64(int16(binary.BigEndian.Uint16(simpleBlock.data[n:]))),
+				isKey:    simpleBlock.data[n+2]&0x80 != 0,
+				data:     simpleBlock.data[n+3:],
+			})
+		}
+	}
+	return blocks
+}
+
+func timeToNTP(t time.Time) uint64 {
+	seconds := uint64(t.Unix() + 2208988800)
+	fraction := uint64(t.Nanosecond()) << 32 / uint64(time.Second)
+	return seconds<<32 | fraction
+}
+
+func TestWebMWriter_SenderReports(t *testing.T) {
+	out := &seekBuffer{}
+	writer, err := NewWith(out)
+	assert.NoError(t, err)
+
+	video, err := writer.AddTrack(TrackConfig{MimeType: mimeTypeVP8, SSRC: 1})
+	assert.NoError(t, err)
+	audio, err := writer.AddTrack(TrackConfig{MimeType: mimeTypeOpus, SSRC: 2})
+	assert.NoError(t, err)
+
+	_, err = writer.AddTrack(TrackConfig{MimeType: "video/H264"})
+	assert.ErrorIs(t, err, errNoSuchCodec)
+
+	// The audio sender clock is 100ms ahead of the video one
+	start := time.Date(2022, 1, 1, 0, 0, 0, 0, time.UTC)
+	assert.NoError(t, video.WriteRTCP([]rtcp.Packet{&rtcp.SenderReport{SSRC: 1, NTPTime: timeToNTP(start), RTPTime: 1000}}))
+	assert.NoError(t, audio.WriteRTCP([]rtcp.Packet{&rtcp.SenderReport{SSRC: 2, NTPTime: timeToNTP(start.Add(100 * time.Millisecond)), RTPTime: 5000}}))
+
+	// VP8 key frame of 320x240, split into two packets
+	keyFrame := []byte{0x00, 0x00, 0x00, 0x9D, 0x01, 0x2A, 0x40, 0x01, 0xF0, 0x00, 0xAA, 0xBB}
+	packets := []struct {
+		track  *Track
+		packet *rtp.Packet
+	}{
+		{video, &rtp.Packet{Header: rtp.Header{SSRC: 1, SequenceNumber: 10, Timestamp: 1000}, Payload: append([]byte{0x10}, keyFrame[:6]...)}},
+		{video, &rtp.Packet{Header: rtp.Header{SSRC: 1, SequenceNumber: 11, Timestamp: 1000, Marker: true}, Payload: append([]byte{0x00}, keyFrame[6:]...)}},
+		{audio, &rtp.Packet{Header: rtp.Header{SSRC: 2, SequenceNumber: 20, Timestamp: 5000}, Payload: []byte{0x01}}},
+		{video, &rtp.Packet{Header: rtp.Header{SSRC: 1, SequenceNumber: 12, Timestamp: 10000, Marker: true}, Payload: []byte{0x10, 0x01, 0xCC, 0xDD}}},
+		{audio, &rtp.Packet{Header: rtp.Header{SSRC: 2, SequenceNumber: 21, Timestamp: 5960}, Payload: []byte{0x02}}},
+		// A frame that misses its first packet is dropped
+		{video, &rtp.Packet{Header: rtp.Header{SSRC: 1, SequenceNumber: 14, Timestamp: 19000, Marker: true}, Payload: []byte{0x00, 0x01, 0xEE, 0xFF}}},
+	}
+	for _, p := range packets {
+		assert.NoError(t, p.track.WriteRTP(p.packet))
+	}
+
+	_, err = writer.AddTrack(TrackConfig{MimeType: mimeTypeVP8})
+	assert.ErrorIs(t, err, errTracksWritten)
+	assert.ErrorIs(t, video.WriteRTP(nil), errInvalidNilPacket)
+
+	assert.NoError(t, writer.Close())
+	assert.NoError(t, writer.Close())
+	assert.ErrorIs(t, audio.WriteRTP(packets[2].packet), errFileNotOpened)
+
+	top := parseElements(t, out.data)
+	assert.Len(t, top, 2)
+	assert.Equal(t, uint32(idEBML), top[0].id)
+	assert.Equal(t, "webm", string(findElements(t, top[0].data, idDocType)[0].data))
+	assert.Equal(t, uint32(idSegment), top[1].id)
+
+	// The Segment has its size
+	segment := top[1].data
+	segmentSize, _ := readVint(out.data[len(out.data)-len(segment)-8:])
+	assert.Equal(t, uint64(len(segment)), segmentSize)
+
+	info := findElements(t, segment, idInfo)[0].data
+	assert.Equal(t, uint64(time.Millisecond), findUint(t, info, idTimecodeScale))
+	assert.Equal(t, 120.0, math.Float64frombits(binary.BigEndian.Uint64(findElements(t, info, idDuration)[0].data)))
+
+	tracks := findElements(t, findElements(t, segment, idTracks)[0].data, idTrackEntry)
+	assert.Len(t, tracks, 2)
+	assert.Equal(t, "V_VP8", string(findElements(t, tracks[0].data, idCodecID)[0].data))
+	videoSettings := findElements(t, tracks[0].data, idVideo)[0].data
+	assert.Equal(t, uint64(320), findUint(t, videoSettings, idPixelWidth))
+	assert.Equal(t, uint64(240), findUint(t, videoSettings, idPixelHeight))
+	assert.Equal(t, "A_OPUS", string(findElements(t, tracks[1].data, idCodecID)[0].data))
+	assert.Equal(t, "OpusHead", string(findElements(t, tracks[1].data, idCodecPrivate)[0].data[:8]))
+
+	assert.Equal(t, []block{
+		{track: 1, timecode: 0, isKey: true, data: keyFrame},
+		{track: 2, timecode: 100, isKey: true, data: []byte{0x01}},
+		{track: 1, timecode: 100, isKey: false, data: []byte{0x01, 0xCC, 0xDD}},
+		{track: 2, timecode: 120, isKey: true, data: []byte{0x02}},
+	}, readBlocks(t, segment))
+
+	// The SeekHead points to the Cues, which point to the Cluster
+	var cuesPosition uint64
+	for _, seek := range findElements(t, findElements(t, segment, idSeekHead)[0].data, idSeek) {
+		if bytes.Equal(findElements(t, seek.data, idSeekID)[0].data, ebmlID(idCues)) {
+			cuesPosition = findUint(t, seek.data, idSeekPosition)
+		}
+	}
+	cues := findElements(t, segment, idCues)
+	assert.Len(t, cues, 1)
+	assert.Equal(t, uint64(cues[0].offset), cuesPosition)
+
+	cuePoints := findElements(t, cues[0].data, idCuePoint)
+	assert.Len(t, cuePoints, 1)
+	assert.Equal(t, uint64(0), findUint(t, cuePoints[0].data, idCueTime))
+	positions := findElements(t, cuePoints[0].data, idCueTrackPositions)[0].data
+	assert.Equal(t, uint64(1), findUint(t, positions, idCueTrack))
+	assert.Equal(t, uint64(findElements(t, segment, idCluster)[0].offset), findUint(t, positions, idCueClusterPosition))
+}
+
+func TestWebMWriter_ArrivalTime(t *testing.T) {
+	now := time.Now()
+	out := &bytes.Buffer{}
+	writer, err := NewWith(out, WithSenderReportTimeout(time.Second))
+	assert.NoError(t, err)
+	writer.now = func() time.Time { return now }
+
+	audio, err := writer.AddTrack(TrackConfig{MimeType: mimeTypeOpus})
+	assert.NoError(t, err)
+
+	// Frames are held back while waiting for sender reports
+	assert.NoError(t, audio.WriteRTP(&rtp.Packet{Header: rtp.Header{SequenceNumber: 1, Timestamp: 4294966336}, Payload: []byte{0x01}}))
+	assert.Equal(t, 0, out.Len())
+
+	now = now.Add(2 * time.Second)
+	assert.NoError(t, audio.WriteRTP(&rtp.Packet{Header: rtp.Header{SequenceNumber: 2, Timestamp: 0}, Payload: []byte{0x02}}))
+	assert.NotEqual(t, 0, out.Len())
+
+	// A new cluster is started after 5 seconds
+	assert.NoError(t, audio.WriteRTP(&rtp.Packet{Header: rtp.Header{SequenceNumber: 3, Timestamp: 48000 * 6}, Payload: []byte{0x03}}))
+	assert.NoError(t, writer.Close())
+
+	top := parseElements(t, out.Bytes())
+	assert.Len(t, top, 2)
+
+	// The output isn't seekable, the Segment keeps its unknown size
+	segment := top[1].data
+	assert.Equal(t, byte(0x01), out.Bytes()[out.Len()-len(segment)-8])
+	assert.Equal(t, byte(0xFF), out.Bytes()[out.Len()-len(segment)-1])
+
+	assert.Equal(t, []block{
+		{track: 1, timecode: 0, isKey: true, data: []byte{0x01}},
+		{track: 1, timecode: 20, isKey: true, data: []byte{0x02}},
+		{track: 1, timecode: 6020, isKey: true, data: []byte{0x03}},
+	}, readBlocks(t, segment))
+	assert.Len(t, findElements(t, segment, idCluster), 2)
+
+	// Without video every cluster is a cue point
+	cues := findElements(t, segment, idCues)
+	assert.Len(t, cues, 1)
+	assert.Len(t, findElements(t, cues[0].data, idCuePoint), 2)
+}
+
+func TestWebMWriter_VP9SpatialLayers(t *testing.T) {
+	out := &bytes.Buffer{}
+	writer, err := NewWith(out)
+	assert.NoError(t, err)
+
+	video, err := writer.AddTrack(TrackConfig{MimeType: mimeTypeVP9, SSRC: 1})
+	assert.NoError(t, err)
+	assert.NoError(t, video.WriteRTCP([]rtcp.Packet{&rtcp.SenderReport{SSRC: 1, NTPTime: timeToNTP(time.Now()), RTPTime: 3000}}))
+
+	// A key picture of two spatial layers, the first one split into two packets,
+	// and an inter picture of a single layer
+	for _, packet := range []*rtp.Packet{
+		{Header: rtp.Header{SSRC: 1, SequenceNumber: 1, Timestamp: 3000}, Payload: []byte{0x08, 0xAA}},
+		{Header: rtp.Header{SSRC: 1, SequenceNumber: 2, Timestamp: 3000}, Payload: []byte{0x04, 0xAB}},
+		{Header: rtp.Header{SSRC: 1, SequenceNumber: 3, Timestamp: 3000, Marker: true}, Payload: []byte{0x0C, 0xBB}},
+		{Header: rtp.Header{SSRC: 1, SequenceNumber: 4, Timestamp: 6000, Marker: true}, Payload: []byte{0x4C, 0xCC}},
+	} {
+		assert.NoError(t, video.WriteRTP(packet))
+	}
+	assert.NoError(t, writer.Close())
+
+	// The layers are joined in a superframe with an index of their sizes
+	top := parseElements(t, out.Bytes())
+	assert.Equal(t, []block{
+		{track: 1, timecode: 0, isKey: true, data: []byte{0xAA, 0xAB, 0xBB, 0xC1, 0x02, 0x01, 0xC1}},
+		{track: 1, timecode: 33, isKey: false, data: []byte{0xCC}},
+	}, readBlocks(t, top[1].data))
+}
+
+func TestWebMWriter_NewOptionError(t *testing.T) {
+	errOption := errors.New("option failed")
+	writer, err := New(filepath.Join(t.TempDir(), "out.webm"), func(*WebMWriter) error {
+		return errOption
+	})
+	assert.Nil(t, writer)
+	assert.ErrorIs(t, err, errOption)
+}
+
+func TestWebMWriter_SenderReportBeforeSSRC(t *testing.T) {
+	writer, err := NewWith(&bytes.Buffer{})
+	assert.NoError(t, err)
+
+	video, err := writer.AddTrack(TrackConfig{MimeType: mimeTypeVP8})
+	assert.NoError(t, err)
+
+	// The SSRC of the track isn't known yet
+	assert.NoError(t, video.WriteRTCP([]rtcp.Packet{&rtcp.SenderReport{SSRC: 1, NTPTime: timeToNTP(time.Now()), RTPTime: 3000}}))
+	assert.False(t, video.hasSenderReport)
+
+	assert.NoError(t, video.WriteRTP(&rtp.Packet{Header: rtp.Header{SSRC: 1, SequenceNumber: 1, Timestamp: 3000}, Payload: []byte{0x10, 0x01, 0xCC, 0xDD}}))
+	assert.NoError(t, video.WriteRTCP([]rtcp.Packet{&rtcp.SenderReport{SSRC: 2, NTPTime: timeToNTP(time.Now()), RTPTime: 3000}}))
+	assert.False(t, video.hasSenderReport)
+	assert.NoError(t, video.WriteRTCP([]rtcp.Packet{&rtcp.SenderReport{SSRC: 1, NTPTime: timeToNTP(time.Now()), RTPTime: 3000}}))
+	assert.True(t, video.hasSenderReport)
+}
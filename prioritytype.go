@@ -0,0 +1,78 @@
+package webrtc
+
+import (
+	"encoding/json"
+)
+
+// PriorityType determines the relative priority of an object compared
+// to the other objects of the same PeerConnection.
+type PriorityType int
+
+const (
+	// PriorityTypeVeryLow indicates the object has a very low priority.
+	PriorityTypeVeryLow PriorityType = iota + 1
+
+	// PriorityTypeLow indicates the object has a low priority. This is
+	// the default priority.
+	PriorityTypeLow
+
+	// PriorityTypeMedium indicates the object has a medium priority.
+	PriorityTypeMedium
+
+	// PriorityTypeHigh indicates the object has a high priority.
+	PriorityTypeHigh
+)
+
+// This is done this way because of a linter.
+const (
+	priorityTypeVeryLowStr = "very-low"
+	priorityTypeLowStr     = "low"
+	priorityTypeMediumStr  = "medium"
+	priorityTypeHighStr    = "high"
+)
+
+func newPriorityType(raw string) PriorityType {
+	switch raw {
+	case priorityTypeVeryLowStr:
+		return PriorityTypeVeryLow
+	case priorityTypeLowStr:
+		return PriorityTypeLow
+	case priorityTypeMediumStr:
+		return PriorityTypeMedium
+	case priorityTypeHighStr:
+		return PriorityTypeHigh
+	default:
+		return PriorityType(Unknown)
+	}
+}
+
+func (p PriorityType) String() string {
+	switch p {
+	case PriorityTypeVeryLow:
+		return priorityTypeVeryLowStr
+	case PriorityTypeLow:
+		return priorityTypeLowStr
+	case PriorityTypeMedium:
+		return priorityTypeMediumStr
+	case PriorityTypeHigh:
+		return priorityTypeHighStr
+	default:
+		return ErrUnknownType.Error()
+	}
+}
+
+// UnmarshalJSON parses the JSON-encoded data and stores the result
+func (p *PriorityType) UnmarshalJSON(b []byte) error {
+	var val string
+	if err := json.Unmarshal(b, &val); err != nil {
+		return err
+	}
+
+	*p = newPriorityType(val)
+	return nil
+}
+
+// MarshalJSON returns the JSON encoding
+func (p PriorityType) MarshalJSON() ([]byte, error) {
+	return json.Marshal(p.String())
+}
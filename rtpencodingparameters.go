@@ -5,4 +5,19 @@ package webrtc
 // http://draft.ortc.org/#dom-rtcrtpencodingparameters
 type RTPEncodingParameters struct {
 	RTPCodingParameters
+
+	// Active indicates that this encoding is being sent, setting it to false pauses the encoding
+	Active bool `json:"active"`
+
+	// MaxBitrate is the maximum bitrate in bits per second the encoding may use, 0 is unlimited.
+	// Pion WebRTC doesn't enforce it, it only caps the target bitrate given to the TrackLocal,
+	// see TrackLocalContext.TargetBitrate
+	MaxBitrate uint64 `json:"maxBitrate"`
+
+	// Priority is the relative priority of this encoding
+	Priority PriorityType `json:"priority"`
+
+	// ScaleResolutionDownBy is the factor the TrackLocal should scale down the resolution of
+	// a video encoding by. Pion WebRTC doesn't scale the video itself
+	ScaleResolutionDownBy float64 `json:"scaleResolutionDownBy"`
 }
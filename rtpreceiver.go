@@ -33,6 +33,15 @@ type trackStreams struct {
 
 	repairRtcpReadStream  *srtp.ReadStreamSRTCP
 	repairRtcpInterceptor interceptor.RTCPReader
+
+	// Only set for video, recovers lost packets before they are read
+	fec *fecDecoder
+
+	fecStreamInfo      *interceptor.StreamInfo
+	fecReadStream      *srtp.ReadStreamSRTP
+	fecInterceptor     interceptor.RTPReader
+	fecRtcpReadStream  *srtp.ReadStreamSRTCP
+	fecRtcpInterceptor interceptor.RTCPReader
 }
 
 // RTPReceiver allows an application to inspect the receipt of a TrackRemote
@@ -83,6 +92,12 @@ func (r *RTPReceiver) Transport() *DTLSTransport {
 	return r.transport
 }
 
+func (r *RTPReceiver) setTransport(transport *DTLSTransport) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.transport = transport
+}
+
 func (r *RTPReceiver) getParameters() RTPParameters {
 	parameters := r.api.mediaEngine.getRTPParametersByKind(r.kind, []RTPTransceiverDirection{RTPTransceiverDirectionRecvonly})
 	if r.tr != nil {
@@ -176,6 +191,12 @@ func (r *RTPReceiver) startReceive(parameters RTPReceiveParameters) error {
 			return fmt.Errorf("%w: %d", errRTPReceiverWithSSRCTrackStreamNotFound, parameters.Encodings[i].SSRC)
 		}
 
+		if r.kind == RTPCodecTypeVideo {
+			t.fec = newFECDecoder(globalParams.Codecs)
+		} else {
+			t.fec = newAudioREDDecoder(globalParams.Codecs)
+		}
+
 		if parameters.Encodings[i].SSRC != 0 {
 			t.streamInfo = createStreamInfo("", parameters.Encodings[i].SSRC, 0, codec, globalParams.HeaderExtensions)
 			var err error
@@ -195,6 +216,16 @@ func (r *RTPReceiver) startReceive(parameters RTPReceiveParameters) error {
 				return err
 			}
 		}
+
+		if fecSsrc := parameters.Encodings[i].FEC.SSRC; fecSsrc != 0 && t.fec != nil {
+			streamInfo := createStreamInfo("", fecSsrc, 0, codec, globalParams.HeaderExtensions)
+			rtpReadStream, rtpInterceptor, rtcpReadStream, rtcpInterceptor, err := r.transport.streamsForSSRC(fecSsrc, *streamInfo)
+			if err != nil {
+				return err
+			}
+
+			r.receiveForFEC(t, streamInfo, rtpReadStream, rtpInterceptor, rtcpReadStream, rtcpInterceptor)
+		}
 	}
 
 	return nil
@@ -302,6 +333,14 @@ func (r *RTPReceiver) Stop() error {
 				errs = append(errs, r.tracks[i].repairRtcpReadStream.Close())
 			}
 
+			if r.tracks[i].fecReadStream != nil {
+				errs = append(errs, r.tracks[i].fecReadStream.Close())
+			}
+
+			if r.tracks[i].fecRtcpReadStream != nil {
+				errs = append(errs, r.tracks[i].fecRtcpReadStream.Close())
+			}
+
 			if r.tracks[i].streamInfo != nil {
 				r.api.interceptor.UnbindRemoteStream(r.tracks[i].streamInfo)
 			}
@@ -310,6 +349,10 @@ func (r *RTPReceiver) Stop() error {
 				r.api.interceptor.UnbindRemoteStream(r.tracks[i].repairStreamInfo)
 			}
 
+			if r.tracks[i].fecStreamInfo != nil {
+				r.api.interceptor.UnbindRemoteStream(r.tracks[i].fecStreamInfo)
+			}
+
 			err = util.FlattenErrs(errs)
 		}
 	default:
@@ -331,11 +374,27 @@ func (r *RTPReceiver) streamsForTrack(t *TrackRemote) *trackStreams {
 // readRTP should only be called by a track, this only exists so we can keep state in one place
 func (r *RTPReceiver) readRTP(b []byte, reader *TrackRemote) (n int, a interceptor.Attributes, err error) {
 	<-r.received
-	if t := r.streamsForTrack(reader); t != nil {
+	t := r.streamsForTrack(reader)
+	if t == nil {
+		return 0, nil, fmt.Errorf("%w: %d", errRTPReceiverWithSSRCTrackStreamNotFound, reader.SSRC())
+	} else if t.fec == nil {
 		return t.rtpInterceptor.Read(b, a)
 	}
 
-	return 0, nil, fmt.Errorf("%w: %d", errRTPReceiverWithSSRCTrackStreamNotFound, reader.SSRC())
+	// Packets recovered by FEC are read before the next one from the stream
+	for {
+		if n, ok := t.fec.popRecovered(b); ok {
+			return n, interceptor.Attributes{}, nil
+		}
+
+		if n, a, err = t.rtpInterceptor.Read(b, a); err != nil {
+			return n, a, err
+		}
+
+		if n = t.fec.processRTP(b, n); n != 0 {
+			return n, a, nil
+		}
+	}
 }
 
 // receiveForRid is the sibling of Receive expect for RIDs instead of SSRCs
@@ -353,6 +412,12 @@ func (r *RTPReceiver) receiveForRid(rid string, params RTPParameters, streamInfo
 			r.tracks[i].track.ssrc = SSRC(streamInfo.SSRC)
 			r.tracks[i].track.mu.Unlock()
 
+			if r.kind == RTPCodecTypeVideo {
+				r.tracks[i].fec = newFECDecoder(r.getParameters().Codecs)
+			} else {
+				r.tracks[i].fec = newAudioREDDecoder(r.getParameters().Codecs)
+			}
+
 			r.tracks[i].streamInfo = streamInfo
 			r.tracks[i].rtpReadStream = rtpReadStream
 			r.tracks[i].rtpInterceptor = rtpInterceptor
@@ -402,6 +467,28 @@ func (r *RTPReceiver) receiveForRtx(ssrc SSRC, rsid string, streamInfo *intercep
 	return nil
 }
 
+// receiveForFEC starts a routine that reads the FlexFEC stream of a track, the
+// packets it recovers are read from the track
+func (r *RTPReceiver) receiveForFEC(track *trackStreams, streamInfo *interceptor.StreamInfo, rtpReadStream *srtp.ReadStreamSRTP, rtpInterceptor interceptor.RTPReader, rtcpReadStream *srtp.ReadStreamSRTCP, rtcpInterceptor interceptor.RTCPReader) {
+	track.fecStreamInfo = streamInfo
+	track.fecReadStream = rtpReadStream
+	track.fecInterceptor = rtpInterceptor
+	track.fecRtcpReadStream = rtcpReadStream
+	track.fecRtcpInterceptor = rtcpInterceptor
+
+	go func() {
+		b := make([]byte, r.api.settingEngine.getReceiveMTU())
+		for {
+			n, _, readErr := track.fecInterceptor.Read(b, nil)
+			if readErr != nil {
+				return
+			}
+
+			track.fec.processFlexFEC(b[:n])
+		}
+	}()
+}
+
 // SetReadDeadline sets the max amount of time the RTCP stream will block before returning. 0 is forever.
 func (r *RTPReceiver) SetReadDeadline(t time.Time) error {
 	r.mu.RLock()
@@ -437,3 +524,72 @@ func (r *RTPReceiver) setRTPReadDeadline(deadline time.Time, reader *TrackRemote
 	}
 	return fmt.Errorf("%w: %d", errRTPReceiverWithSSRCTrackStreamNotFound, reader.SSRC())
 }
+
+// collectStats adds an inbound-rtp and a remote-outbound-rtp entry for every track that is being received
+func (r *RTPReceiver) collectStats(collector *statsReportCollector, statsInterceptor *statsInterceptor) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	if !r.haveReceived() {
+		return
+	}
+
+	for i := range r.tracks {
+		track := r.tracks[i].track
+		if track == nil {
+			continue
+		}
+
+		ssrc := track.SSRC()
+		state, ok := statsInterceptor.inboundStats(ssrc)
+		if !ok {
+			continue
+		}
+
+		codec := track.Codec()
+		collector.Collecting()
+		inboundStats := InboundRTPStreamStats{
+			Timestamp:                   statsTimestampNow(),
+			Type:                        StatsTypeInboundRTP,
+			ID:                          newInboundRTPStreamStatsID(ssrc),
+			SSRC:                        ssrc,
+			Kind:                        r.kind.String(),
+			TransportID:                 r.transport.iceTransportStatsID(),
+			CodecID:                     codec.statsID,
+			FIRCount:                    state.firCount,
+			PLICount:                    state.pliCount,
+			NACKCount:                   state.nackCount,
+			PacketsReceived:             state.packetsReceived,
+			PacketsLost:                 state.packetsLost(),
+			LastPacketReceivedTimestamp: statsTimestampFrom(state.lastPacketReceived),
+			BytesReceived:               state.bytesReceived,
+		}
+		if state.clockRate != 0 {
+			inboundStats.Jitter = state.jitter / float64(state.clockRate)
+		}
+
+		if !state.remoteReported {
+			collector.Collect(inboundStats.ID, inboundStats)
+			continue
+		}
+
+		inboundStats.RemoteID = newRemoteOutboundRTPStreamStatsID(ssrc)
+		collector.Collect(inboundStats.ID, inboundStats)
+
+		collector.Collecting()
+		remoteOutboundStats := RemoteOutboundRTPStreamStats{
+			Timestamp:       statsTimestampNow(),
+			Type:            StatsTypeRemoteOutboundRTP,
+			ID:              inboundStats.RemoteID,
+			SSRC:            ssrc,
+			Kind:            inboundStats.Kind,
+			TransportID:     inboundStats.TransportID,
+			CodecID:         inboundStats.CodecID,
+			PacketsSent:     state.remotePacketsSent,
+			BytesSent:       state.remoteBytesSent,
+			LocalID:         inboundStats.ID,
+			RemoteTimestamp: statsTimestampFrom(state.remoteTimestamp),
+		}
+		collector.Collect(remoteOutboundStats.ID, remoteOutboundStats)
+	}
+}
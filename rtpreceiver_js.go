@@ -10,3 +10,19 @@ type RTPReceiver struct {
 	// Pointer to the underlying JavaScript RTCRTPReceiver object.
 	underlying js.Value
 }
+
+// Track returns the RTPReceiver's track, or nil. The StreamID of the returned track is
+// only known to the TrackRemote passed to OnTrack
+func (r *RTPReceiver) Track() *TrackRemote {
+	underlying := r.underlying.Get("track")
+	if underlying.IsNull() || underlying.IsUndefined() {
+		return nil
+	}
+
+	return &TrackRemote{underlying: underlying, receiver: r}
+}
+
+// JSValue returns the underlying RTCRtpReceiver
+func (r *RTPReceiver) JSValue() js.Value {
+	return r.underlying
+}
@@ -14,6 +14,7 @@ import (
 	"github.com/pion/rtcp"
 	"github.com/pion/rtp"
 	"github.com/pion/webrtc/v3/internal/util"
+	"github.com/pion/webrtc/v3/pkg/rtcerr"
 )
 
 type trackEncoding struct {
@@ -27,6 +28,23 @@ type trackEncoding struct {
 	context TrackLocalContext
 
 	ssrc SSRC
+
+	// SSRC of the RTX (RFC 4588) repair flow, only allocated for video
+	rtxSsrc SSRC
+
+	// SSRC of the FlexFEC protection, only allocated for video
+	fecSsrc SSRC
+
+	targetBitrate *targetBitrate
+
+	// Only set for audio, the telephone-event codec used by DTMF and the stream the events are inserted in
+	telephoneEvent *RTPCodecParameters
+	writeStream    *interceptorToTrackLocalWriter
+
+	// Set by SetParameters, paused encodings are not sent
+	paused                atomicBool
+	priority              PriorityType
+	scaleResolutionDownBy float64
 }
 
 // RTPSender allows an application to control how a given Track is encoded and transmitted to a remote peer
@@ -50,6 +68,11 @@ type RTPSender struct {
 
 	rtpTransceiver *RTPTransceiver
 
+	dtmf *DTMFSender
+
+	// Changed every time SetParameters is successful, so stale parameters are rejected
+	transactionID string
+
 	mu                     sync.RWMutex
 	sendCalled, stopCalled chan struct{}
 }
@@ -68,12 +91,17 @@ func (api *API) NewRTPSender(track TrackLocal, transport *DTLSTransport) (*RTPSe
 	}
 
 	r := &RTPSender{
-		transport:  transport,
-		api:        api,
-		sendCalled: make(chan struct{}),
-		stopCalled: make(chan struct{}),
-		id:         id,
-		kind:       track.Kind(),
+		transport:     transport,
+		api:           api,
+		sendCalled:    make(chan struct{}),
+		stopCalled:    make(chan struct{}),
+		id:            id,
+		kind:          track.Kind(),
+		transactionID: util.MathRandAlpha(16),
+	}
+
+	if r.kind == RTPCodecTypeAudio {
+		r.dtmf = &DTMFSender{rtpSender: r}
 	}
 
 	r.addEncoding(track)
@@ -107,6 +135,12 @@ func (r *RTPSender) Transport() *DTLSTransport {
 	return r.transport
 }
 
+func (r *RTPSender) setTransport(transport *DTLSTransport) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.transport = transport
+}
+
 func (r *RTPSender) getParameters() RTPSendParameters {
 	var encodings []RTPEncodingParameters
 	for _, trackEncoding := range r.trackEncodings {
@@ -119,7 +153,13 @@ func (r *RTPSender) getParameters() RTPSendParameters {
 				RID:         rid,
 				SSRC:        trackEncoding.ssrc,
 				PayloadType: r.payloadType,
+				RTX:         RTPRtxParameters{SSRC: trackEncoding.rtxSsrc},
+				FEC:         RTPFecParameters{SSRC: trackEncoding.fecSsrc},
 			},
+			Active:                !trackEncoding.paused.get(),
+			MaxBitrate:            uint64(trackEncoding.targetBitrate.getMaxBitrate()),
+			Priority:              trackEncoding.priority,
+			ScaleResolutionDownBy: trackEncoding.scaleResolutionDownBy,
 		})
 	}
 	sendParameters := RTPSendParameters{
@@ -127,13 +167,28 @@ func (r *RTPSender) getParameters() RTPSendParameters {
 			r.kind,
 			[]RTPTransceiverDirection{RTPTransceiverDirectionSendonly},
 		),
-		Encodings: encodings,
+		Encodings:     encodings,
+		TransactionID: r.transactionID,
 	}
 	if r.rtpTransceiver != nil {
 		sendParameters.Codecs = r.rtpTransceiver.getCodecs()
 	} else {
 		sendParameters.Codecs = r.api.mediaEngine.getCodecsByKind(r.kind)
 	}
+
+	// Only announce the repair flows if RTX can be used
+	if !codecsHaveRTX(sendParameters.Codecs) {
+		for i := range sendParameters.Encodings {
+			sendParameters.Encodings[i].RTX.SSRC = 0
+		}
+	}
+
+	// FlexFEC is only announced if it is going to be sent
+	if r.api.settingEngine.fecOverhead == 0 || findFECCodec(MimeTypeFlexFEC03, sendParameters.Codecs) == nil {
+		for i := range sendParameters.Encodings {
+			sendParameters.Encodings[i].FEC.SSRC = 0
+		}
+	}
 	return sendParameters
 }
 
@@ -145,6 +200,54 @@ func (r *RTPSender) GetParameters() RTPSendParameters {
 	return r.getParameters()
 }
 
+// SetParameters updates how the encodings of the sender's track are transmitted.
+// The parameters must be the ones returned by the last call to GetParameters,
+// only Active, MaxBitrate, Priority and ScaleResolutionDownBy of the Encodings
+// can be modified. Paused encodings are not sent until they are activated again.
+func (r *RTPSender) SetParameters(parameters RTPSendParameters) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if r.hasStopped() {
+		return &rtcerr.InvalidStateError{Err: errRTPSenderStopped}
+	}
+
+	if parameters.TransactionID != r.transactionID {
+		return &rtcerr.InvalidModificationError{Err: errRTPSenderTransactionIDMismatch}
+	}
+
+	if len(parameters.Encodings) != len(r.trackEncodings) {
+		return &rtcerr.InvalidModificationError{Err: errRTPSenderEncodingsMismatch}
+	}
+
+	for i, encoding := range parameters.Encodings {
+		var rid string
+		if r.trackEncodings[i].track != nil {
+			rid = r.trackEncodings[i].track.RID()
+		}
+
+		switch {
+		case encoding.RID != rid || encoding.SSRC != r.trackEncodings[i].ssrc:
+			return &rtcerr.InvalidModificationError{Err: errRTPSenderEncodingsMismatch}
+		case encoding.ScaleResolutionDownBy < 1:
+			return &rtcerr.RangeError{Err: errRTPSenderScaleResolutionDownBy}
+		case encoding.Priority < PriorityTypeVeryLow || encoding.Priority > PriorityTypeHigh:
+			return &rtcerr.TypeError{Err: errRTPSenderInvalidPriority}
+		}
+	}
+
+	for i, encoding := range parameters.Encodings {
+		trackEncoding := r.trackEncodings[i]
+		trackEncoding.paused.set(!encoding.Active)
+		trackEncoding.priority = encoding.Priority
+		trackEncoding.scaleResolutionDownBy = encoding.ScaleResolutionDownBy
+		trackEncoding.targetBitrate.setMaxBitrate(int(encoding.MaxBitrate))
+	}
+
+	r.transactionID = util.MathRandAlpha(16)
+	return nil
+}
+
 // AddEncoding adds an encoding to RTPSender. Used by simulcast senders.
 func (r *RTPSender) AddEncoding(track TrackLocal) error {
 	r.mu.Lock()
@@ -195,21 +298,34 @@ func (r *RTPSender) AddEncoding(track TrackLocal) error {
 func (r *RTPSender) addEncoding(track TrackLocal) {
 	ssrc := SSRC(randutil.NewMathRandomGenerator().Uint32())
 	trackEncoding := &trackEncoding{
-		track:      track,
-		srtpStream: &srtpWriterFuture{ssrc: ssrc},
-		ssrc:       ssrc,
+		track:                 track,
+		srtpStream:            &srtpWriterFuture{ssrc: ssrc},
+		ssrc:                  ssrc,
+		targetBitrate:         &targetBitrate{},
+		priority:              PriorityTypeLow,
+		scaleResolutionDownBy: 1,
+	}
+	if r.kind == RTPCodecTypeVideo {
+		trackEncoding.rtxSsrc = SSRC(randutil.NewMathRandomGenerator().Uint32())
+		trackEncoding.fecSsrc = SSRC(randutil.NewMathRandomGenerator().Uint32())
 	}
 	trackEncoding.srtpStream.rtpSender = r
 	trackEncoding.rtcpInterceptor = r.api.interceptor.BindRTCPReader(
 		interceptor.RTPReaderFunc(func(in []byte, a interceptor.Attributes) (n int, attributes interceptor.Attributes, err error) {
 			n, err = trackEncoding.srtpStream.Read(in)
-			return n, a, err
+			return n, withRTCPStreamSSRC(a, ssrc), err
 		}),
 	)
 
 	r.trackEncodings = append(r.trackEncodings, trackEncoding)
 }
 
+// DTMF returns the DTMFSender that sends DTMF tones on the track of this RTPSender,
+// or nil if the RTPSender is not sending audio
+func (r *RTPSender) DTMF() *DTMFSender {
+	return r.dtmf
+}
+
 // Track returns the RTCRtpTransceiver track, or nil
 func (r *RTPSender) Track() TrackLocal {
 	r.mu.RLock()
@@ -248,6 +364,8 @@ func (r *RTPSender) ReplaceTrack(track TrackLocal) error {
 		if err := replacedTrack.Unbind(*context); err != nil {
 			return err
 		}
+		// The replaced track must not be notified anymore
+		context.OnTargetBitrateChange(nil)
 	}
 
 	if !r.hasSent() || track == nil {
@@ -261,6 +379,7 @@ func (r *RTPSender) ReplaceTrack(track TrackLocal) error {
 		ssrc:            context.ssrc,
 		writeStream:     context.writeStream,
 		rtcpInterceptor: context.rtcpInterceptor,
+		targetBitrate:   context.targetBitrate,
 	})
 	if err != nil {
 		// Re-bind the original track
@@ -293,19 +412,39 @@ func (r *RTPSender) Send(parameters RTPSendParameters) error {
 	}
 
 	for idx, trackEncoding := range r.trackEncodings {
-		writeStream := &interceptorToTrackLocalWriter{}
+		writeStream := &interceptorToTrackLocalWriter{paused: &trackEncoding.paused, sequencer: newRTPSequencer()}
 		trackEncoding.context = TrackLocalContext{
 			id:              r.id,
 			params:          r.api.mediaEngine.getRTPParametersByKind(trackEncoding.track.Kind(), []RTPTransceiverDirection{RTPTransceiverDirectionSendonly}),
 			ssrc:            parameters.Encodings[idx].SSRC,
 			writeStream:     writeStream,
 			rtcpInterceptor: trackEncoding.rtcpInterceptor,
+			targetBitrate:   trackEncoding.targetBitrate,
 		}
 
 		codec, err := trackEncoding.track.Bind(trackEncoding.context)
 		if err != nil {
 			return err
 		}
+		rtxPayloadType := findRTXPayloadType(codec.PayloadType, trackEncoding.context.params.Codecs)
+		if r.kind == RTPCodecTypeAudio {
+			trackEncoding.telephoneEvent = findTelephoneEventCodec(codec.ClockRate, trackEncoding.context.params.Codecs)
+			trackEncoding.writeStream = writeStream
+		}
+		if overhead := r.api.settingEngine.fecOverhead; overhead != 0 && r.kind == RTPCodecTypeVideo {
+			codecs := trackEncoding.context.params.Codecs
+			flexFEC := findFECCodec(MimeTypeFlexFEC03, codecs)
+			red, ulpfec := findFECCodec(MimeTypeRED, codecs), findFECCodec(MimeTypeULPFEC, codecs)
+
+			switch {
+			case flexFEC != nil && parameters.Encodings[idx].FEC.SSRC != 0:
+				writeStream.fec = newFlexFECEncoder(overhead, parameters.Encodings[idx].FEC.SSRC, flexFEC.PayloadType)
+			case red != nil && ulpfec != nil:
+				// The media is sent inside of RED, so are its retransmissions
+				writeStream.fec = newULPFECEncoder(overhead, red.PayloadType, ulpfec.PayloadType, writeStream.sequencer)
+				rtxPayloadType = findRTXPayloadType(red.PayloadType, codecs)
+			}
+		}
 		trackEncoding.context.params.Codecs = []RTPCodecParameters{codec}
 
 		trackEncoding.streamInfo = *createStreamInfo(
@@ -315,10 +454,17 @@ func (r *RTPSender) Send(parameters RTPSendParameters) error {
 			codec.RTPCodecCapability,
 			parameters.HeaderExtensions,
 		)
-		srtpStream := trackEncoding.srtpStream
+		if rtxSsrc := parameters.Encodings[idx].RTX.SSRC; rtxSsrc != 0 && rtxPayloadType != 0 {
+			trackEncoding.streamInfo.Attributes[rtxSSRCAttribute{}] = rtxSsrc
+			trackEncoding.streamInfo.Attributes[rtxPayloadTypeAttribute{}] = rtxPayloadType
+		}
+		srtpStream, fecEncoder := trackEncoding.srtpStream, writeStream.fec
 		rtpInterceptor := r.api.interceptor.BindLocalStream(
 			&trackEncoding.streamInfo,
 			interceptor.RTPWriterFunc(func(header *rtp.Header, payload []byte, attributes interceptor.Attributes) (int, error) {
+				if fecEncoder != nil && attributes.Get(fecProtectAttribute{}) != nil {
+					return fecEncoder.writeProtected(header, payload, srtpStream.WriteRTP)
+				}
 				return srtpStream.WriteRTP(header, payload)
 			}),
 		)
@@ -430,6 +576,21 @@ func (r *RTPSender) SetReadDeadlineSimulcast(deadline time.Time, rid string) err
 	return fmt.Errorf("%w: %s", errRTPSenderNoTrackForRID, rid)
 }
 
+// setTargetBitrate forwards the estimate of the bandwidth estimator, the total of the
+// PeerConnection, to the tracks of every encoding
+func (r *RTPSender) setTargetBitrate(bitrate int) {
+	r.mu.RLock()
+	targetBitrates := make([]*targetBitrate, 0, len(r.trackEncodings))
+	for _, trackEncoding := range r.trackEncodings {
+		targetBitrates = append(targetBitrates, trackEncoding.targetBitrate)
+	}
+	r.mu.RUnlock()
+
+	for _, t := range targetBitrates {
+		t.set(bitrate)
+	}
+}
+
 // hasSent tells if data has been ever sent for this instance
 func (r *RTPSender) hasSent() bool {
 	select {
@@ -449,3 +610,81 @@ func (r *RTPSender) hasStopped() bool {
 		return false
 	}
 }
+
+// collectStats adds an outbound-rtp and a remote-inbound-rtp entry for every encoding that has been sent
+func (r *RTPSender) collectStats(collector *statsReportCollector, statsInterceptor *statsInterceptor) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	if !r.hasSent() {
+		return
+	}
+
+	for _, trackEncoding := range r.trackEncodings {
+		state, ok := statsInterceptor.outboundStats(trackEncoding.ssrc)
+		if !ok {
+			continue
+		}
+
+		var codecID string
+		if codecs := trackEncoding.context.params.Codecs; len(codecs) != 0 {
+			codecID = codecs[0].statsID
+		}
+
+		var rid string
+		if trackEncoding.track != nil {
+			rid = trackEncoding.track.RID()
+		}
+
+		collector.Collecting()
+		outboundStats := OutboundRTPStreamStats{
+			Timestamp:                statsTimestampNow(),
+			Type:                     StatsTypeOutboundRTP,
+			ID:                       newOutboundRTPStreamStatsID(trackEncoding.ssrc),
+			SSRC:                     trackEncoding.ssrc,
+			Kind:                     r.kind.String(),
+			TransportID:              r.transport.iceTransportStatsID(),
+			CodecID:                  codecID,
+			FIRCount:                 state.firCount,
+			PLICount:                 state.pliCount,
+			NACKCount:                state.nackCount,
+			PacketsSent:              state.packetsSent,
+			BytesSent:                state.bytesSent,
+			RetransmittedPacketsSent: state.retransmittedPacketsSent,
+			RetransmittedBytesSent:   state.retransmittedBytesSent,
+			SenderID:                 r.id,
+			LastPacketSentTimestamp:  statsTimestampFrom(state.lastPacketSent),
+			TargetBitrate:            float64(trackEncoding.targetBitrate.get()),
+			Rid:                      rid,
+			Active:                   !trackEncoding.paused.get(),
+		}
+
+		if !state.remoteReported {
+			collector.Collect(outboundStats.ID, outboundStats)
+			continue
+		}
+
+		outboundStats.RemoteID = newRemoteInboundRTPStreamStatsID(trackEncoding.ssrc)
+		collector.Collect(outboundStats.ID, outboundStats)
+
+		collector.Collecting()
+		remoteInboundStats := RemoteInboundRTPStreamStats{
+			Timestamp:     statsTimestampFrom(state.remoteTimestamp),
+			Type:          StatsTypeRemoteInboundRTP,
+			ID:            outboundStats.RemoteID,
+			SSRC:          trackEncoding.ssrc,
+			Kind:          outboundStats.Kind,
+			TransportID:   outboundStats.TransportID,
+			CodecID:       codecID,
+			PacketsLost:   state.packetsLost,
+			Jitter:        state.jitter,
+			LocalID:       outboundStats.ID,
+			RoundTripTime: state.roundTripTime,
+			FractionLost:  state.fractionLost,
+		}
+		if received := int64(state.packetsSent) - int64(state.packetsLost); received > 0 {
+			remoteInboundStats.PacketsReceived = uint32(received)
+		}
+		collector.Collect(remoteInboundStats.ID, remoteInboundStats)
+	}
+}
@@ -10,3 +10,38 @@ type RTPSender struct {
 	// Pointer to the underlying JavaScript RTCRTPSender object.
 	underlying js.Value
 }
+
+// Track returns the RTPSender's track, or nil. The StreamID of the returned track is
+// not known to the browser and empty
+func (r *RTPSender) Track() TrackLocal {
+	underlying := r.underlying.Get("track")
+	if underlying.IsNull() || underlying.IsUndefined() {
+		return nil
+	}
+
+	return NewTrackLocalMediaStreamTrack(underlying, "")
+}
+
+// ReplaceTrack replaces the track currently being used as the sender's source with a new TrackLocal.
+// The new track must be of the same media kind (audio, video, etc) and switching the track should not
+// require negotiation. A nil track stops sending.
+func (r *RTPSender) ReplaceTrack(track TrackLocal) (err error) {
+	defer func() {
+		if e := recover(); e != nil {
+			err = recoveryToError(e)
+		}
+	}()
+
+	trackValue, err := trackLocalToValue(track)
+	if err != nil {
+		return err
+	}
+
+	_, err = awaitPromise(r.underlying.Call("replaceTrack", trackValue))
+	return err
+}
+
+// JSValue returns the underlying RTCRtpSender
+func (r *RTPSender) JSValue() js.Value {
+	return r.underlying
+}
@@ -11,8 +11,11 @@ import (
 	"testing"
 	"time"
 
+	"github.com/pion/interceptor"
+	"github.com/pion/rtp"
 	"github.com/pion/transport/test"
 	"github.com/pion/webrtc/v3/pkg/media"
+	"github.com/pion/webrtc/v3/pkg/rtcerr"
 	"github.com/stretchr/testify/assert"
 )
 
@@ -118,6 +121,8 @@ func Test_RTPSender_GetParameters(t *testing.T) {
 	assert.NotEqual(t, 0, len(parameters.Codecs))
 	assert.Equal(t, 1, len(parameters.Encodings))
 	assert.Equal(t, rtpTransceiver.Sender().trackEncodings[0].ssrc, parameters.Encodings[0].SSRC)
+	assert.Equal(t, rtpTransceiver.Sender().trackEncodings[0].rtxSsrc, parameters.Encodings[0].RTX.SSRC)
+	assert.NotEqual(t, SSRC(0), parameters.Encodings[0].RTX.SSRC)
 	assert.Equal(t, "", parameters.Encodings[0].RID)
 
 	closePairNow(t, offerer, answerer)
@@ -401,3 +406,85 @@ func Test_RTPSender_Add_Encoding(t *testing.T) {
 
 	assert.NoError(t, peerConnection.Close())
 }
+
+func Test_RTPSender_SetParameters(t *testing.T) {
+	peerConnection, err := NewPeerConnection(Configuration{})
+	assert.NoError(t, err)
+
+	track, err := NewTrackLocalStaticSample(RTPCodecCapability{MimeType: MimeTypeVP8}, "video", "pion", WithRTPStreamID("q"))
+	assert.NoError(t, err)
+
+	rtpSender, err := peerConnection.AddTrack(track)
+	assert.NoError(t, err)
+
+	track1, err := NewTrackLocalStaticSample(RTPCodecCapability{MimeType: MimeTypeVP8}, "video", "pion", WithRTPStreamID("h"))
+	assert.NoError(t, err)
+	assert.NoError(t, rtpSender.AddEncoding(track1))
+
+	parameters := rtpSender.GetParameters()
+	for _, encoding := range parameters.Encodings {
+		assert.True(t, encoding.Active)
+		assert.Equal(t, uint64(0), encoding.MaxBitrate)
+		assert.Equal(t, PriorityTypeLow, encoding.Priority)
+		assert.Equal(t, 1.0, encoding.ScaleResolutionDownBy)
+	}
+
+	parameters.Encodings[1].Active = false
+	parameters.Encodings[0].MaxBitrate = 300_000
+	parameters.Encodings[0].Priority = PriorityTypeHigh
+	assert.NoError(t, rtpSender.SetParameters(parameters))
+
+	// The parameters are stale after a successful SetParameters
+	var modificationErr *rtcerr.InvalidModificationError
+	assert.True(t, errors.As(rtpSender.SetParameters(parameters), &modificationErr))
+
+	parameters = rtpSender.GetParameters()
+	assert.True(t, parameters.Encodings[0].Active)
+	assert.False(t, parameters.Encodings[1].Active)
+	assert.Equal(t, uint64(300_000), parameters.Encodings[0].MaxBitrate)
+	assert.Equal(t, PriorityTypeHigh, parameters.Encodings[0].Priority)
+	assert.True(t, rtpSender.trackEncodings[1].paused.get())
+	assert.Equal(t, 300_000, rtpSender.trackEncodings[0].targetBitrate.get())
+
+	// Encodings can't be removed
+	invalidParameters := parameters
+	invalidParameters.Encodings = parameters.Encodings[:1]
+	assert.True(t, errors.As(rtpSender.SetParameters(invalidParameters), &modificationErr))
+
+	var rangeErr *rtcerr.RangeError
+	parameters.Encodings[0].ScaleResolutionDownBy = 0.5
+	assert.True(t, errors.As(rtpSender.SetParameters(parameters), &rangeErr))
+
+	// Paused layers are announced in the simulcast attribute
+	offer, err := peerConnection.CreateOffer(nil)
+	assert.NoError(t, err)
+	assert.Contains(t, offer.SDP, "a=simulcast:send q;~h")
+
+	assert.NoError(t, rtpSender.Stop())
+
+	var stateErr *rtcerr.InvalidStateError
+	assert.True(t, errors.As(rtpSender.SetParameters(rtpSender.GetParameters()), &stateErr))
+
+	assert.NoError(t, peerConnection.Close())
+}
+
+func Test_RTPSender_PausedSequenceNumbers(t *testing.T) {
+	var sequenceNumbers []uint16
+	writeStream := &interceptorToTrackLocalWriter{paused: &atomicBool{}, sequencer: newRTPSequencer()}
+	writeStream.interceptor.Store(interceptor.RTPWriterFunc(func(header *rtp.Header, payload []byte, _ interceptor.Attributes) (int, error) {
+		sequenceNumbers = append(sequenceNumbers, header.SequenceNumber)
+		return len(payload), nil
+	}))
+
+	// The packets written while the encoding is paused are left out without a gap
+	for i := uint16(0); i < 30; i++ {
+		writeStream.paused.set(i >= 10 && i < 20)
+		_, err := writeStream.WriteRTP(&rtp.Header{SequenceNumber: 65530 + i}, []byte{0x00})
+		assert.NoError(t, err)
+	}
+
+	assert.Len(t, sequenceNumbers, 20)
+	for i := 1; i < len(sequenceNumbers); i++ {
+		assert.Equal(t, sequenceNumbers[i-1]+1, sequenceNumbers[i])
+	}
+}
@@ -4,4 +4,8 @@ package webrtc
 type RTPSendParameters struct {
 	RTPParameters
 	Encodings []RTPEncodingParameters
+
+	// TransactionID identifies the parameters returned by GetParameters, SetParameters
+	// rejects parameters that are not the latest ones
+	TransactionID string
 }
@@ -0,0 +1,192 @@
+//go:build !js
+// +build !js
+
+package webrtc
+
+import (
+	"sync"
+
+	"github.com/pion/interceptor"
+	"github.com/pion/randutil"
+	"github.com/pion/rtcp"
+	"github.com/pion/rtp"
+)
+
+// rtxResponderBufferSize is the amount of packets kept per stream to answer NACKs. Must be a power of two.
+const rtxResponderBufferSize = 1024
+
+// rtxSSRCAttribute and rtxPayloadTypeAttribute are set by RTPSender on the StreamInfo
+// of an encoding that has a negotiated RTX (RFC 4588) repair flow.
+type (
+	rtxSSRCAttribute        struct{}
+	rtxPayloadTypeAttribute struct{}
+)
+
+// rtxRetransmissionAttribute is set on the packets the rtxResponderInterceptor resends, so they
+// are not counted as the packets of the media by the statsInterceptor
+type rtxRetransmissionAttribute struct{}
+
+// rtxResponderInterceptorFactory creates rtxResponderInterceptors
+type rtxResponderInterceptorFactory struct{}
+
+// NewInterceptor constructs a new rtxResponderInterceptor
+func (r *rtxResponderInterceptorFactory) NewInterceptor(_ string) (interceptor.Interceptor, error) {
+	return &rtxResponderInterceptor{
+		streams: map[uint32]*rtxResponderStream{},
+	}, nil
+}
+
+// rtxResponderInterceptor answers NACKs for local streams. If the stream has a RTX
+// repair flow the lost packets are sent RTX encapsulated, otherwise they are sent
+// again on the original SSRC.
+type rtxResponderInterceptor struct {
+	interceptor.NoOp
+
+	streamsMu sync.Mutex
+	streams   map[uint32]*rtxResponderStream
+}
+
+type rtxResponderStream struct {
+	mu      sync.Mutex
+	packets [rtxResponderBufferSize][]byte
+
+	rtxSSRC           uint32
+	rtxPayloadType    uint8
+	rtxSequenceNumber uint16
+
+	rtpWriter interceptor.RTPWriter
+}
+
+// BindRTCPReader lets the interceptor look for NACKs in the incoming RTCP
+func (r *rtxResponderInterceptor) BindRTCPReader(reader interceptor.RTCPReader) interceptor.RTCPReader {
+	return interceptor.RTCPReaderFunc(func(b []byte, a interceptor.Attributes) (int, interceptor.Attributes, error) {
+		i, attr, err := reader.Read(b, a)
+		if err != nil {
+			return 0, nil, err
+		}
+
+		pkts, err := rtcp.Unmarshal(b[:i])
+		if err != nil {
+			return 0, nil, err
+		}
+
+		// Compound packets are read once per stream they reference, only answer for the stream it was read from
+		streamSSRC, filtered := attr[rtcpStreamSSRCAttribute{}].(SSRC)
+		for _, pkt := range pkts {
+			nack, ok := pkt.(*rtcp.TransportLayerNack)
+			if !ok || (filtered && SSRC(nack.MediaSSRC) != streamSSRC) {
+				continue
+			}
+
+			r.resendPackets(nack)
+		}
+
+		return i, attr, nil
+	})
+}
+
+// BindLocalStream keeps a copy of the sent packets of streams that negotiated NACK
+func (r *rtxResponderInterceptor) BindLocalStream(info *interceptor.StreamInfo, writer interceptor.RTPWriter) interceptor.RTPWriter {
+	if !streamSupportsNack(info) {
+		return writer
+	}
+
+	// RFC 3550 Section 5.1, the sequence numbers of the RTX repair flow start at a random value
+	stream := &rtxResponderStream{
+		rtpWriter:         writer,
+		rtxSequenceNumber: uint16(randutil.NewMathRandomGenerator().Uint32()),
+	}
+	if rtxSSRC, ok := info.Attributes[rtxSSRCAttribute{}].(SSRC); ok {
+		if rtxPayloadType, ok := info.Attributes[rtxPayloadTypeAttribute{}].(PayloadType); ok {
+			stream.rtxSSRC = uint32(rtxSSRC)
+			stream.rtxPayloadType = uint8(rtxPayloadType)
+		}
+	}
+
+	r.streamsMu.Lock()
+	r.streams[info.SSRC] = stream
+	r.streamsMu.Unlock()
+
+	return interceptor.RTPWriterFunc(func(header *rtp.Header, payload []byte, attributes interceptor.Attributes) (int, error) {
+		// Only the packets of the media are kept, not the ones of other SSRCs written on the stream
+		if header.SSRC == info.SSRC {
+			stream.add(header, payload)
+		}
+		return writer.Write(header, payload, attributes)
+	})
+}
+
+// UnbindLocalStream drops the packets kept for a local stream
+func (r *rtxResponderInterceptor) UnbindLocalStream(info *interceptor.StreamInfo) {
+	r.streamsMu.Lock()
+	defer r.streamsMu.Unlock()
+	delete(r.streams, info.SSRC)
+}
+
+func (r *rtxResponderInterceptor) resendPackets(nack *rtcp.TransportLayerNack) {
+	r.streamsMu.Lock()
+	stream, ok := r.streams[nack.MediaSSRC]
+	r.streamsMu.Unlock()
+	if !ok {
+		return
+	}
+
+	for _, pair := range nack.Nacks {
+		for _, seq := range pair.PacketList() {
+			stream.resend(seq)
+		}
+	}
+}
+
+func (s *rtxResponderStream) add(header *rtp.Header, payload []byte) {
+	// Keep a marshaled copy, the caller is free to reuse header and payload. The payload
+	// doesn't contain the padding of the packet, the copy has none
+	pkt := &rtp.Packet{Header: *header, Payload: payload}
+	pkt.Padding = false
+	raw, err := pkt.Marshal()
+	if err != nil {
+		return
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.packets[header.SequenceNumber%rtxResponderBufferSize] = raw
+}
+
+func (s *rtxResponderStream) resend(seq uint16) {
+	s.mu.Lock()
+	pkt := &rtp.Packet{}
+	if raw := s.packets[seq%rtxResponderBufferSize]; raw == nil || pkt.Unmarshal(raw) != nil || pkt.SequenceNumber != seq {
+		s.mu.Unlock()
+		return
+	}
+
+	header := pkt.Header
+	header.Padding = false
+	payload := pkt.Payload
+	if s.rtxSSRC != 0 {
+		// RFC 4588 Section 4: the RTX payload starts with the original sequence number
+		payload = make([]byte, 2+len(pkt.Payload))
+		payload[0] = byte(seq >> 8)
+		payload[1] = byte(seq)
+		copy(payload[2:], pkt.Payload)
+
+		header.SSRC = s.rtxSSRC
+		header.PayloadType = s.rtxPayloadType
+		header.SequenceNumber = s.rtxSequenceNumber
+		s.rtxSequenceNumber++
+	}
+	s.mu.Unlock()
+
+	_, _ = s.rtpWriter.Write(&header, payload, interceptor.Attributes{rtxRetransmissionAttribute{}: true})
+}
+
+func streamSupportsNack(info *interceptor.StreamInfo) bool {
+	for _, fb := range info.RTCPFeedback {
+		if fb.Type == "nack" && fb.Parameter == "" {
+			return true
+		}
+	}
+
+	return false
+}
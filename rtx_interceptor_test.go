@@ -0,0 +1,115 @@
+//go:build !js
+// +build !js
+
+package webrtc
+
+import (
+	"testing"
+
+	"github.com/pion/interceptor"
+	"github.com/pion/rtcp"
+	"github.com/pion/rtp"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRTXResponderInterceptor(t *testing.T) {
+	nackFeedback := []interceptor.RTCPFeedback{{Type: "nack"}}
+
+	for _, test := range []struct {
+		name       string
+		attributes interceptor.Attributes
+		check      func(t *testing.T, header *rtp.Header, payload []byte, rtxSequenceNumber uint16)
+	}{
+		{
+			name:       "NoRTX",
+			attributes: interceptor.Attributes{},
+			check: func(t *testing.T, header *rtp.Header, payload []byte, _ uint16) {
+				assert.Equal(t, uint32(5000), header.SSRC)
+				assert.Equal(t, uint8(96), header.PayloadType)
+				assert.Equal(t, uint16(11), header.SequenceNumber)
+				assert.Equal(t, []byte{0x0B}, payload)
+			},
+		},
+		{
+			name: "RTX",
+			attributes: interceptor.Attributes{
+				rtxSSRCAttribute{}:        SSRC(6000),
+				rtxPayloadTypeAttribute{}: PayloadType(97),
+			},
+			check: func(t *testing.T, header *rtp.Header, payload []byte, rtxSequenceNumber uint16) {
+				assert.Equal(t, uint32(6000), header.SSRC)
+				assert.Equal(t, uint8(97), header.PayloadType)
+				assert.Equal(t, rtxSequenceNumber, header.SequenceNumber)
+				assert.Equal(t, []byte{0x00, 0x0B, 0x0B}, payload)
+			},
+		},
+	} {
+		test := test
+		t.Run(test.name, func(t *testing.T) {
+			i, err := (&rtxResponderInterceptorFactory{}).NewInterceptor("")
+			assert.NoError(t, err)
+
+			type writtenPacket struct {
+				header  rtp.Header
+				payload []byte
+			}
+			var written []writtenPacket
+			info := &interceptor.StreamInfo{SSRC: 5000, PayloadType: 96, RTCPFeedback: nackFeedback, Attributes: test.attributes}
+			writer := i.BindLocalStream(info, interceptor.RTPWriterFunc(func(header *rtp.Header, payload []byte, attributes interceptor.Attributes) (int, error) {
+				// Resent packets are marked as retransmissions
+				_, retransmitted := attributes[rtxRetransmissionAttribute{}]
+				assert.Equal(t, len(written) == 3, retransmitted)
+				written = append(written, writtenPacket{*header, append([]byte{}, payload...)})
+				return len(payload), nil
+			}))
+
+			// The payload passed to the writer doesn't contain the padding of a packet
+			for seq := uint16(10); seq < 13; seq++ {
+				_, err = writer.Write(&rtp.Header{Version: 2, Padding: seq == 11, SSRC: 5000, PayloadType: 96, SequenceNumber: seq}, []byte{byte(seq)}, nil)
+				assert.NoError(t, err)
+			}
+			rtxSequenceNumber := i.(*rtxResponderInterceptor).streams[5000].rtxSequenceNumber
+
+			// 11 has been sent, 20 is unknown. The NACK for 6000 is not ours to answer
+			raw, err := rtcp.Marshal([]rtcp.Packet{
+				&rtcp.TransportLayerNack{MediaSSRC: 5000, Nacks: []rtcp.NackPair{{PacketID: 11}, {PacketID: 20}}},
+				&rtcp.TransportLayerNack{MediaSSRC: 6000, Nacks: []rtcp.NackPair{{PacketID: 11}}},
+			})
+			assert.NoError(t, err)
+
+			reader := i.BindRTCPReader(interceptor.RTCPReaderFunc(func(b []byte, a interceptor.Attributes) (int, interceptor.Attributes, error) {
+				return copy(b, raw), withRTCPStreamSSRC(a, 5000), nil
+			}))
+			_, _, err = reader.Read(make([]byte, receiveMTU), nil)
+			assert.NoError(t, err)
+
+			if assert.Len(t, written, 4) {
+				assert.False(t, written[3].header.Padding)
+				test.check(t, &written[3].header, written[3].payload, rtxSequenceNumber)
+			}
+
+			i.UnbindLocalStream(info)
+			_, _, err = reader.Read(make([]byte, receiveMTU), nil)
+			assert.NoError(t, err)
+			assert.Len(t, written, 4)
+		})
+	}
+}
+
+func TestRTXResponderInterceptor_NoNack(t *testing.T) {
+	i, err := (&rtxResponderInterceptorFactory{}).NewInterceptor("")
+	assert.NoError(t, err)
+
+	called := false
+	writer := interceptor.RTPWriterFunc(func(header *rtp.Header, payload []byte, _ interceptor.Attributes) (int, error) {
+		called = true
+		return len(payload), nil
+	})
+
+	// Streams without NACK feedback are not buffered
+	bound := i.BindLocalStream(&interceptor.StreamInfo{SSRC: 5000, Attributes: interceptor.Attributes{}}, writer)
+	_, err = bound.Write(&rtp.Header{SSRC: 5000}, []byte{0x01}, nil)
+	assert.NoError(t, err)
+	assert.True(t, called)
+	assert.Empty(t, i.(*rtxResponderInterceptor).streams)
+}
@@ -5,6 +5,7 @@ package webrtc
 
 import (
 	"errors"
+	"fmt"
 	"io"
 	"math"
 	"sync"
@@ -53,6 +54,10 @@ type SCTPTransport struct {
 	dataChannelsRequested uint32
 	dataChannelsAccepted  uint32
 
+	statsID string
+
+	scheduler *dataChannelScheduler
+
 	api *API
 	log logging.LeveledLogger
 }
@@ -64,10 +69,12 @@ func (api *API) NewSCTPTransport(dtls *DTLSTransport) *SCTPTransport {
 	res := &SCTPTransport{
 		dtlsTransport: dtls,
 		state:         SCTPTransportStateConnecting,
+		statsID:       fmt.Sprintf("SCTPTransport-%d", time.Now().UnixNano()),
 		api:           api,
 		log:           api.settingEngine.LoggerFactory.NewLogger("ortc"),
 	}
 
+	res.scheduler = newDataChannelScheduler(res.bufferedAmount)
 	res.updateMessageSize()
 	res.updateMaxChannels()
 
@@ -82,6 +89,17 @@ func (r *SCTPTransport) Transport() *DTLSTransport {
 	return r.dtlsTransport
 }
 
+// setDTLSTransport changes the DTLSTransport before the SCTPTransport is started,
+// it is the one of the data media section if it isn't bundled
+func (r *SCTPTransport) setDTLSTransport(dtlsTransport *DTLSTransport) {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+
+	if !r.isStarted {
+		r.dtlsTransport = dtlsTransport
+	}
+}
+
 // GetCapabilities returns the SCTPCapabilities of the SCTPTransport.
 func (r *SCTPTransport) GetCapabilities() SCTPCapabilities {
 	return SCTPCapabilities{
@@ -141,6 +159,8 @@ func (r *SCTPTransport) Start(remoteCaps SCTPCapabilities) error {
 
 // Stop stops the SCTPTransport
 func (r *SCTPTransport) Stop() error {
+	r.scheduler.close()
+
 	r.lock.Lock()
 	defer r.lock.Unlock()
 	if r.sctpAssociation == nil {
@@ -224,6 +244,7 @@ ACCEPT:
 			Ordered:           ordered,
 			MaxPacketLifeTime: maxPacketLifeTime,
 			MaxRetransmits:    maxRetransmits,
+			Priority:          newPriorityTypeFromDataChannel(dc.Config.Priority),
 		}, r.api.settingEngine.LoggerFactory.NewLogger("ortc"))
 		if err != nil {
 			r.log.Errorf("Failed to accept data channel: %v", err)
@@ -231,6 +252,10 @@ ACCEPT:
 			return
 		}
 
+		rtcDC.mu.Lock()
+		rtcDC.sctpTransport = r
+		rtcDC.mu.Unlock()
+
 		<-r.onDataChannel(rtcDC)
 		rtcDC.handleOpen(dc, true, dc.Config.Negotiated)
 
@@ -359,21 +384,62 @@ func (r *SCTPTransport) State() SCTPTransportState {
 func (r *SCTPTransport) collectStats(collector *statsReportCollector) {
 	collector.Collecting()
 
-	stats := TransportStats{
+	transportStats := TransportStats{
 		Timestamp: statsTimestampFrom(time.Now()),
 		Type:      StatsTypeTransport,
 		ID:        "sctpTransport",
 	}
 
-	association := r.association()
+	collector.Collecting()
+
+	r.lock.RLock()
+	stats := SCTPTransportStats{
+		Timestamp:      transportStats.Timestamp,
+		Type:           StatsTypeSCTPTransport,
+		ID:             r.statsID,
+		TransportID:    r.dtlsTransport.iceTransportStatsID(),
+		State:          r.state,
+		MaxMessageSize: uint32(r.maxMessageSize),
+		MaxChannels:    sctpMaxChannels,
+	}
+	if r.maxChannels != nil {
+		stats.MaxChannels = *r.maxChannels
+	}
+	association := r.sctpAssociation
+	r.lock.RUnlock()
+
+	stats.UNACKData = r.bufferedAmount()
+
 	if association != nil {
-		stats.BytesSent = association.BytesSent()
-		stats.BytesReceived = association.BytesReceived()
+		transportStats.BytesSent = association.BytesSent()
+		transportStats.BytesReceived = association.BytesReceived()
+		stats.BytesSent = transportStats.BytesSent
+		stats.BytesReceived = transportStats.BytesReceived
+
+		stats.SmoothedRoundTripTime = association.SRTT() / 1000 // milliseconds to seconds
+		stats.CongestionWindow = association.CWND()
+		stats.ReceiverWindow = association.RWND()
+		stats.MTU = association.MTU()
 	}
 
+	collector.Collect(transportStats.ID, transportStats)
 	collector.Collect(stats.ID, stats)
 }
 
+// bufferedAmount returns the amount of data the DataChannels have handed to the association
+// that is not acknowledged yet. The messages queued by the scheduler are not included
+func (r *SCTPTransport) bufferedAmount() uint64 {
+	r.lock.RLock()
+	dataChannels := append([]*DataChannel{}, r.dataChannels...)
+	r.lock.RUnlock()
+
+	var bufferedAmount uint64
+	for _, d := range dataChannels {
+		bufferedAmount += d.sctpBufferedAmount()
+	}
+	return bufferedAmount
+}
+
 func (r *SCTPTransport) generateAndSetDataChannelID(dtlsRole DTLSRole, idOut **uint16) error {
 	var id uint16
 	if dtlsRole != DTLSRoleClient {
@@ -26,6 +26,7 @@ type trackDetails struct {
 	id         string
 	ssrcs      []SSRC
 	repairSsrc *SSRC
+	fecSsrc    *SSRC
 	rids       []string
 }
 
@@ -77,6 +78,7 @@ func trackDetailsFromSDP(log logging.LeveledLogger, s *sdp.SessionDescription) (
 	for _, media := range s.MediaDescriptions {
 		tracksInMediaSection := []trackDetails{}
 		rtxRepairFlows := map[uint64]uint64{}
+		fecRepairFlows := map[uint64]uint64{}
 
 		// Plan B can have multiple tracks in a signle media section
 		streamID := ""
@@ -122,6 +124,23 @@ func trackDetailsFromSDP(log logging.LeveledLogger, s *sdp.SessionDescription) (
 						rtxRepairFlows[rtxRepairFlow] = baseSsrc
 						tracksInMediaSection = filterTrackWithSSRC(tracksInMediaSection, SSRC(rtxRepairFlow)) // Remove if rtx was added as track before
 					}
+				} else if split[0] == sdpSemanticTokenFECFramework {
+					// Lines like `a=ssrc-group:FEC-FR 2231627014 3194584352` declare that the second SSRC
+					// carries the FlexFEC protection (RFC 5956) of the first, it isn't a track either
+					if len(split) == 3 {
+						baseSsrc, err := strconv.ParseUint(split[1], 10, 32)
+						if err != nil {
+							log.Warnf("Failed to parse SSRC: %v", err)
+							continue
+						}
+						fecRepairFlow, err := strconv.ParseUint(split[2], 10, 32)
+						if err != nil {
+							log.Warnf("Failed to parse SSRC: %v", err)
+							continue
+						}
+						fecRepairFlows[fecRepairFlow] = baseSsrc
+						tracksInMediaSection = filterTrackWithSSRC(tracksInMediaSection, SSRC(fecRepairFlow))
+					}
 				}
 
 			// Handle `a=msid:<stream_id> <track_label>` for Unified plan. The first value is the same as MediaStream.id
@@ -145,6 +164,9 @@ func trackDetailsFromSDP(log logging.LeveledLogger, s *sdp.SessionDescription) (
 				if _, ok := rtxRepairFlows[ssrc]; ok {
 					continue // This ssrc is a RTX repair flow, ignore
 				}
+				if _, ok := fecRepairFlows[ssrc]; ok {
+					continue // This ssrc is a FlexFEC repair flow, ignore
+				}
 
 				if len(split) == 3 && strings.HasPrefix(split[1], "msid:") {
 					streamID = split[1][len("msid:"):]
@@ -174,6 +196,12 @@ func trackDetailsFromSDP(log logging.LeveledLogger, s *sdp.SessionDescription) (
 						trackDetails.repairSsrc = &repairSsrc
 					}
 				}
+				for r, baseSsrc := range fecRepairFlows {
+					if baseSsrc == ssrc {
+						fecSsrc := SSRC(r)
+						trackDetails.fecSsrc = &fecSsrc
+					}
+				}
 
 				if isNewTrack {
 					tracksInMediaSection = append(tracksInMediaSection, *trackDetails)
@@ -220,6 +248,10 @@ func trackDetailsToRTPReceiveParameters(t *trackDetails) RTPReceiveParameters {
 		if t.repairSsrc != nil {
 			encodings[i].RTX.SSRC = *t.repairSsrc
 		}
+
+		if t.fecSsrc != nil {
+			encodings[i].FEC.SSRC = *t.fecSsrc
+		}
 	}
 
 	return RTPReceiveParameters{Encodings: encodings}
@@ -310,7 +342,7 @@ func addDataMediaSection(d *sdp.SessionDescription, shouldAddCandidates bool, dt
 	return nil
 }
 
-func populateLocalCandidates(sessionDescription *SessionDescription, i *ICEGatherer, iceGatheringState ICEGatheringState) *SessionDescription {
+func populateLocalCandidates(sessionDescription *SessionDescription, i *ICEGatherer, mediaSectionTransports map[string]*mediaSectionTransport, iceGatheringState ICEGatheringState) *SessionDescription {
 	if sessionDescription == nil || i == nil {
 		return sessionDescription
 	}
@@ -328,6 +360,23 @@ func populateLocalCandidates(sessionDescription *SessionDescription, i *ICEGathe
 		}
 	}
 
+	// Media sections that have their own transport carry its candidates
+	for _, m := range parsed.MediaDescriptions {
+		transport, ok := mediaSectionTransports[getMidValue(m)]
+		if !ok {
+			continue
+		}
+
+		mediaCandidates, err := transport.iceGatherer.GetLocalCandidates()
+		if err != nil {
+			return sessionDescription
+		}
+
+		if err = addCandidatesToMediaDescriptions(mediaCandidates, m, iceGatheringState); err != nil {
+			return sessionDescription
+		}
+	}
+
 	sdp, err := parsed.Marshal()
 	if err != nil {
 		return sessionDescription
@@ -358,7 +407,19 @@ func addSenderSDP(
 
 		sendParameters := sender.GetParameters()
 		for _, encoding := range sendParameters.Encodings {
+			if encoding.RTX.SSRC != 0 {
+				media = media.WithValueAttribute("ssrc-group", fmt.Sprintf("FID %d %d", encoding.SSRC, encoding.RTX.SSRC))
+			}
+			if encoding.FEC.SSRC != 0 {
+				media = media.WithValueAttribute("ssrc-group", fmt.Sprintf("%s %d %d", sdpSemanticTokenFECFramework, encoding.SSRC, encoding.FEC.SSRC))
+			}
 			media = media.WithMediaSource(uint32(encoding.SSRC), track.StreamID() /* cname */, track.StreamID() /* streamLabel */, track.ID())
+			if encoding.RTX.SSRC != 0 {
+				media = media.WithMediaSource(uint32(encoding.RTX.SSRC), track.StreamID() /* cname */, track.StreamID() /* streamLabel */, track.ID())
+			}
+			if encoding.FEC.SSRC != 0 {
+				media = media.WithMediaSource(uint32(encoding.FEC.SSRC), track.StreamID() /* cname */, track.StreamID() /* streamLabel */, track.ID())
+			}
 			if !isPlanB {
 				media = media.WithPropertyAttribute("msid:" + track.StreamID() + " " + track.ID())
 			}
@@ -369,7 +430,12 @@ func addSenderSDP(
 
 			for _, encoding := range sendParameters.Encodings {
 				media.WithValueAttribute(sdpAttributeRid, encoding.RID+" send")
-				sendRids = append(sendRids, encoding.RID)
+				if encoding.Active {
+					sendRids = append(sendRids, encoding.RID)
+				} else {
+					// RFC 8853 Section 5.1: paused streams are prefixed with ~
+					sendRids = append(sendRids, "~"+encoding.RID)
+				}
 			}
 			// Simulcast
 			media.WithValueAttribute("simulcast", "send "+strings.Join(sendRids, ";"))
@@ -498,6 +564,16 @@ type mediaSection struct {
 	transceivers []*RTPTransceiver
 	data         bool
 	ridMap       map[string]string
+
+	// Set if the media section has its own transport instead of sharing the one of the
+	// first media section, see BundlePolicyMaxCompat
+	iceParams  *ICEParameters
+	candidates []ICECandidate
+
+	// unbundled media sections are left out of the BUNDLE group, bundleOnly ones
+	// can only be used if the remote accepts BUNDLE (JSEP Section 5.2.1)
+	unbundled  bool
+	bundleOnly bool
 }
 
 // populateSDP serializes a PeerConnections state into an SDP
@@ -516,6 +592,7 @@ func populateSDP(d *sdp.SessionDescription, isPlanB bool, dtlsFingerprints []DTL
 		bundleCount++
 	}
 
+	allUnbundled := len(mediaSections) != 0
 	for i, m := range mediaSections {
 		if m.data && len(m.transceivers) != 0 {
 			return nil, errSDPMediaSectionMediaDataChanInvalid
@@ -525,20 +602,35 @@ func populateSDP(d *sdp.SessionDescription, isPlanB bool, dtlsFingerprints []DTL
 
 		shouldAddID := true
 		shouldAddCandidates := i == 0
+		mediaICEParams, mediaCandidates := iceParams, candidates
+		if m.iceParams != nil {
+			shouldAddCandidates = true
+			mediaICEParams, mediaCandidates = *m.iceParams, m.candidates
+		}
+
 		if m.data {
-			if err = addDataMediaSection(d, shouldAddCandidates, mediaDtlsFingerprints, m.id, iceParams, candidates, connectionRole, iceGatheringState); err != nil {
+			if err = addDataMediaSection(d, shouldAddCandidates, mediaDtlsFingerprints, m.id, mediaICEParams, mediaCandidates, connectionRole, iceGatheringState); err != nil {
 				return nil, err
 			}
 		} else {
-			shouldAddID, err = addTransceiverSDP(d, isPlanB, shouldAddCandidates, mediaDtlsFingerprints, mediaEngine, m.id, iceParams, candidates, connectionRole, iceGatheringState, m)
+			shouldAddID, err = addTransceiverSDP(d, isPlanB, shouldAddCandidates, mediaDtlsFingerprints, mediaEngine, m.id, mediaICEParams, mediaCandidates, connectionRole, iceGatheringState, m)
 			if err != nil {
 				return nil, err
 			}
 		}
 
-		if shouldAddID {
-			appendBundle(m.id)
+		allUnbundled = allUnbundled && m.unbundled
+		if !shouldAddID || m.unbundled {
+			continue
 		}
+
+		if m.bundleOnly {
+			// RFC 8829 Section 5.2.1, the port of a bundle-only media section is 0
+			media := d.MediaDescriptions[len(d.MediaDescriptions)-1]
+			media.MediaName.Port = sdp.RangedPort{Value: 0}
+			media.WithPropertyAttribute(sdpAttributeBundleOnly)
+		}
+		appendBundle(m.id)
 	}
 
 	if !mediaDescriptionFingerprint {
@@ -556,6 +648,11 @@ func populateSDP(d *sdp.SessionDescription, isPlanB bool, dtlsFingerprints []DTL
 		d = d.WithPropertyAttribute(sdp.AttrKeyExtMapAllowMixed)
 	}
 
+	// A description without BUNDLE group is sent to remotes that didn't accept BUNDLE
+	if allUnbundled {
+		return d, nil
+	}
+
 	return d.WithValueAttribute(sdp.AttrKeyGroup, bundleValue), nil
 }
 
@@ -613,34 +710,48 @@ func getPeerDirection(media *sdp.MediaDescription) RTPTransceiverDirection {
 	return RTPTransceiverDirection(Unknown)
 }
 
-func extractFingerprint(desc *sdp.SessionDescription) (string, string, error) {
-	fingerprints := []string{}
-
-	if fingerprint, haveFingerprint := desc.Attribute("fingerprint"); haveFingerprint {
-		fingerprints = append(fingerprints, fingerprint)
+// extractFingerprints returns the distinct fingerprints of a description, from the session
+// and media level. The remote certificate has to match one of them (RFC 8122 Section 5).
+func extractFingerprints(desc *sdp.SessionDescription) ([]DTLSFingerprint, error) {
+	values := []string{}
+	for _, a := range desc.Attributes {
+		if a.Key == "fingerprint" {
+			values = append(values, a.Value)
+		}
 	}
-
 	for _, m := range desc.MediaDescriptions {
-		if fingerprint, haveFingerprint := m.Attribute("fingerprint"); haveFingerprint {
-			fingerprints = append(fingerprints, fingerprint)
+		for _, a := range m.Attributes {
+			if a.Key == "fingerprint" {
+				values = append(values, a.Value)
+			}
 		}
 	}
 
-	if len(fingerprints) < 1 {
-		return "", "", ErrSessionDescriptionNoFingerprint
+	if len(values) < 1 {
+		return nil, ErrSessionDescriptionNoFingerprint
 	}
 
-	for _, m := range fingerprints {
-		if m != fingerprints[0] {
-			return "", "", ErrSessionDescriptionConflictingFingerprints
+	fingerprints := []DTLSFingerprint{}
+	for _, value := range values {
+		parts := strings.Split(value, " ")
+		if len(parts) != 2 {
+			return nil, ErrSessionDescriptionInvalidFingerprint
 		}
-	}
 
-	parts := strings.Split(fingerprints[0], " ")
-	if len(parts) != 2 {
-		return "", "", ErrSessionDescriptionInvalidFingerprint
+		fingerprint := DTLSFingerprint{Algorithm: parts[0], Value: parts[1]}
+		duplicate := false
+		for _, f := range fingerprints {
+			if strings.EqualFold(f.Algorithm, fingerprint.Algorithm) && strings.EqualFold(f.Value, fingerprint.Value) {
+				duplicate = true
+				break
+			}
+		}
+		if !duplicate {
+			fingerprints = append(fingerprints, fingerprint)
+		}
 	}
-	return parts[1], parts[0], nil
+
+	return fingerprints, nil
 }
 
 func extractICEDetails(desc *sdp.SessionDescription, log logging.LeveledLogger) (string, string, []ICECandidate, error) { // nolint:gocognit
@@ -705,6 +816,60 @@ func extractICEDetails(desc *sdp.SessionDescription, log logging.LeveledLogger)
 	return remoteUfrags[0], remotePwds[0], candidates, nil
 }
 
+// descriptionIsBundled returns true if the description has a BUNDLE group, RFC 8843
+func descriptionIsBundled(desc *sdp.SessionDescription) bool {
+	for _, a := range desc.Attributes {
+		if a.Key != sdp.AttrKeyGroup {
+			continue
+		}
+
+		if fields := strings.Fields(a.Value); len(fields) > 1 && fields[0] == "BUNDLE" {
+			return true
+		}
+	}
+	return false
+}
+
+// bundleTransportMedia returns the media section whose transport a BUNDLE group uses, the
+// first one of the group (RFC 8843 Section 7.3.1). It is nil if the description isn't bundled
+// or if all its media sections have the same ICE credentials already, as a max-compat offer
+// that offers a transport for each media section has not.
+func bundleTransportMedia(desc *sdp.SessionDescription) *sdp.MediaDescription {
+	ufrags := map[string]bool{}
+	for _, m := range desc.MediaDescriptions {
+		if ufrag, haveUfrag := m.Attribute("ice-ufrag"); haveUfrag {
+			ufrags[ufrag] = true
+		}
+	}
+	if len(ufrags) < 2 {
+		return nil
+	}
+
+	for _, a := range desc.Attributes {
+		if a.Key != sdp.AttrKeyGroup {
+			continue
+		}
+
+		if fields := strings.Fields(a.Value); len(fields) > 1 && fields[0] == "BUNDLE" {
+			for _, m := range desc.MediaDescriptions {
+				if getMidValue(m) == fields[1] {
+					return m
+				}
+			}
+		}
+	}
+	return nil
+}
+
+// descriptionForMedia returns a description that only has the given media section, so
+// the ICE and DTLS parameters of a media section that isn't bundled can be extracted
+func descriptionForMedia(desc *sdp.SessionDescription, media *sdp.MediaDescription) *sdp.SessionDescription {
+	return &sdp.SessionDescription{
+		Attributes:        desc.Attributes,
+		MediaDescriptions: []*sdp.MediaDescription{media},
+	}
+}
+
 func haveApplicationMediaSection(desc *sdp.SessionDescription) bool {
 	for _, m := range desc.MediaDescriptions {
 		if m.MediaName.Media == mediaSectionApplication {
@@ -7,6 +7,7 @@ import (
 	"crypto/ecdsa"
 	"crypto/elliptic"
 	"crypto/rand"
+	"fmt"
 	"strings"
 	"testing"
 
@@ -14,16 +15,15 @@ import (
 	"github.com/stretchr/testify/assert"
 )
 
-func TestExtractFingerprint(t *testing.T) {
+func TestExtractFingerprints(t *testing.T) {
 	t.Run("Good Session Fingerprint", func(t *testing.T) {
 		s := &sdp.SessionDescription{
 			Attributes: []sdp.Attribute{{Key: "fingerprint", Value: "foo bar"}},
 		}
 
-		fingerprint, hash, err := extractFingerprint(s)
+		fingerprints, err := extractFingerprints(s)
 		assert.NoError(t, err)
-		assert.Equal(t, fingerprint, "bar")
-		assert.Equal(t, hash, "foo")
+		assert.Equal(t, []DTLSFingerprint{{Algorithm: "foo", Value: "bar"}}, fingerprints)
 	})
 
 	t.Run("Good Media Fingerprint", func(t *testing.T) {
@@ -33,16 +33,15 @@ func TestExtractFingerprint(t *testing.T) {
 			},
 		}
 
-		fingerprint, hash, err := extractFingerprint(s)
+		fingerprints, err := extractFingerprints(s)
 		assert.NoError(t, err)
-		assert.Equal(t, fingerprint, "bar")
-		assert.Equal(t, hash, "foo")
+		assert.Equal(t, []DTLSFingerprint{{Algorithm: "foo", Value: "bar"}}, fingerprints)
 	})
 
 	t.Run("No Fingerprint", func(t *testing.T) {
 		s := &sdp.SessionDescription{}
 
-		_, _, err := extractFingerprint(s)
+		_, err := extractFingerprints(s)
 		assert.Equal(t, ErrSessionDescriptionNoFingerprint, err)
 	})
 
@@ -51,20 +50,25 @@ func TestExtractFingerprint(t *testing.T) {
 			Attributes: []sdp.Attribute{{Key: "fingerprint", Value: "foo"}},
 		}
 
-		_, _, err := extractFingerprint(s)
+		_, err := extractFingerprints(s)
 		assert.Equal(t, ErrSessionDescriptionInvalidFingerprint, err)
 	})
 
-	t.Run("Conflicting Fingerprint", func(t *testing.T) {
+	t.Run("Multiple Fingerprints", func(t *testing.T) {
 		s := &sdp.SessionDescription{
-			Attributes: []sdp.Attribute{{Key: "fingerprint", Value: "foo"}},
+			Attributes: []sdp.Attribute{{Key: "fingerprint", Value: "foo bar"}, {Key: "fingerprint", Value: "foo baz"}},
 			MediaDescriptions: []*sdp.MediaDescription{
-				{Attributes: []sdp.Attribute{{Key: "fingerprint", Value: "foo blah"}}},
+				{Attributes: []sdp.Attribute{{Key: "fingerprint", Value: "foo bar"}, {Key: "fingerprint", Value: "blah bar"}}},
 			},
 		}
 
-		_, _, err := extractFingerprint(s)
-		assert.Equal(t, ErrSessionDescriptionConflictingFingerprints, err)
+		fingerprints, err := extractFingerprints(s)
+		assert.NoError(t, err)
+		assert.Equal(t, []DTLSFingerprint{
+			{Algorithm: "foo", Value: "bar"},
+			{Algorithm: "foo", Value: "baz"},
+			{Algorithm: "blah", Value: "bar"},
+		}, fingerprints)
 	})
 }
 
@@ -449,6 +453,40 @@ func TestPopulateSDP(t *testing.T) {
 		}
 		assert.Equal(t, true, foundVP8, "vp8 should be present in sdp")
 	})
+	t.Run("rtx", func(t *testing.T) {
+		se := SettingEngine{}
+
+		me := &MediaEngine{}
+		assert.NoError(t, me.RegisterDefaultCodecs())
+		api := NewAPI(WithMediaEngine(me))
+
+		track, err := NewTrackLocalStaticSample(RTPCodecCapability{MimeType: MimeTypeVP8}, "video", "pion")
+		assert.NoError(t, err)
+
+		sender, err := api.NewRTPSender(track, &DTLSTransport{})
+		assert.NoError(t, err)
+
+		tr := &RTPTransceiver{kind: RTPCodecTypeVideo, api: api, codecs: me.videoCodecs}
+		tr.setDirection(RTPTransceiverDirectionSendonly)
+		tr.setSender(sender)
+		mediaSections := []mediaSection{{id: "video", transceivers: []*RTPTransceiver{tr}}}
+
+		offerSdp, err := populateSDP(&sdp.SessionDescription{}, false, []DTLSFingerprint{}, se.sdpMediaLevelFingerprints, se.candidates.ICELite, true, me, connectionRoleFromDtlsRole(defaultDtlsRoleOffer), []ICECandidate{}, ICEParameters{}, mediaSections, ICEGatheringStateComplete)
+		assert.NoError(t, err)
+
+		// The repair flow is grouped with the media flow, and announced
+		ssrc, rtxSsrc := sender.trackEncodings[0].ssrc, sender.trackEncodings[0].rtxSsrc
+		ssrcGroup, _ := offerSdp.MediaDescriptions[0].Attribute("ssrc-group")
+		assert.Equal(t, fmt.Sprintf("FID %d %d", ssrc, rtxSsrc), ssrcGroup)
+
+		var foundRtxSsrc bool
+		for _, a := range offerSdp.MediaDescriptions[0].Attributes {
+			if a.Key == "ssrc" && strings.HasPrefix(a.Value, fmt.Sprintf("%d ", rtxSsrc)) {
+				foundRtxSsrc = true
+			}
+		}
+		assert.True(t, foundRtxSsrc, "RTX SSRC should be present")
+	})
 	t.Run("ice-lite", func(t *testing.T) {
 		se := SettingEngine{}
 		se.SetLite(true)
@@ -82,11 +82,23 @@ func getMdNames(sdp *sdp.SessionDescription) []string {
 }
 
 func extractSsrcList(md *sdp.MediaDescription) []string {
+	// The RTX repair flows of the FID groups aren't counted
+	repairSsrcs := map[string]struct{}{}
+	for _, attr := range md.Attributes {
+		if attr.Key == sdp.AttrKeySSRCGroup {
+			if fields := strings.Fields(attr.Value); len(fields) == 3 && fields[0] == "FID" {
+				repairSsrcs[fields[2]] = struct{}{}
+			}
+		}
+	}
+
 	ssrcMap := map[string]struct{}{}
 	for _, attr := range md.Attributes {
 		if attr.Key == sdp.AttrKeySSRC {
 			ssrc := strings.Fields(attr.Value)[0]
-			ssrcMap[ssrc] = struct{}{}
+			if _, isRepair := repairSsrcs[ssrc]; !isRepair {
+				ssrcMap[ssrc] = struct{}{}
+			}
 		}
 	}
 	ssrcList := make([]string, 0, len(ssrcMap))
@@ -297,20 +309,6 @@ func TestSDPSemantics_UnifiedPlanWithFallback(t *testing.T) {
 
 	assert.ObjectsAreEqual(getMdNames(answer.parsed), []string{"video", "audio", "data"})
 
-	extractSsrcList := func(md *sdp.MediaDescription) []string {
-		ssrcMap := map[string]struct{}{}
-		for _, attr := range md.Attributes {
-			if attr.Key == sdp.AttrKeySSRC {
-				ssrc := strings.Fields(attr.Value)[0]
-				ssrcMap[ssrc] = struct{}{}
-			}
-		}
-		ssrcList := make([]string, 0, len(ssrcMap))
-		for ssrc := range ssrcMap {
-			ssrcList = append(ssrcList, ssrc)
-		}
-		return ssrcList
-	}
 	// Verify that each section has 2 SSRCs (one for each sender)
 	for _, section := range []string{"video", "audio"} {
 		for _, media := range answer.parsed.MediaDescriptions {
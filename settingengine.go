@@ -10,6 +10,7 @@ import (
 
 	"github.com/pion/dtls/v2"
 	"github.com/pion/ice/v2"
+	"github.com/pion/interceptor/pkg/cc"
 	"github.com/pion/logging"
 	"github.com/pion/transport/packetio"
 	"github.com/pion/transport/vnet"
@@ -60,6 +61,11 @@ type SettingEngine struct {
 	sctp struct {
 		maxReceiveBufferSize uint32
 	}
+	bandwidthEstimation struct {
+		enabled bool
+		factory cc.BandwidthEstimatorFactory
+	}
+	fecOverhead                               uint8
 	sdpMediaLevelFingerprints                 bool
 	answeringDTLSRole                         DTLSRole
 	disableCertificateFingerprintVerification bool
@@ -330,3 +336,20 @@ func (e *SettingEngine) SetDTLSRetransmissionInterval(interval time.Duration) {
 func (e *SettingEngine) SetSCTPMaxReceiveBufferSize(maxReceiveBufferSize uint32) {
 	e.sctp.maxReceiveBufferSize = maxReceiveBufferSize
 }
+
+// EnableBandwidthEstimation enables sender side congestion control. Every PeerConnection creates
+// its own BandwidthEstimator with the given factory, passing nil uses Google Congestion Control.
+// The estimates are delivered to PeerConnection.OnBandwidthEstimate and, undivided, to every
+// TrackLocal via TrackLocalContext.OnTargetBitrateChange. The remote peer must send TWCC feedback.
+func (e *SettingEngine) EnableBandwidthEstimation(factory cc.BandwidthEstimatorFactory) {
+	e.bandwidthEstimation.enabled = true
+	e.bandwidthEstimation.factory = factory
+}
+
+// SetFECOverhead enables sending Forward Error Correction for video. overhead is the amount of
+// protection packets sent, in percent of the media packets, 0 disables it. FlexFEC-03 is used if
+// it has been negotiated, otherwise ULPFEC inside of RED. Received FEC is always used to recover
+// lost packets.
+func (e *SettingEngine) SetFECOverhead(overhead uint8) {
+	e.fecOverhead = overhead
+}
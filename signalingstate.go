@@ -151,6 +151,11 @@ func checkNextSignalingState(cur, next SignalingState, op stateChangeOp, sdpType
 					return next, nil
 				}
 			}
+		} else if op == stateChangeOpSetLocal && sdpType == SDPTypeRollback {
+			// have-local-offer->SetLocal(rollback)->stable
+			if next == SignalingStateStable {
+				return next, nil
+			}
 		}
 	case SignalingStateHaveRemotePranswer:
 		if op == stateChangeOpSetRemote && sdpType == SDPTypeAnswer {
@@ -173,6 +178,11 @@ func checkNextSignalingState(cur, next SignalingState, op stateChangeOp, sdpType
 					return next, nil
 				}
 			}
+		} else if op == stateChangeOpSetRemote && sdpType == SDPTypeRollback {
+			// have-remote-offer->SetRemote(rollback)->stable
+			if next == SignalingStateStable {
+				return next, nil
+			}
 		}
 	case SignalingStateHaveLocalPranswer:
 		if op == stateChangeOpSetLocal && sdpType == SDPTypeAnswer {
@@ -126,6 +126,22 @@ func TestSignalingState_Transitions(t *testing.T) {
 			SDPTypeAnswer,
 			nil,
 		},
+		{
+			"have-local-offer->SetLocal(rollback)->stable",
+			SignalingStateHaveLocalOffer,
+			SignalingStateStable,
+			stateChangeOpSetLocal,
+			SDPTypeRollback,
+			nil,
+		},
+		{
+			"have-remote-offer->SetRemote(rollback)->stable",
+			SignalingStateHaveRemoteOffer,
+			SignalingStateStable,
+			stateChangeOpSetRemote,
+			SDPTypeRollback,
+			nil,
+		},
 		{
 			"(invalid) stable->SetRemote(pranswer)->have-remote-pranswer",
 			SignalingStateStable,
@@ -142,6 +158,14 @@ func TestSignalingState_Transitions(t *testing.T) {
 			SDPTypeRollback,
 			&rtcerr.InvalidModificationError{},
 		},
+		{
+			"(invalid) have-local-offer->SetRemote(rollback)->stable",
+			SignalingStateHaveLocalOffer,
+			SignalingStateStable,
+			stateChangeOpSetRemote,
+			SDPTypeRollback,
+			&rtcerr.InvalidModificationError{},
+		},
 	}
 
 	for i, tc := range testCases {
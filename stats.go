@@ -55,6 +55,9 @@ const (
 	// StatsTypeTransport is used by TransportStats.
 	StatsTypeTransport StatsType = "transport"
 
+	// StatsTypeSCTPTransport is used by SCTPTransportStats.
+	StatsTypeSCTPTransport StatsType = "sctp-transport"
+
 	// StatsTypeCandidatePair is used by ICECandidatePairStats.
 	StatsTypeCandidatePair StatsType = "candidate-pair"
 
@@ -376,6 +379,12 @@ type OutboundRTPStreamStats struct {
 	// to produce the CodecStats associated with this RTP stream.
 	CodecID string `json:"codecId"`
 
+	// Rid is the RTP stream ID of this stream, if it is a simulcast layer.
+	Rid string `json:"rid"`
+
+	// Active indicates if this stream is being sent, see RTPEncodingParameters.Active.
+	Active bool `json:"active"`
+
 	// FIRCount counts the total number of Full Intra Request (FIR) packets received
 	// by the sender. This metric is only valid for video and is sent by receiver.
 	FIRCount uint32 `json:"firCount"`
@@ -419,6 +428,15 @@ type OutboundRTPStreamStats struct {
 	// reasons, including full buffer or no available memory.
 	BytesDiscardedOnSend uint64 `json:"bytesDiscardedOnSend"`
 
+	// RetransmittedPacketsSent is the total number of packets of this SSRC that were
+	// retransmitted, on the SSRC itself or on its RTX repair flow. They are not counted
+	// in PacketsSent.
+	RetransmittedPacketsSent uint64 `json:"retransmittedPacketsSent"`
+
+	// RetransmittedBytesSent is the total number of payload bytes of this SSRC that were
+	// retransmitted. They are not counted in BytesSent.
+	RetransmittedBytesSent uint64 `json:"retransmittedBytesSent"`
+
 	// TrackID is the identifier of the stats object representing the current track
 	// attachment to the sender of this stream, a SenderAudioTrackAttachmentStats
 	// or SenderVideoTrackAttachmentStats.
@@ -779,6 +797,10 @@ type DataChannelStats struct {
 	// BytesReceived represents the total number of bytes received on this
 	// datachannel not including headers or padding.
 	BytesReceived uint64 `json:"bytesReceived"`
+
+	// BufferedAmount is the "bufferedAmount" value of the DataChannel object, the
+	// number of bytes that are queued or sent but not acknowledged yet.
+	BufferedAmount uint64 `json:"bufferedAmount"`
 }
 
 // MediaStreamStats contains statistics related to a specific MediaStream.
@@ -1176,6 +1198,60 @@ type TransportStats struct {
 	SRTPCipher string `json:"srtpCipher"`
 }
 
+// SCTPTransportStats contains statistics related to the SCTPTransport that
+// carries the DataChannels of the PeerConnection object.
+type SCTPTransportStats struct {
+	// Timestamp is the timestamp associated with this object.
+	Timestamp StatsTimestamp `json:"timestamp"`
+
+	// Type is the object's StatsType
+	Type StatsType `json:"type"`
+
+	// ID is a unique id that is associated with the component inspected to produce
+	// this Stats object. Two Stats objects will have the same ID if they were produced
+	// by inspecting the same underlying object.
+	ID string `json:"id"`
+
+	// TransportID is the ID of the TransportStats object for the transport
+	// the SCTP association is running over.
+	TransportID string `json:"transportId"`
+
+	// SmoothedRoundTripTime is the latest smoothed round-trip time of the SCTP
+	// association in seconds.
+	SmoothedRoundTripTime float64 `json:"smoothedRoundTripTime"`
+
+	// CongestionWindow is the latest congestion window of the SCTP association in bytes.
+	CongestionWindow uint32 `json:"congestionWindow"`
+
+	// ReceiverWindow is the latest receiver window announced by the remote peer in bytes.
+	ReceiverWindow uint32 `json:"receiverWindow"`
+
+	// MTU is the latest maximum transmission unit of the SCTP association in bytes.
+	MTU uint32 `json:"mtu"`
+
+	// UNACKData is the number of bytes of all DataChannels that are passed to the
+	// SCTP association and not acknowledged yet. Messages the SCTPTransport holds
+	// back while the association is busy are not included.
+	UNACKData uint64 `json:"unackData"`
+
+	// BytesSent represents the total number of payload bytes sent on this
+	// association not including headers or padding.
+	BytesSent uint64 `json:"bytesSent"`
+
+	// BytesReceived represents the total number of payload bytes received on this
+	// association not including headers or padding.
+	BytesReceived uint64 `json:"bytesReceived"`
+
+	// State is the "state" value of the SCTPTransport object.
+	State SCTPTransportState `json:"state"`
+
+	// MaxMessageSize is the "maxMessageSize" value of the SCTPTransport object.
+	MaxMessageSize uint32 `json:"maxMessageSize"`
+
+	// MaxChannels is the "maxChannels" value of the SCTPTransport object.
+	MaxChannels uint16 `json:"maxChannels"`
+}
+
 // StatsICECandidatePairState is the state of an ICE candidate pair used in the
 // ICECandidatePairStats object.
 type StatsICECandidatePairState string
@@ -33,6 +33,20 @@ func (r StatsReport) GetDataChannelStats(dc *DataChannel) (DataChannelStats, boo
 	return dcStats, true
 }
 
+// GetSCTPTransportStats is a helper method to return the associated stats for a given SCTPTransport
+func (r StatsReport) GetSCTPTransportStats(t *SCTPTransport) (SCTPTransportStats, bool) {
+	stats, ok := r[t.statsID]
+	if !ok {
+		return SCTPTransportStats{}, false
+	}
+
+	sctpTransportStats, ok := stats.(SCTPTransportStats)
+	if !ok {
+		return SCTPTransportStats{}, false
+	}
+	return sctpTransportStats, true
+}
+
 // GetICECandidateStats is a helper method to return the associated stats for a given ICECandidate
 func (r StatsReport) GetICECandidateStats(c *ICECandidate) (ICECandidateStats, bool) {
 	statsID := c.statsID
@@ -92,3 +106,62 @@ func (r StatsReport) GetCodecStats(c *RTPCodecParameters) (CodecStats, bool) {
 	}
 	return codecStats, true
 }
+
+// GetInboundRTPStreamStats is a helper method to return the associated stats for a given TrackRemote
+func (r StatsReport) GetInboundRTPStreamStats(t *TrackRemote) (InboundRTPStreamStats, bool) {
+	stats, ok := r[newInboundRTPStreamStatsID(t.SSRC())]
+	if !ok {
+		return InboundRTPStreamStats{}, false
+	}
+
+	inboundStats, ok := stats.(InboundRTPStreamStats)
+	if !ok {
+		return InboundRTPStreamStats{}, false
+	}
+	return inboundStats, true
+}
+
+// GetRemoteOutboundRTPStreamStats is a helper method to return the stats the remote peer
+// reported in RTCP Sender Reports for a given TrackRemote
+func (r StatsReport) GetRemoteOutboundRTPStreamStats(t *TrackRemote) (RemoteOutboundRTPStreamStats, bool) {
+	stats, ok := r[newRemoteOutboundRTPStreamStatsID(t.SSRC())]
+	if !ok {
+		return RemoteOutboundRTPStreamStats{}, false
+	}
+
+	remoteOutboundStats, ok := stats.(RemoteOutboundRTPStreamStats)
+	if !ok {
+		return RemoteOutboundRTPStreamStats{}, false
+	}
+	return remoteOutboundStats, true
+}
+
+// GetOutboundRTPStreamStats is a helper method to return the associated stats for the encoding
+// of a RTPSender with the given SSRC. The SSRC of each encoding is available via RTPSender.GetParameters
+func (r StatsReport) GetOutboundRTPStreamStats(ssrc SSRC) (OutboundRTPStreamStats, bool) {
+	stats, ok := r[newOutboundRTPStreamStatsID(ssrc)]
+	if !ok {
+		return OutboundRTPStreamStats{}, false
+	}
+
+	outboundStats, ok := stats.(OutboundRTPStreamStats)
+	if !ok {
+		return OutboundRTPStreamStats{}, false
+	}
+	return outboundStats, true
+}
+
+// GetRemoteInboundRTPStreamStats is a helper method to return the stats the remote peer
+// reported in RTCP Receiver Reports for the encoding of a RTPSender with the given SSRC
+func (r StatsReport) GetRemoteInboundRTPStreamStats(ssrc SSRC) (RemoteInboundRTPStreamStats, bool) {
+	stats, ok := r[newRemoteInboundRTPStreamStatsID(ssrc)]
+	if !ok {
+		return RemoteInboundRTPStreamStats{}, false
+	}
+
+	remoteInboundStats, ok := stats.(RemoteInboundRTPStreamStats)
+	if !ok {
+		return RemoteInboundRTPStreamStats{}, false
+	}
+	return remoteInboundStats, true
+}
@@ -11,6 +11,7 @@ import (
 	"time"
 
 	"github.com/pion/ice/v2"
+	"github.com/pion/transport/test"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 )
@@ -57,6 +58,7 @@ func TestStatsMarshal(t *testing.T) {
 		RemoteInboundRTPStreamStats{},
 		RemoteOutboundRTPStreamStats{},
 		RTPContributingSourceStats{},
+		SCTPTransportStats{},
 		SenderAudioTrackAttachmentStats{},
 		SenderAudioTrackAttachmentStats{},
 		SenderVideoTrackAttachmentStats{},
@@ -118,6 +120,13 @@ func getTransportStats(t *testing.T, report StatsReport, statsID string) Transpo
 	return transportStats
 }
 
+func getSCTPTransportStats(t *testing.T, report StatsReport, sctpTransport *SCTPTransport) SCTPTransportStats {
+	stats, ok := report.GetSCTPTransportStats(sctpTransport)
+	assert.True(t, ok)
+	assert.Equal(t, stats.Type, StatsTypeSCTPTransport)
+	return stats
+}
+
 func getCertificateStats(t *testing.T, report StatsReport, certificate *Certificate) CertificateStats {
 	certificateStats, ok := report.GetCertificateStats(certificate)
 	assert.True(t, ok)
@@ -368,6 +377,19 @@ func TestPeerConnection_GetStats(t *testing.T) {
 	assert.GreaterOrEqual(t, offerSCTPTransportStats.BytesSent, answerSCTPTransportStats.BytesReceived)
 	assert.GreaterOrEqual(t, answerSCTPTransportStats.BytesSent, offerSCTPTransportStats.BytesReceived)
 
+	answerSCTPStats := getSCTPTransportStats(t, reportPCAnswer, answerPC.SCTP())
+	offerSCTPStats := getSCTPTransportStats(t, reportPCOffer, offerPC.SCTP())
+	assert.GreaterOrEqual(t, offerSCTPStats.BytesSent, answerSCTPStats.BytesReceived)
+	assert.GreaterOrEqual(t, answerSCTPStats.BytesSent, offerSCTPStats.BytesReceived)
+	assert.Equal(t, SCTPTransportStateConnected, offerSCTPStats.State)
+	assert.Equal(t, "iceTransport", offerSCTPStats.TransportID)
+	assert.Equal(t, offerPC.SCTP().MaxChannels(), offerSCTPStats.MaxChannels)
+	assert.Zero(t, offerSCTPStats.UNACKData)
+	assert.NotZero(t, offerSCTPStats.SmoothedRoundTripTime)
+	assert.NotZero(t, offerSCTPStats.CongestionWindow)
+	assert.NotZero(t, offerSCTPStats.ReceiverWindow)
+	assert.NotZero(t, offerSCTPStats.MTU)
+
 	certificates := offerPC.configuration.Certificates
 
 	for i := range certificates {
@@ -385,3 +407,55 @@ func TestPeerConnection_GetStats_Closed(t *testing.T) {
 
 	pc.GetStats()
 }
+
+func TestPeerConnection_GetStats_RTPStreams(t *testing.T) {
+	lim := test.TimeOut(time.Second * 30)
+	defer lim.Stop()
+
+	report := test.CheckRoutines(t)
+	defer report()
+
+	pcOffer, pcAnswer, err := newPair()
+	assert.NoError(t, err)
+
+	track, err := NewTrackLocalStaticSample(RTPCodecCapability{MimeType: MimeTypeVP8}, "video", "pion")
+	assert.NoError(t, err)
+
+	sender, err := pcOffer.AddTrack(track)
+	assert.NoError(t, err)
+
+	remoteTrackChan := make(chan *TrackRemote)
+	pcAnswer.OnTrack(func(track *TrackRemote, r *RTPReceiver) {
+		for i := 0; i < 5; i++ {
+			if _, _, readErr := track.ReadRTP(); readErr != nil {
+				return
+			}
+		}
+		remoteTrackChan <- track
+	})
+
+	assert.NoError(t, signalPair(pcOffer, pcAnswer))
+
+	done := make(chan struct{})
+	go sendVideoUntilDone(done, t, []*TrackLocalStaticSample{track})
+
+	remoteTrack := <-remoteTrackChan
+	close(done)
+
+	outboundStats, ok := pcOffer.GetStats().GetOutboundRTPStreamStats(sender.GetParameters().Encodings[0].SSRC)
+	assert.True(t, ok)
+	assert.Equal(t, StatsTypeOutboundRTP, outboundStats.Type)
+	assert.Equal(t, "video", outboundStats.Kind)
+	assert.GreaterOrEqual(t, outboundStats.PacketsSent, uint32(5))
+	assert.NotZero(t, outboundStats.BytesSent)
+	assert.True(t, outboundStats.Active)
+
+	inboundStats, ok := pcAnswer.GetStats().GetInboundRTPStreamStats(remoteTrack)
+	assert.True(t, ok)
+	assert.Equal(t, StatsTypeInboundRTP, inboundStats.Type)
+	assert.Equal(t, remoteTrack.SSRC(), inboundStats.SSRC)
+	assert.GreaterOrEqual(t, inboundStats.PacketsReceived, uint32(5))
+	assert.NotZero(t, inboundStats.BytesReceived)
+
+	closePairNow(t, pcOffer, pcAnswer)
+}
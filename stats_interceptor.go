@@ -0,0 +1,379 @@
+//go:build !js
+// +build !js
+
+package webrtc
+
+import (
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/pion/interceptor"
+	"github.com/pion/rtcp"
+	"github.com/pion/rtp"
+)
+
+// ntpEpochOffset is the amount of seconds between the NTP epoch (1900) and the Unix epoch (1970)
+const ntpEpochOffset = 2208988800
+
+// rtcpStreamSSRCAttribute is set by the SRTCP read streams of RTPSender and RTPReceiver. RTCP
+// compound packets are delivered to every stream they reference, so the statsInterceptor uses
+// it to only account a packet for the stream it was read from.
+type rtcpStreamSSRCAttribute struct{}
+
+func withRTCPStreamSSRC(a interceptor.Attributes, ssrc SSRC) interceptor.Attributes {
+	if a == nil {
+		a = interceptor.Attributes{}
+	}
+	a[rtcpStreamSSRCAttribute{}] = ssrc
+	return a
+}
+
+// outboundStreamState holds the counters of a local RTP stream and what the
+// remote peer reported about it in RTCP Receiver Reports
+type outboundStreamState struct {
+	clockRate uint32
+
+	packetsSent    uint32
+	bytesSent      uint64
+	lastPacketSent time.Time
+
+	retransmittedPacketsSent uint64
+	retransmittedBytesSent   uint64
+
+	nackCount, pliCount, firCount uint32
+
+	remoteReported  bool
+	remoteTimestamp time.Time
+	packetsLost     int32
+	fractionLost    float64
+	jitter          float64
+	roundTripTime   float64
+}
+
+// inboundStreamState holds the counters of a remote RTP stream and what the
+// remote peer reported about it in RTCP Sender Reports
+type inboundStreamState struct {
+	clockRate uint32
+
+	packetsReceived    uint32
+	bytesReceived      uint64
+	lastPacketReceived time.Time
+
+	// RFC 3550 Appendix A.1 sequence tracking
+	started     bool
+	baseSeq     uint32
+	maxSeq      uint16
+	cycles      uint32
+	lastTransit int64
+	haveTransit bool
+	jitter      float64
+
+	nackCount, pliCount, firCount uint32
+
+	remoteReported    bool
+	remoteTimestamp   time.Time
+	remotePacketsSent uint32
+	remoteBytesSent   uint64
+}
+
+func (s *inboundStreamState) packetsLost() int32 {
+	if !s.started {
+		return 0
+	}
+
+	extendedMax := s.cycles + uint32(s.maxSeq)
+	expected := int64(extendedMax) - int64(s.baseSeq) + 1
+	return int32(expected - int64(s.packetsReceived))
+}
+
+func (s *inboundStreamState) update(header *rtp.Header, payloadLen int, now time.Time) {
+	s.packetsReceived++
+	s.bytesReceived += uint64(payloadLen)
+	s.lastPacketReceived = now
+
+	if !s.started {
+		s.started = true
+		s.baseSeq = uint32(header.SequenceNumber)
+		s.maxSeq = header.SequenceNumber
+	} else if delta := header.SequenceNumber - s.maxSeq; delta != 0 && delta < 1<<15 {
+		if header.SequenceNumber < s.maxSeq {
+			s.cycles += 1 << 16
+		}
+		s.maxSeq = header.SequenceNumber
+	}
+
+	if s.clockRate == 0 {
+		return
+	}
+
+	// RFC 3550 Appendix A.8, arrival expressed in RTP timestamp units
+	arrival := now.UnixNano() * int64(s.clockRate) / int64(time.Second)
+	transit := arrival - int64(header.Timestamp)
+	if s.haveTransit {
+		d := transit - s.lastTransit
+		if d < 0 {
+			d = -d
+		}
+		s.jitter += (float64(d) - s.jitter) / 16
+	}
+	s.lastTransit = transit
+	s.haveTransit = true
+}
+
+// statsInterceptor is an interceptor that is always bound first for every PeerConnection.
+// It observes every RTP and RTCP packet sent and received and produces the counters
+// exposed by the inbound-rtp, outbound-rtp, remote-inbound-rtp and remote-outbound-rtp stats.
+type statsInterceptor struct {
+	interceptor.NoOp
+
+	mu       sync.Mutex
+	outbound map[SSRC]*outboundStreamState
+	inbound  map[SSRC]*inboundStreamState
+	now      func() time.Time
+}
+
+func newStatsInterceptor() *statsInterceptor {
+	return &statsInterceptor{
+		outbound: map[SSRC]*outboundStreamState{},
+		inbound:  map[SSRC]*inboundStreamState{},
+		now:      time.Now,
+	}
+}
+
+// BindRTCPReader lets the interceptor account incoming RTCP: Receiver Reports and feedback
+// about local streams, and Sender Reports about remote streams.
+func (s *statsInterceptor) BindRTCPReader(reader interceptor.RTCPReader) interceptor.RTCPReader {
+	return interceptor.RTCPReaderFunc(func(b []byte, a interceptor.Attributes) (int, interceptor.Attributes, error) {
+		i, attr, err := reader.Read(b, a)
+		if err != nil {
+			return 0, nil, err
+		}
+
+		pkts, err := rtcp.Unmarshal(b[:i])
+		if err != nil {
+			return 0, nil, err
+		}
+
+		streamSSRC, filtered := attr[rtcpStreamSSRCAttribute{}].(SSRC)
+		s.processIncomingRTCP(pkts, streamSSRC, filtered)
+
+		return i, attr, nil
+	})
+}
+
+// BindRTCPWriter lets the interceptor account the feedback sent about remote streams.
+func (s *statsInterceptor) BindRTCPWriter(writer interceptor.RTCPWriter) interceptor.RTCPWriter {
+	return interceptor.RTCPWriterFunc(func(pkts []rtcp.Packet, attributes interceptor.Attributes) (int, error) {
+		s.processOutgoingRTCP(pkts)
+		return writer.Write(pkts, attributes)
+	})
+}
+
+// BindLocalStream lets the interceptor count the RTP packets sent on a local stream.
+func (s *statsInterceptor) BindLocalStream(info *interceptor.StreamInfo, writer interceptor.RTPWriter) interceptor.RTPWriter {
+	s.mu.Lock()
+	state := &outboundStreamState{clockRate: info.ClockRate}
+	s.outbound[SSRC(info.SSRC)] = state
+	s.mu.Unlock()
+
+	return interceptor.RTPWriterFunc(func(header *rtp.Header, payload []byte, attributes interceptor.Attributes) (int, error) {
+		n, err := writer.Write(header, payload, attributes)
+		if err != nil {
+			return n, err
+		}
+
+		s.mu.Lock()
+		if _, ok := attributes[rtxRetransmissionAttribute{}]; ok {
+			state.retransmittedPacketsSent++
+			state.retransmittedBytesSent += uint64(len(payload))
+		} else {
+			state.packetsSent++
+			state.bytesSent += uint64(len(payload))
+		}
+		state.lastPacketSent = s.now()
+		s.mu.Unlock()
+
+		return n, nil
+	})
+}
+
+// UnbindLocalStream forgets the counters of a local stream.
+func (s *statsInterceptor) UnbindLocalStream(info *interceptor.StreamInfo) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	delete(s.outbound, SSRC(info.SSRC))
+}
+
+// BindRemoteStream lets the interceptor count the RTP packets received on a remote stream.
+func (s *statsInterceptor) BindRemoteStream(info *interceptor.StreamInfo, reader interceptor.RTPReader) interceptor.RTPReader {
+	s.mu.Lock()
+	state := &inboundStreamState{clockRate: info.ClockRate}
+	s.inbound[SSRC(info.SSRC)] = state
+	s.mu.Unlock()
+
+	return interceptor.RTPReaderFunc(func(b []byte, a interceptor.Attributes) (int, interceptor.Attributes, error) {
+		i, attr, err := reader.Read(b, a)
+		if err != nil {
+			return 0, nil, err
+		}
+
+		pkt := &rtp.Packet{}
+		if err := pkt.Unmarshal(b[:i]); err != nil {
+			return 0, nil, err
+		}
+
+		s.mu.Lock()
+		state.update(&pkt.Header, len(pkt.Payload), s.now())
+		s.mu.Unlock()
+
+		return i, attr, nil
+	})
+}
+
+// UnbindRemoteStream forgets the counters of a remote stream.
+func (s *statsInterceptor) UnbindRemoteStream(info *interceptor.StreamInfo) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	delete(s.inbound, SSRC(info.SSRC))
+}
+
+func (s *statsInterceptor) processIncomingRTCP(pkts []rtcp.Packet, streamSSRC SSRC, filtered bool) { //nolint:gocognit
+	accept := func(ssrc uint32) bool {
+		return !filtered || SSRC(ssrc) == streamSSRC
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := s.now()
+	for _, pkt := range pkts {
+		switch pkt := pkt.(type) {
+		case *rtcp.SenderReport:
+			if state, ok := s.inbound[SSRC(pkt.SSRC)]; ok && accept(pkt.SSRC) {
+				state.remoteReported = true
+				state.remoteTimestamp = ntpToTime(pkt.NTPTime)
+				state.remotePacketsSent = pkt.PacketCount
+				state.remoteBytesSent = uint64(pkt.OctetCount)
+			}
+			s.processReceptionReports(pkt.Reports, accept, now)
+		case *rtcp.ReceiverReport:
+			s.processReceptionReports(pkt.Reports, accept, now)
+		case *rtcp.TransportLayerNack:
+			if state, ok := s.outbound[SSRC(pkt.MediaSSRC)]; ok && accept(pkt.MediaSSRC) {
+				state.nackCount++
+			}
+		case *rtcp.PictureLossIndication:
+			if state, ok := s.outbound[SSRC(pkt.MediaSSRC)]; ok && accept(pkt.MediaSSRC) {
+				state.pliCount++
+			}
+		case *rtcp.FullIntraRequest:
+			for _, entry := range pkt.FIR {
+				if state, ok := s.outbound[SSRC(entry.SSRC)]; ok && accept(entry.SSRC) {
+					state.firCount++
+				}
+			}
+		}
+	}
+}
+
+func (s *statsInterceptor) processReceptionReports(reports []rtcp.ReceptionReport, accept func(uint32) bool, now time.Time) {
+	for _, report := range reports {
+		state, ok := s.outbound[SSRC(report.SSRC)]
+		if !ok || !accept(report.SSRC) {
+			continue
+		}
+
+		state.remoteReported = true
+		state.remoteTimestamp = now
+		state.packetsLost = int32(report.TotalLost)
+		state.fractionLost = float64(report.FractionLost) / 256
+		if state.clockRate != 0 {
+			state.jitter = float64(report.Jitter) / float64(state.clockRate)
+		}
+
+		// RFC 3550 Section 6.4.1, all values are in units of 1/65536 seconds
+		if report.LastSenderReport != 0 {
+			if rtt := ntpMiddle32(now) - report.LastSenderReport - report.Delay; int32(rtt) >= 0 {
+				state.roundTripTime = float64(rtt) / 65536
+			}
+		}
+	}
+}
+
+func (s *statsInterceptor) processOutgoingRTCP(pkts []rtcp.Packet) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for _, pkt := range pkts {
+		switch pkt := pkt.(type) {
+		case *rtcp.TransportLayerNack:
+			if state, ok := s.inbound[SSRC(pkt.MediaSSRC)]; ok {
+				state.nackCount++
+			}
+		case *rtcp.PictureLossIndication:
+			if state, ok := s.inbound[SSRC(pkt.MediaSSRC)]; ok {
+				state.pliCount++
+			}
+		case *rtcp.FullIntraRequest:
+			for _, entry := range pkt.FIR {
+				if state, ok := s.inbound[SSRC(entry.SSRC)]; ok {
+					state.firCount++
+				}
+			}
+		}
+	}
+}
+
+// outboundStats returns a copy of the state of a local stream
+func (s *statsInterceptor) outboundStats(ssrc SSRC) (outboundStreamState, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	state, ok := s.outbound[ssrc]
+	if !ok {
+		return outboundStreamState{}, false
+	}
+	return *state, true
+}
+
+// inboundStats returns a copy of the state of a remote stream
+func (s *statsInterceptor) inboundStats(ssrc SSRC) (inboundStreamState, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	state, ok := s.inbound[ssrc]
+	if !ok {
+		return inboundStreamState{}, false
+	}
+	return *state, true
+}
+
+func ntpToTime(ntp uint64) time.Time {
+	seconds := int64(ntp>>32) - ntpEpochOffset
+	fraction := (ntp & 0xFFFFFFFF) * uint64(time.Second) >> 32
+	return time.Unix(seconds, int64(fraction))
+}
+
+// ntpMiddle32 returns the middle 32 bits of the NTP timestamp of t, as used by LSR and DLSR
+func ntpMiddle32(t time.Time) uint32 {
+	seconds := uint64(t.Unix()) + ntpEpochOffset
+	fraction := uint64(t.Nanosecond()) << 32 / uint64(time.Second)
+	return uint32((seconds&0xFFFF)<<16 | fraction>>16)
+}
+
+func newInboundRTPStreamStatsID(ssrc SSRC) string {
+	return fmt.Sprintf("InboundRTPStream-%d", ssrc)
+}
+
+func newOutboundRTPStreamStatsID(ssrc SSRC) string {
+	return fmt.Sprintf("OutboundRTPStream-%d", ssrc)
+}
+
+func newRemoteInboundRTPStreamStatsID(ssrc SSRC) string {
+	return fmt.Sprintf("RemoteInboundRTPStream-%d", ssrc)
+}
+
+func newRemoteOutboundRTPStreamStatsID(ssrc SSRC) string {
+	return fmt.Sprintf("RemoteOutboundRTPStream-%d", ssrc)
+}
@@ -0,0 +1,152 @@
+//go:build !js
+// +build !js
+
+package webrtc
+
+import (
+	"testing"
+	"time"
+
+	"github.com/pion/interceptor"
+	"github.com/pion/rtcp"
+	"github.com/pion/rtp"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestStatsInterceptor_RemoteStream(t *testing.T) {
+	s := newStatsInterceptor()
+	now := time.Unix(1000, 0)
+	s.now = func() time.Time { return now }
+
+	sequenceNumbers := []uint16{65534, 65535, 1, 2}
+	reader := s.BindRemoteStream(&interceptor.StreamInfo{SSRC: 5000, ClockRate: 90000}, interceptor.RTPReaderFunc(func(b []byte, a interceptor.Attributes) (int, interceptor.Attributes, error) {
+		pkt := &rtp.Packet{
+			Header:  rtp.Header{Version: 2, SSRC: 5000, SequenceNumber: sequenceNumbers[0]},
+			Payload: []byte{0x01, 0x02, 0x03},
+		}
+		sequenceNumbers = sequenceNumbers[1:]
+
+		raw, err := pkt.Marshal()
+		if err != nil {
+			return 0, nil, err
+		}
+		return copy(b, raw), a, nil
+	}))
+
+	buf := make([]byte, receiveMTU)
+	for i := 0; i < 4; i++ {
+		_, _, err := reader.Read(buf, nil)
+		assert.NoError(t, err)
+	}
+
+	state, ok := s.inboundStats(5000)
+	assert.True(t, ok)
+	assert.Equal(t, uint32(4), state.packetsReceived)
+	assert.Equal(t, uint64(12), state.bytesReceived)
+	assert.Equal(t, int32(1), state.packetsLost())
+	assert.Equal(t, now, state.lastPacketReceived)
+
+	s.UnbindRemoteStream(&interceptor.StreamInfo{SSRC: 5000})
+	_, ok = s.inboundStats(5000)
+	assert.False(t, ok)
+}
+
+func TestStatsInterceptor_LocalStream(t *testing.T) {
+	s := newStatsInterceptor()
+	now := time.Unix(1000, 0)
+	s.now = func() time.Time { return now }
+
+	writer := s.BindLocalStream(&interceptor.StreamInfo{SSRC: 5000, ClockRate: 90000}, interceptor.RTPWriterFunc(func(header *rtp.Header, payload []byte, attributes interceptor.Attributes) (int, error) {
+		return len(payload), nil
+	}))
+	s.BindLocalStream(&interceptor.StreamInfo{SSRC: 6000, ClockRate: 90000}, interceptor.RTPWriterFunc(func(header *rtp.Header, payload []byte, attributes interceptor.Attributes) (int, error) {
+		return len(payload), nil
+	}))
+
+	for i := 0; i < 3; i++ {
+		_, err := writer.Write(&rtp.Header{SSRC: 5000}, []byte{0x01, 0x02}, nil)
+		assert.NoError(t, err)
+	}
+
+	// Retransmissions are counted apart from the packets of the media
+	_, err := writer.Write(&rtp.Header{SSRC: 5001}, []byte{0x00, 0x00, 0x01, 0x02}, interceptor.Attributes{rtxRetransmissionAttribute{}: true})
+	assert.NoError(t, err)
+
+	pkts := []rtcp.Packet{
+		&rtcp.ReceiverReport{
+			SSRC: 1,
+			Reports: []rtcp.ReceptionReport{
+				{SSRC: 5000, FractionLost: 64, TotalLost: 2, Jitter: 9000, LastSenderReport: ntpMiddle32(now.Add(-time.Second)), Delay: 65536 / 2},
+				{SSRC: 6000, TotalLost: 7},
+			},
+		},
+		&rtcp.TransportLayerNack{MediaSSRC: 5000, Nacks: []rtcp.NackPair{{PacketID: 1}}},
+		&rtcp.PictureLossIndication{MediaSSRC: 6000},
+	}
+
+	// The compound packet is delivered to the streams of both SSRCs, it must only be accounted once
+	for _, ssrc := range []SSRC{5000, 6000} {
+		streamSSRC := ssrc
+		reader := s.BindRTCPReader(interceptor.RTCPReaderFunc(func(b []byte, a interceptor.Attributes) (int, interceptor.Attributes, error) {
+			raw, err := rtcp.Marshal(pkts)
+			if err != nil {
+				return 0, nil, err
+			}
+			return copy(b, raw), withRTCPStreamSSRC(a, streamSSRC), nil
+		}))
+
+		_, _, err := reader.Read(make([]byte, receiveMTU), nil)
+		assert.NoError(t, err)
+	}
+
+	state, ok := s.outboundStats(5000)
+	assert.True(t, ok)
+	assert.Equal(t, uint32(3), state.packetsSent)
+	assert.Equal(t, uint64(6), state.bytesSent)
+	assert.Equal(t, uint64(1), state.retransmittedPacketsSent)
+	assert.Equal(t, uint64(4), state.retransmittedBytesSent)
+	assert.Equal(t, uint32(1), state.nackCount)
+	assert.Equal(t, uint32(0), state.pliCount)
+	assert.True(t, state.remoteReported)
+	assert.Equal(t, int32(2), state.packetsLost)
+	assert.Equal(t, 0.25, state.fractionLost)
+	assert.Equal(t, 0.1, state.jitter)
+	assert.InDelta(t, 0.5, state.roundTripTime, 0.001)
+
+	state, ok = s.outboundStats(6000)
+	assert.True(t, ok)
+	assert.Equal(t, uint32(0), state.nackCount)
+	assert.Equal(t, uint32(1), state.pliCount)
+	assert.Equal(t, int32(7), state.packetsLost)
+}
+
+func TestStatsInterceptor_SenderReport(t *testing.T) {
+	s := newStatsInterceptor()
+	s.BindRemoteStream(&interceptor.StreamInfo{SSRC: 5000, ClockRate: 48000}, interceptor.RTPReaderFunc(func(b []byte, a interceptor.Attributes) (int, interceptor.Attributes, error) {
+		return 0, a, nil
+	}))
+
+	reportTime := time.Unix(1600000000, 500000000)
+	reader := s.BindRTCPReader(interceptor.RTCPReaderFunc(func(b []byte, a interceptor.Attributes) (int, interceptor.Attributes, error) {
+		raw, err := rtcp.Marshal([]rtcp.Packet{&rtcp.SenderReport{
+			SSRC:        5000,
+			NTPTime:     uint64(reportTime.Unix()+ntpEpochOffset)<<32 | 1<<31,
+			PacketCount: 10,
+			OctetCount:  1000,
+		}})
+		if err != nil {
+			return 0, nil, err
+		}
+		return copy(b, raw), a, nil
+	}))
+
+	_, _, err := reader.Read(make([]byte, receiveMTU), nil)
+	assert.NoError(t, err)
+
+	state, ok := s.inboundStats(5000)
+	assert.True(t, ok)
+	assert.True(t, state.remoteReported)
+	assert.Equal(t, uint32(10), state.remotePacketsSent)
+	assert.Equal(t, uint64(1000), state.remoteBytesSent)
+	assert.Equal(t, reportTime.UnixNano(), state.remoteTimestamp.UnixNano())
+}
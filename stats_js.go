@@ -0,0 +1,102 @@
+//go:build js && wasm
+// +build js,wasm
+
+package webrtc
+
+import (
+	"encoding/json"
+	"errors"
+	"reflect"
+	"syscall/js"
+)
+
+// valueToStatsReport converts a RTCStatsReport into a StatsReport. Every stats
+// object is converted into the struct of its StatsType, members that are
+// unknown or can't be represented by the struct are left zero. Stats objects
+// of a StatsType that has no struct are skipped.
+func valueToStatsReport(reportValue js.Value) StatsReport {
+	report := StatsReport{}
+	stringify := js.Global().Get("JSON").Get("stringify")
+
+	forEach := js.FuncOf(func(this js.Value, args []js.Value) interface{} {
+		statsValue := args[0]
+		stats := newStatsOfType(
+			StatsType(valueToStringOrZero(statsValue.Get("type"))),
+			valueToStringOrZero(statsValue.Get("kind")),
+			statsValue.Get("remoteSource").Truthy(),
+		)
+		if stats == nil {
+			return js.Undefined()
+		}
+
+		// Members of the wrong type are skipped, the others are still unmarshaled
+		err := json.Unmarshal([]byte(stringify.Invoke(statsValue).String()), stats)
+		var typeErr *json.UnmarshalTypeError
+		if err == nil || errors.As(err, &typeErr) {
+			report[valueToStringOrZero(statsValue.Get("id"))] = reflect.ValueOf(stats).Elem().Interface()
+		}
+		return js.Undefined()
+	})
+	defer forEach.Release()
+
+	reportValue.Call("forEach", forEach)
+	return report
+}
+
+// newStatsOfType returns a pointer to the struct of a StatsType, nil if there is none
+func newStatsOfType(statsType StatsType, kind string, remoteSource bool) Stats {
+	switch statsType {
+	case StatsTypeCodec:
+		return &CodecStats{}
+	case StatsTypeInboundRTP:
+		return &InboundRTPStreamStats{}
+	case StatsTypeOutboundRTP:
+		return &OutboundRTPStreamStats{}
+	case StatsTypeRemoteInboundRTP:
+		return &RemoteInboundRTPStreamStats{}
+	case StatsTypeRemoteOutboundRTP:
+		return &RemoteOutboundRTPStreamStats{}
+	case StatsTypeCSRC:
+		return &RTPContributingSourceStats{}
+	case StatsTypePeerConnection:
+		return &PeerConnectionStats{}
+	case StatsTypeDataChannel:
+		return &DataChannelStats{}
+	case StatsTypeStream:
+		return &MediaStreamStats{}
+	case StatsTypeTrack:
+		// Browsers report the attachments of both local and remote tracks as "track"
+		switch {
+		case remoteSource && kind == RTPCodecTypeAudio.String():
+			return &AudioReceiverStats{}
+		case remoteSource:
+			return &VideoReceiverStats{}
+		case kind == RTPCodecTypeAudio.String():
+			return &SenderAudioTrackAttachmentStats{}
+		default:
+			return &SenderVideoTrackAttachmentStats{}
+		}
+	case StatsTypeSender:
+		if kind == RTPCodecTypeAudio.String() {
+			return &AudioSenderStats{}
+		}
+		return &VideoSenderStats{}
+	case StatsTypeReceiver:
+		if kind == RTPCodecTypeAudio.String() {
+			return &AudioReceiverStats{}
+		}
+		return &VideoReceiverStats{}
+	case StatsTypeTransport:
+		return &TransportStats{}
+	case StatsTypeSCTPTransport:
+		return &SCTPTransportStats{}
+	case StatsTypeCandidatePair:
+		return &ICECandidatePairStats{}
+	case StatsTypeLocalCandidate, StatsTypeRemoteCandidate:
+		return &ICECandidateStats{}
+	case StatsTypeCertificate:
+		return &CertificateStats{}
+	}
+
+	return nil
+}
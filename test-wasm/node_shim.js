@@ -4,7 +4,12 @@
 const wrtc = require('wrtc')
 
 global.window = {
-  RTCPeerConnection: wrtc.RTCPeerConnection
+  RTCPeerConnection: wrtc.RTCPeerConnection,
+  MediaStream: wrtc.MediaStream
 }
 
 global.RTCPeerConnection = wrtc.RTCPeerConnection
+global.MediaStream = wrtc.MediaStream
+
+// Used by the tests to create MediaStreamTracks without getUserMedia
+global.nonstandard = wrtc.nonstandard
@@ -1,6 +1,8 @@
 package webrtc
 
 import (
+	"sync"
+
 	"github.com/pion/interceptor"
 	"github.com/pion/rtp"
 )
@@ -22,6 +24,62 @@ type TrackLocalContext struct {
 	ssrc            SSRC
 	writeStream     TrackLocalWriter
 	rtcpInterceptor interceptor.RTCPReader
+	targetBitrate   *targetBitrate
+}
+
+// targetBitrate holds the latest bitrate the bandwidth estimator of a
+// PeerConnection recommends for a TrackLocal, capped by the MaxBitrate of the encoding
+type targetBitrate struct {
+	mu              sync.RWMutex
+	estimate        int
+	maxBitrate      int
+	onChangeHandler func(int)
+}
+
+func (t *targetBitrate) get() int {
+	t.mu.RLock()
+	defer t.mu.RUnlock()
+	return t.value()
+}
+
+func (t *targetBitrate) value() int {
+	if t.maxBitrate != 0 && (t.estimate == 0 || t.maxBitrate < t.estimate) {
+		return t.maxBitrate
+	}
+	return t.estimate
+}
+
+func (t *targetBitrate) getMaxBitrate() int {
+	t.mu.RLock()
+	defer t.mu.RUnlock()
+	return t.maxBitrate
+}
+
+func (t *targetBitrate) set(estimate int) {
+	t.update(func() { t.estimate = estimate })
+}
+
+func (t *targetBitrate) setMaxBitrate(maxBitrate int) {
+	t.update(func() { t.maxBitrate = maxBitrate })
+}
+
+func (t *targetBitrate) update(f func()) {
+	t.mu.Lock()
+	before := t.value()
+	f()
+	after := t.value()
+	handler := t.onChangeHandler
+	t.mu.Unlock()
+
+	if before != after && handler != nil {
+		handler(after)
+	}
+}
+
+func (t *targetBitrate) onChange(f func(int)) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.onChangeHandler = f
 }
 
 // CodecParameters returns the negotiated RTPCodecParameters. These are the codecs supported by both
@@ -58,6 +116,26 @@ func (t *TrackLocalContext) RTCPReader() interceptor.RTCPReader {
 	return t.rtcpInterceptor
 }
 
+// TargetBitrate returns the bitrate in bits per second the bandwidth estimator of the
+// PeerConnection recommends, capped by the MaxBitrate of the encoding. The estimate is the
+// total of the PeerConnection, it isn't divided between its tracks and encodings, so the
+// TrackLocal must share it with the other ones it sends. It returns 0 if neither is
+// available, see SettingEngine.EnableBandwidthEstimation and RTPSender.SetParameters
+func (t *TrackLocalContext) TargetBitrate() int {
+	if t.targetBitrate == nil {
+		return 0
+	}
+	return t.targetBitrate.get()
+}
+
+// OnTargetBitrateChange sets an event handler which is called when the bandwidth estimator
+// of the PeerConnection changes the bitrate it recommends, see TargetBitrate
+func (t *TrackLocalContext) OnTargetBitrateChange(f func(bitrate int)) {
+	if t.targetBitrate != nil {
+		t.targetBitrate.onChange(f)
+	}
+}
+
 // TrackLocal is an interface that controls how the user can send media
 // The user can provide their own TrackLocal implementations, or use
 // the implementations in pkg/media
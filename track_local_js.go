@@ -0,0 +1,71 @@
+//go:build js && wasm
+// +build js,wasm
+
+package webrtc
+
+import "syscall/js"
+
+// TrackLocalMediaStreamTrack is a TrackLocal backed by a MediaStreamTrack of the
+// browser, e.g. one returned by getUserMedia. The media is captured, encoded and
+// sent by the browser.
+type TrackLocalMediaStreamTrack struct {
+	// Pointer to the underlying JavaScript MediaStreamTrack object.
+	underlying js.Value
+
+	streamID string
+}
+
+// NewTrackLocalMediaStreamTrack returns a TrackLocal that sends the media of track.
+// Tracks of a PeerConnection with the same streamID are sent in the same MediaStream.
+func NewTrackLocalMediaStreamTrack(track js.Value, streamID string) *TrackLocalMediaStreamTrack {
+	return &TrackLocalMediaStreamTrack{
+		underlying: track,
+		streamID:   streamID,
+	}
+}
+
+// Bind is never called by the js PeerConnection, the browser sends the media of the track
+func (s *TrackLocalMediaStreamTrack) Bind(TrackLocalContext) (RTPCodecParameters, error) {
+	return RTPCodecParameters{}, nil
+}
+
+// Unbind is never called by the js PeerConnection, the browser sends the media of the track
+func (s *TrackLocalMediaStreamTrack) Unbind(TrackLocalContext) error {
+	return nil
+}
+
+// ID is the unique identifier for this Track. This is the id of the MediaStreamTrack
+func (s *TrackLocalMediaStreamTrack) ID() string {
+	return s.underlying.Get("id").String()
+}
+
+// RID is the RTP Stream ID for this track. Simulcast is not supported, it is always empty
+func (s *TrackLocalMediaStreamTrack) RID() string { return "" }
+
+// StreamID is the group this track belongs too. This must be unique
+func (s *TrackLocalMediaStreamTrack) StreamID() string { return s.streamID }
+
+// Kind controls if this TrackLocal is audio or video
+func (s *TrackLocalMediaStreamTrack) Kind() RTPCodecType {
+	return NewRTPCodecType(s.underlying.Get("kind").String())
+}
+
+// JSValue returns the underlying MediaStreamTrack
+func (s *TrackLocalMediaStreamTrack) JSValue() js.Value {
+	return s.underlying
+}
+
+// trackLocalToValue returns the MediaStreamTrack of a TrackLocal, null if track is nil.
+// Only a TrackLocal that is backed by a MediaStreamTrack can be sent by the browser
+func trackLocalToValue(track TrackLocal) (js.Value, error) {
+	if track == nil {
+		return js.Null(), nil
+	}
+
+	jsTrack, ok := track.(interface{ JSValue() js.Value })
+	if !ok {
+		return js.Undefined(), errTrackLocalNotMediaStreamTrack
+	}
+
+	return jsTrack.JSValue(), nil
+}
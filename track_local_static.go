@@ -8,6 +8,7 @@ import (
 	"sync"
 
 	"github.com/pion/rtp"
+	"github.com/pion/webrtc/v3/internal/fec"
 	"github.com/pion/webrtc/v3/internal/util"
 	"github.com/pion/webrtc/v3/pkg/media"
 )
@@ -20,6 +21,16 @@ type trackBinding struct {
 	ssrc        SSRC
 	payloadType PayloadType
 	writeStream TrackLocalWriter
+
+	// Set if the packets are sent inside of RED together with redundant encodings
+	redPayloadType PayloadType
+}
+
+// redundantEncoding is a previously sent packet that is repeated in the next RED payloads
+type redundantEncoding struct {
+	sequenceNumber uint16
+	timestamp      uint32
+	payload        []byte
 }
 
 // TrackLocalStaticRTP  is a TrackLocal that has a pre-set codec and accepts RTP Packets.
@@ -29,6 +40,11 @@ type TrackLocalStaticRTP struct {
 	bindings          []trackBinding
 	codec             RTPCodecCapability
 	id, rid, streamID string
+
+	// How many of the previous packets are sent again with each Opus packet
+	redundancy int
+	redMu      sync.Mutex
+	redHistory []redundantEncoding
 }
 
 // NewTrackLocalStaticRTP returns a TrackLocalStaticRTP.
@@ -54,6 +70,15 @@ func WithRTPStreamID(rid string) func(*TrackLocalStaticRTP) {
 	}
 }
 
+// WithOpusRED sets how many of the previous Opus packets are sent again with each packet
+// as RED (RFC 2198) redundancy. Redundancy is only sent to PeerConnections that negotiated
+// audio/red, the others receive plain Opus.
+func WithOpusRED(distance int) func(*TrackLocalStaticRTP) {
+	return func(t *TrackLocalStaticRTP) {
+		t.redundancy = distance
+	}
+}
+
 // Bind is called by the PeerConnection after negotiation is complete
 // This asserts that the code requested is supported by the remote peer.
 // If so it setups all the state (SSRC and PayloadType) to have a call
@@ -63,12 +88,19 @@ func (s *TrackLocalStaticRTP) Bind(t TrackLocalContext) (RTPCodecParameters, err
 
 	parameters := RTPCodecParameters{RTPCodecCapability: s.codec}
 	if codec, matchType := codecParametersFuzzySearch(parameters, t.CodecParameters()); matchType != codecMatchNone {
-		s.bindings = append(s.bindings, trackBinding{
+		binding := trackBinding{
 			ssrc:        t.SSRC(),
 			payloadType: codec.PayloadType,
 			writeStream: t.WriteStream(),
 			id:          t.ID(),
-		})
+		}
+		if s.redundancy > 0 && strings.EqualFold(codec.MimeType, MimeTypeOpus) {
+			if red := findAudioREDCodec(codec.PayloadType, t.CodecParameters()); red != nil {
+				binding.redPayloadType = red.PayloadType
+			}
+		}
+
+		s.bindings = append(s.bindings, binding)
 		return codec, nil
 	}
 
@@ -159,10 +191,34 @@ func (s *TrackLocalStaticRTP) writeRTP(p *rtp.Packet) error {
 
 	writeErrs := []error{}
 
+	var redundant []redundantEncoding
+	if s.redundancy > 0 {
+		redundant = s.pushRedundancy(p)
+	}
+
 	for _, b := range s.bindings {
 		p.Header.SSRC = uint32(b.ssrc)
 		p.Header.PayloadType = uint8(b.payloadType)
-		if _, err := b.writeStream.WriteRTP(&p.Header, p.Payload); err != nil {
+
+		payload := p.Payload
+		if b.redPayloadType != 0 {
+			blocks := make([]fec.REDBlock, 0, len(redundant)+1)
+			for _, r := range redundant {
+				blocks = append(blocks, fec.REDBlock{PayloadType: uint8(b.payloadType), TimestampOffset: uint16(p.Timestamp - r.timestamp), Data: r.payload})
+			}
+			blocks = append(blocks, fec.REDBlock{PayloadType: uint8(b.payloadType), Data: p.Payload})
+
+			red, err := fec.MarshalRED(blocks)
+			if err != nil {
+				writeErrs = append(writeErrs, err)
+				continue
+			}
+
+			p.Header.PayloadType = uint8(b.redPayloadType)
+			payload = red
+		}
+
+		if _, err := b.writeStream.WriteRTP(&p.Header, payload); err != nil {
 			writeErrs = append(writeErrs, err)
 		}
 	}
@@ -170,6 +226,41 @@ func (s *TrackLocalStaticRTP) writeRTP(p *rtp.Packet) error {
 	return util.FlattenErrs(writeErrs)
 }
 
+// pushRedundancy returns the previous packets that can be sent as redundancy with p, oldest
+// first, and keeps p for the next ones. The receiver numbers redundant encodings by their
+// distance to the primary, so only packets that directly precede p are returned.
+func (s *TrackLocalStaticRTP) pushRedundancy(p *rtp.Packet) []redundantEncoding {
+	s.redMu.Lock()
+	defer s.redMu.Unlock()
+
+	if n := len(s.redHistory); n != 0 && s.redHistory[n-1].sequenceNumber+1 != p.SequenceNumber {
+		s.redHistory = nil
+	}
+
+	first := len(s.redHistory)
+	for first > 0 {
+		r := s.redHistory[first-1]
+		if len(r.payload) > fec.REDMaxBlockLength || p.Timestamp-r.timestamp > fec.REDMaxTimestampOffset {
+			break
+		}
+		first--
+	}
+	redundant := s.redHistory[first:]
+
+	history := append([]redundantEncoding{}, redundant...)
+	history = append(history, redundantEncoding{
+		sequenceNumber: p.SequenceNumber,
+		timestamp:      p.Timestamp,
+		payload:        append([]byte{}, p.Payload...),
+	})
+	if len(history) > s.redundancy {
+		history = history[len(history)-s.redundancy:]
+	}
+	s.redHistory = history
+
+	return redundant
+}
+
 // Write writes a RTP Packet as a buffer to the TrackLocalStaticRTP
 // If one PeerConnection fails the packets will still be sent to
 // all PeerConnections. The error message will contain the ID of the failed
@@ -4,6 +4,7 @@
 package webrtc
 
 import (
+	"strings"
 	"sync"
 	"time"
 
@@ -28,6 +29,10 @@ type TrackRemote struct {
 	receiver         *RTPReceiver
 	peeked           []byte
 	peekedAttributes interceptor.Attributes
+
+	onDTMFHandler func(string)
+	lastDTMF      *dtmfEvent
+	lastDTMFTime  uint32
 }
 
 func newTrackRemote(kind RTPCodecType, ssrc SSRC, rid string, receiver *RTPReceiver) *TrackRemote {
@@ -98,7 +103,8 @@ func (t *TrackRemote) Codec() RTPCodecParameters {
 	return t.codec
 }
 
-// Read reads data from the track.
+// Read reads data from the track. The telephone-events of DTMF tones are not returned,
+// they are passed to the OnDTMF handler.
 func (t *TrackRemote) Read(b []byte) (n int, attributes interceptor.Attributes, err error) {
 	t.mu.RLock()
 	r := t.receiver
@@ -117,17 +123,25 @@ func (t *TrackRemote) Read(b []byte) (n int, attributes interceptor.Attributes,
 		// released the lock.  Deal with it.
 		if data != nil {
 			n = copy(b, data)
-			err = t.checkAndUpdateTrack(b)
-			return
+			if !t.handleTelephoneEvent(b[:n]) {
+				err = t.checkAndUpdateTrack(b[:n])
+				return
+			}
 		}
 	}
 
-	n, attributes, err = r.readRTP(b, t)
-	if err != nil {
-		return
+	// telephone-events are passed to OnDTMF instead
+	for {
+		n, attributes, err = r.readRTP(b, t)
+		if err != nil {
+			return
+		}
+		if !t.handleTelephoneEvent(b[:n]) {
+			break
+		}
 	}
 
-	err = t.checkAndUpdateTrack(b)
+	err = t.checkAndUpdateTrack(b[:n])
 	return
 }
 
@@ -139,14 +153,14 @@ func (t *TrackRemote) checkAndUpdateTrack(b []byte) error {
 	}
 
 	if payloadType := PayloadType(b[1] & rtpPayloadTypeBitmask); payloadType != t.PayloadType() {
-		t.mu.Lock()
-		defer t.mu.Unlock()
-
 		params, err := t.receiver.api.mediaEngine.getRTPParametersByPayloadType(payloadType)
 		if err != nil {
 			return err
 		}
 
+		t.mu.Lock()
+		defer t.mu.Unlock()
+
 		t.kind = t.receiver.kind
 		t.payloadType = payloadType
 		t.codec = params.Codecs[0]
@@ -156,6 +170,60 @@ func (t *TrackRemote) checkAndUpdateTrack(b []byte) error {
 	return nil
 }
 
+// handleTelephoneEvent passes b to handleDTMF if it is a telephone-event, they share the
+// SSRC of the audio but don't change the codec of the track
+func (t *TrackRemote) handleTelephoneEvent(b []byte) bool {
+	if len(b) < 2 {
+		return false
+	}
+
+	payloadType := PayloadType(b[1] & rtpPayloadTypeBitmask)
+	if payloadType == t.PayloadType() {
+		return false
+	}
+
+	params, err := t.receiver.api.mediaEngine.getRTPParametersByPayloadType(payloadType)
+	if err != nil || !strings.EqualFold(params.Codecs[0].MimeType, MimeTypeTelephoneEvent) {
+		return false
+	}
+
+	t.handleDTMF(b)
+	return true
+}
+
+// OnDTMF sets an event handler which is called when a DTMF tone is received as
+// a RFC 4733 telephone-event. The handler is called from Read, once per tone.
+func (t *TrackRemote) OnDTMF(f func(tone string)) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.onDTMFHandler = f
+}
+
+func (t *TrackRemote) handleDTMF(b []byte) {
+	packet := &rtp.Packet{}
+	event := &dtmfEvent{}
+	if err := packet.Unmarshal(b); err != nil || event.unmarshal(packet.Payload) != nil {
+		return
+	}
+
+	t.mu.Lock()
+	last, lastTime := t.lastDTMF, t.lastDTMFTime
+	t.lastDTMF, t.lastDTMFTime = event, packet.Timestamp
+	handler := t.onDTMFHandler
+	t.mu.Unlock()
+
+	// Retransmissions of an event share its timestamp, and long events are continued
+	// in segments that start where the previous one ended (RFC 4733 Section 2.5.1.3)
+	if last != nil && last.event == event.event &&
+		(lastTime == packet.Timestamp || lastTime+uint32(last.duration) == packet.Timestamp) {
+		return
+	}
+
+	if tone := event.tone(); tone != "" && handler != nil {
+		handler(tone)
+	}
+}
+
 // ReadRTP is a convenience method that wraps Read and unmarshals for you.
 func (t *TrackRemote) ReadRTP() (*rtp.Packet, interceptor.Attributes, error) {
 	b := make([]byte, t.receiver.api.settingEngine.getReceiveMTU())
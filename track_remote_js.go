@@ -0,0 +1,52 @@
+//go:build js && wasm
+// +build js,wasm
+
+package webrtc
+
+import "syscall/js"
+
+// TrackRemote represents a single inbound source of media
+type TrackRemote struct {
+	// Pointer to the underlying JavaScript MediaStreamTrack object.
+	underlying js.Value
+
+	streamID string
+	receiver *RTPReceiver
+}
+
+// ID is the unique identifier for this Track. This should be unique for the
+// stream, but doesn't have to globally unique. A common example would be 'audio' or 'video'
+// and StreamID would be 'desktop' or 'webcam'
+func (t *TrackRemote) ID() string {
+	return t.underlying.Get("id").String()
+}
+
+// StreamID is the group this track belongs too. This must be unique
+func (t *TrackRemote) StreamID() string {
+	return t.streamID
+}
+
+// Kind gets the Kind of the track
+func (t *TrackRemote) Kind() RTPCodecType {
+	return NewRTPCodecType(t.underlying.Get("kind").String())
+}
+
+// Enabled returns if the media of the track is played
+func (t *TrackRemote) Enabled() bool {
+	return t.underlying.Get("enabled").Bool()
+}
+
+// SetEnabled controls if the media of the track is played
+func (t *TrackRemote) SetEnabled(enabled bool) {
+	t.underlying.Set("enabled", enabled)
+}
+
+// Receiver returns the RTPReceiver the track is received with, nil if unknown
+func (t *TrackRemote) Receiver() *RTPReceiver {
+	return t.receiver
+}
+
+// JSValue returns the underlying MediaStreamTrack
+func (t *TrackRemote) JSValue() js.Value {
+	return t.underlying
+}